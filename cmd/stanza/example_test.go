@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"regexp"
 	"runtime"
 	"strings"
 	"sync"
@@ -31,6 +32,14 @@ func (b *muxWriter) String() string {
 	return b.buffer.String()
 }
 
+// observedTimestampPattern matches the observed timestamps of serialized entries,
+// which are set to the time that the example runs
+var observedTimestampPattern = regexp.MustCompile(`,"observed_timestamp":"[^"]*"`)
+
+func withoutObservedTimestamps(output string) string {
+	return observedTimestampPattern.ReplaceAllString(output, "")
+}
+
 func TestTomcatExample(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("Skipping on windows because of service failures")
@@ -59,13 +68,13 @@ func TestTomcatExample(t *testing.T) {
 	}()
 	defer func() { <-done }()
 
-	expected := `{"timestamp":"2019-03-13T10:43:00-04:00","severity":60,"labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"-","http_method":"GET","http_status":"404","remote_host":"10.66.2.46","remote_user":"-","url_path":"/"}}
-{"timestamp":"2019-03-13T10:43:01-04:00","severity":60,"labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"-","http_method":"GET","http_status":"404","remote_host":"10.66.2.46","remote_user":"-","url_path":"/favicon.ico"}}
-{"timestamp":"2019-03-13T10:43:08-04:00","severity":30,"labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"-","http_method":"GET","http_status":"302","remote_host":"10.66.2.46","remote_user":"-","url_path":"/manager"}}
-{"timestamp":"2019-03-13T10:43:08-04:00","severity":60,"labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"3420","http_method":"GET","http_status":"403","remote_host":"10.66.2.46","remote_user":"-","url_path":"/manager/"}}
-{"timestamp":"2019-03-13T11:00:26-04:00","severity":60,"labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"2473","http_method":"GET","http_status":"401","remote_host":"10.66.2.46","remote_user":"-","url_path":"/manager/html"}}
-{"timestamp":"2019-03-13T11:00:53-04:00","severity":20,"labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"11936","http_method":"GET","http_status":"200","remote_host":"10.66.2.46","remote_user":"tomcat","url_path":"/manager/html"}}
-{"timestamp":"2019-03-13T11:00:53-04:00","severity":20,"labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"19698","http_method":"GET","http_status":"200","remote_host":"10.66.2.46","remote_user":"-","url_path":"/manager/images/asf-logo.svg"}}
+	expected := `{"timestamp":"2019-03-13T10:43:00-04:00","severity":60,"severity_text":"404","labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"-","http_method":"GET","http_status":"404","remote_host":"10.66.2.46","remote_user":"-","url_path":"/"}}
+{"timestamp":"2019-03-13T10:43:01-04:00","severity":60,"severity_text":"404","labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"-","http_method":"GET","http_status":"404","remote_host":"10.66.2.46","remote_user":"-","url_path":"/favicon.ico"}}
+{"timestamp":"2019-03-13T10:43:08-04:00","severity":30,"severity_text":"302","labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"-","http_method":"GET","http_status":"302","remote_host":"10.66.2.46","remote_user":"-","url_path":"/manager"}}
+{"timestamp":"2019-03-13T10:43:08-04:00","severity":60,"severity_text":"403","labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"3420","http_method":"GET","http_status":"403","remote_host":"10.66.2.46","remote_user":"-","url_path":"/manager/"}}
+{"timestamp":"2019-03-13T11:00:26-04:00","severity":60,"severity_text":"401","labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"2473","http_method":"GET","http_status":"401","remote_host":"10.66.2.46","remote_user":"-","url_path":"/manager/html"}}
+{"timestamp":"2019-03-13T11:00:53-04:00","severity":20,"severity_text":"200","labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"11936","http_method":"GET","http_status":"200","remote_host":"10.66.2.46","remote_user":"tomcat","url_path":"/manager/html"}}
+{"timestamp":"2019-03-13T11:00:53-04:00","severity":20,"severity_text":"200","labels":{"file_name":"access.log","log_type":"tomcat"},"record":{"bytes_sent":"19698","http_method":"GET","http_status":"200","remote_host":"10.66.2.46","remote_user":"-","url_path":"/manager/images/asf-logo.svg"}}
 `
 
 	timeout := time.After(5 * time.Second)
@@ -74,7 +83,7 @@ func TestTomcatExample(t *testing.T) {
 		case <-time.After(100 * time.Millisecond):
 			if len(strings.Split(buf.String(), "\n")) == len(strings.Split(expected, "\n")) {
 				defer cancel()
-				require.Equal(t, expected, buf.String())
+				require.Equal(t, expected, withoutObservedTimestamps(buf.String()))
 				return
 			}
 		case <-timeout:
@@ -124,7 +133,7 @@ func TestSimplePluginsExample(t *testing.T) {
 		case <-time.After(100 * time.Millisecond):
 			if len(strings.Split(buf.String(), "\n")) == len(strings.Split(expected, "\n")) {
 				defer cancel()
-				require.Equal(t, expected, buf.String())
+				require.Equal(t, expected, withoutObservedTimestamps(buf.String()))
 				return
 			}
 		case <-timeout:
@@ -196,3 +196,42 @@ pipeline:
 
 	graphTest(config, expected)(t)
 }
+
+func TestGraphErrorOutput(t *testing.T) {
+	config := `
+pipeline:
+  - type: generate_input
+    entry:
+      record:
+        test: value
+
+  - type: json_parser
+    on_error: route
+    error_output: quarantine
+
+  - project_id: testproject
+    type: google_cloud_output
+
+  - id: quarantine
+    type: stdout
+`
+
+	expected := `
+    strict digraph G {
+      // Node definitions.
+      "$.json_parser";
+      "$.google_cloud_output";
+      "$.quarantine";
+      "$.generate_input";
+
+      // Edge definitions.
+      "$.json_parser" -> "$.google_cloud_output";
+      "$.json_parser" -> "$.quarantine" [
+        label=error
+        style=dashed
+      ];
+      "$.generate_input" -> "$.json_parser";
+    }`
+
+	graphTest(config, expected)(t)
+}
@@ -8,11 +8,20 @@ import (
 	_ "github.com/observiq/stanza/operator/builtin/input/tcp"
 	_ "github.com/observiq/stanza/operator/builtin/input/udp"
 
+	_ "github.com/observiq/stanza/operator/builtin/parser/cef"
+	_ "github.com/observiq/stanza/operator/builtin/parser/container"
+	_ "github.com/observiq/stanza/operator/builtin/parser/csv"
+	_ "github.com/observiq/stanza/operator/builtin/parser/grok"
 	_ "github.com/observiq/stanza/operator/builtin/parser/json"
+	_ "github.com/observiq/stanza/operator/builtin/parser/keyvalue"
+	_ "github.com/observiq/stanza/operator/builtin/parser/layout"
+	_ "github.com/observiq/stanza/operator/builtin/parser/leef"
 	_ "github.com/observiq/stanza/operator/builtin/parser/regex"
 	_ "github.com/observiq/stanza/operator/builtin/parser/severity"
 	_ "github.com/observiq/stanza/operator/builtin/parser/syslog"
 	_ "github.com/observiq/stanza/operator/builtin/parser/time"
+	_ "github.com/observiq/stanza/operator/builtin/parser/trace"
+	_ "github.com/observiq/stanza/operator/builtin/parser/xml"
 
 	_ "github.com/observiq/stanza/operator/builtin/transformer/filter"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/hostmetadata"
@@ -20,6 +29,7 @@ import (
 	_ "github.com/observiq/stanza/operator/builtin/transformer/metadata"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/noop"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/ratelimit"
+	_ "github.com/observiq/stanza/operator/builtin/transformer/recombine"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/restructure"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/router"
 
@@ -60,9 +60,9 @@ pipeline:
 		require.NoError(t, err)
 	}()
 
-	expectedPattern := `{"timestamp":".*","severity":0,"labels":{"file_name":"input.log"},"record":{"message":"log1"}}
-{"timestamp":".*","severity":0,"labels":{"file_name":"input.log"},"record":{"message":"log2"}}
-{"timestamp":".*","severity":0,"labels":{"file_name":"input.log"},"record":{"message":"log3"}}
+	expectedPattern := `{"timestamp":".*","severity":0,"labels":{"file_name":"input.log"},"record":{"message":"log1"},"observed_timestamp":".*"}
+{"timestamp":".*","severity":0,"labels":{"file_name":"input.log"},"record":{"message":"log2"},"observed_timestamp":".*"}
+{"timestamp":".*","severity":0,"labels":{"file_name":"input.log"},"record":{"message":"log3"},"observed_timestamp":".*"}
 `
 
 	time.Sleep(1000 * time.Millisecond)
@@ -0,0 +1,249 @@
+package entry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// toInt64 will convert a value to an int64.
+// Floats are only converted if they have no fractional part,
+// and strings are parsed as base 10 integers.
+func toInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint:
+		return uintToInt64(uint64(v))
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		return uintToInt64(v)
+	case float32:
+		return floatToInt64(float64(v))
+	case float64:
+		return floatToInt64(v)
+	case json.Number:
+		return stringToInt64(string(v))
+	case string:
+		return stringToInt64(v)
+	case []byte:
+		return stringToInt64(string(v))
+	default:
+		return 0, fmt.Errorf("unsupported type")
+	}
+}
+
+// uintToInt64 will convert a uint64 to an int64 if it does not overflow.
+func uintToInt64(u uint64) (int64, error) {
+	if u > math.MaxInt64 {
+		return 0, fmt.Errorf("value %d overflows an int64", u)
+	}
+	return int64(u), nil
+}
+
+// floatToInt64 will convert a float64 to an int64 if it is a whole number in range.
+func floatToInt64(f float64) (int64, error) {
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("value %v has a fractional part", f)
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("value %v overflows an int64", f)
+	}
+	return int64(f), nil
+}
+
+// stringToInt64 will parse a string as an int64.
+// Strings in float notation are accepted if they are whole numbers, such as "1e3".
+func stringToInt64(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		return i, nil
+	}
+
+	f, floatErr := strconv.ParseFloat(s, 64)
+	if floatErr != nil {
+		return 0, fmt.Errorf("'%s' is not a valid integer", s)
+	}
+	return floatToInt64(f)
+}
+
+// toFloat64 will convert a value to a float64.
+// Strings are parsed as floating point numbers.
+func toFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case uint:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		i, err := toInt64(v)
+		return float64(i), err
+	case json.Number:
+		return stringToFloat64(string(v))
+	case string:
+		return stringToFloat64(v)
+	case []byte:
+		return stringToFloat64(string(v))
+	default:
+		return 0, fmt.Errorf("unsupported type")
+	}
+}
+
+// stringToFloat64 will parse a string as a float64.
+func stringToFloat64(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a valid float", s)
+	}
+	return f, nil
+}
+
+// toBool will convert a value to a bool.
+// Strings are parsed with strconv.ParseBool, so values like "true", "T" and "1" are accepted.
+func toBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return stringToBool(v)
+	case []byte:
+		return stringToBool(string(v))
+	default:
+		return false, fmt.Errorf("unsupported type")
+	}
+}
+
+// stringToBool will parse a string as a bool.
+func stringToBool(s string) (bool, error) {
+	s = strings.TrimSpace(s)
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("'%s' is not a valid bool", s)
+	}
+	return b, nil
+}
+
+// toTime will convert a value to a time.Time.
+// Strings are parsed as RFC 3339 timestamps, and numbers are treated as seconds since the epoch.
+func toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return stringToTime(v)
+	case []byte:
+		return stringToTime(string(v))
+	case bool:
+		return time.Time{}, fmt.Errorf("unsupported type")
+	default:
+		f, err := toFloat64(v)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*float64(time.Second))), nil
+	}
+}
+
+// stringToTime will parse a string as an RFC 3339 timestamp.
+func stringToTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is not a valid RFC 3339 timestamp", s)
+	}
+	return t, nil
+}
+
+// toDuration will convert a value to a time.Duration.
+// Strings are parsed with time.ParseDuration, and numbers are treated as seconds.
+func toDuration(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return stringToDuration(v)
+	case []byte:
+		return stringToDuration(string(v))
+	case bool:
+		return 0, fmt.Errorf("unsupported type")
+	default:
+		f, err := toFloat64(v)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(f * float64(time.Second)), nil
+	}
+}
+
+// stringToDuration will parse a string as a time.Duration.
+func stringToDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a valid duration", s)
+	}
+	return d, nil
+}
+
+// toStringArray will convert a value to an array of strings.
+// Every element of the array must be a string or byte array.
+func toStringArray(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case []string:
+		return v, nil
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for i, elem := range v {
+			switch s := elem.(type) {
+			case string:
+				result = append(result, s)
+			case []byte:
+				result = append(result, string(s))
+			default:
+				return nil, fmt.Errorf("element %d of type '%T' is not a string", i, elem)
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unsupported type")
+	}
+}
+
+// toInterfaceArray will convert a value to an array of interfaces.
+func toInterfaceArray(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, nil
+	case []string:
+		result := make([]interface{}, 0, len(v))
+		for _, s := range v {
+			result = append(result, s)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unsupported type")
+	}
+}
@@ -0,0 +1,159 @@
+package entry
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToInt64(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     interface{}
+		expected  int64
+		expectErr bool
+	}{
+		{"Int", 10, 10, false},
+		{"Int32", int32(-10), -10, false},
+		{"Uint64", uint64(10), 10, false},
+		{"Uint64Overflow", uint64(math.MaxUint64), 0, true},
+		{"WholeFloat", 10.0, 10, false},
+		{"FractionalFloat", 10.5, 0, true},
+		{"JSONNumber", json.Number("10"), 10, false},
+		{"JSONNumberExponent", json.Number("1e3"), 1000, false},
+		{"String", "10", 10, false},
+		{"StringWithSpaces", " -10 ", -10, false},
+		{"Bytes", []byte("10"), 10, false},
+		{"InvalidString", "ten", 0, true},
+		{"Bool", true, 0, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			i, err := toInt64(tc.input)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, i)
+		})
+	}
+}
+
+func TestToFloat64(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     interface{}
+		expected  float64
+		expectErr bool
+	}{
+		{"Float", 1.5, 1.5, false},
+		{"Float32", float32(1.5), 1.5, false},
+		{"Int", 10, 10, false},
+		{"Uint64", uint64(math.MaxUint64), math.MaxUint64, false},
+		{"JSONNumber", json.Number("1.5"), 1.5, false},
+		{"String", "1.5", 1.5, false},
+		{"InvalidString", "one", 0, true},
+		{"Map", map[string]interface{}{}, 0, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := toFloat64(tc.input)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, f)
+		})
+	}
+}
+
+func TestToBool(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     interface{}
+		expected  bool
+		expectErr bool
+	}{
+		{"Bool", true, true, false},
+		{"String", "false", false, false},
+		{"ShortString", "T", true, false},
+		{"NumericString", "1", true, false},
+		{"InvalidString", "yes", false, true},
+		{"Int", 1, false, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := toBool(tc.input)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, b)
+		})
+	}
+}
+
+func TestToTime(t *testing.T) {
+	expected := time.Date(2020, 9, 24, 12, 30, 0, 500000000, time.UTC)
+	cases := []struct {
+		name      string
+		input     interface{}
+		expectErr bool
+	}{
+		{"Time", expected, false},
+		{"String", "2020-09-24T12:30:00.5Z", false},
+		{"Float", 1600950600.5, false},
+		{"JSONNumber", json.Number("1600950600.5"), false},
+		{"InvalidString", "yesterday", true},
+		{"Bool", true, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts, err := toTime(tc.input)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, expected.Equal(ts), "expected %s, got %s", expected, ts)
+		})
+	}
+}
+
+func TestToDuration(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     interface{}
+		expected  time.Duration
+		expectErr bool
+	}{
+		{"Duration", time.Second, time.Second, false},
+		{"String", "12ms", 12 * time.Millisecond, false},
+		{"Int", 2, 2 * time.Second, false},
+		{"Float", 0.5, 500 * time.Millisecond, false},
+		{"InvalidString", "12 minutes", 0, true},
+		{"Bool", false, 0, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := toDuration(tc.input)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, d)
+		})
+	}
+}
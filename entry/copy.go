@@ -11,6 +11,8 @@ func copyValue(v interface{}) interface{} {
 		return copyStringMap(value)
 	case map[string]interface{}:
 		return copyInterfaceMap(value)
+	case *OrderedMap:
+		return value.Copy()
 	case []string:
 		return copyStringArray(value)
 	case []byte:
@@ -50,7 +52,11 @@ func copyStringArray(a []string) []string {
 }
 
 // copyByteArray will deep copy an array of bytes.
+// A nil array is copied as nil.
 func copyByteArray(a []byte) []byte {
+	if a == nil {
+		return nil
+	}
 	arrayCopy := make([]byte, len(a))
 	copy(arrayCopy, a)
 	return arrayCopy
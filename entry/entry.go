@@ -1,17 +1,33 @@
 package entry
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 )
 
 // Entry is a flexible representation of log data associated with a timestamp.
+//
+// The labels, resource and record of a shared entry are shared with the entries it was
+// shared with, until one of them modifies them through its methods or reads a map or
+// array from its record with Get, at which point that entry receives its own copy.
+// Operators should therefore modify these values through the entry's methods, or
+// replace them entirely, rather than modifying them in place.
 type Entry struct {
-	Timestamp time.Time         `json:"timestamp"        yaml:"timestamp"`
-	Severity  Severity          `json:"severity"         yaml:"severity"`
-	Labels    map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
-	Resource  map[string]string `json:"resource,omitempty" yaml:"resource,omitempty"`
-	Record    interface{}       `json:"record"           yaml:"record"`
+	Timestamp         time.Time         `json:"timestamp"               yaml:"timestamp"`
+	ObservedTimestamp time.Time         `json:"observed_timestamp"      yaml:"observed_timestamp,omitempty"`
+	Severity          Severity          `json:"severity"                yaml:"severity"`
+	SeverityText      string            `json:"severity_text,omitempty" yaml:"severity_text,omitempty"`
+	TraceID           []byte            `json:"trace_id,omitempty"      yaml:"trace_id,omitempty"`
+	SpanID            []byte            `json:"span_id,omitempty"       yaml:"span_id,omitempty"`
+	TraceFlags        []byte            `json:"trace_flags,omitempty"   yaml:"trace_flags,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"        yaml:"labels,omitempty"`
+	Resource          map[string]string `json:"resource,omitempty"      yaml:"resource,omitempty"`
+	Record            interface{}       `json:"record"                  yaml:"record"`
+
+	labelsRef   *sharedRef
+	resourceRef *sharedRef
+	recordRef   *sharedRef
 }
 
 // New will create a new log entry with current timestamp and an empty record.
@@ -21,8 +37,31 @@ func New() *Entry {
 	}
 }
 
+// entryAlias has the fields of an entry without its MarshalJSON method.
+type entryAlias Entry
+
+// entryJSON is the JSON representation of an entry, which omits an observed timestamp that is not set.
+type entryJSON struct {
+	entryAlias
+	ObservedTimestamp *time.Time `json:"observed_timestamp,omitempty"`
+}
+
+// MarshalJSON will marshal the entry as JSON, omitting the observed timestamp if it is not set.
+func (entry Entry) MarshalJSON() ([]byte, error) {
+	var observedTimestamp *time.Time
+	if !entry.ObservedTimestamp.IsZero() {
+		observedTimestamp = &entry.ObservedTimestamp
+	}
+
+	return json.Marshal(entryJSON{
+		entryAlias:        entryAlias(entry),
+		ObservedTimestamp: observedTimestamp,
+	})
+}
+
 // AddLabel will add a key/value pair to the entry's labels.
 func (entry *Entry) AddLabel(key, value string) {
+	entry.ownLabels()
 	if entry.Labels == nil {
 		entry.Labels = make(map[string]string)
 	}
@@ -31,6 +70,7 @@ func (entry *Entry) AddLabel(key, value string) {
 
 // AddResourceKey wil add a key/value pair to the entry's resource.
 func (entry *Entry) AddResourceKey(key, value string) {
+	entry.ownResource()
 	if entry.Resource == nil {
 		entry.Resource = make(map[string]string)
 	}
@@ -63,6 +103,22 @@ func (entry *Entry) Read(field FieldInterface, dest interface{}) error {
 		return entry.readToStringMap(field, dest)
 	case *interface{}:
 		return entry.readToInterface(field, dest)
+	case *int:
+		return entry.readToInt(field, dest)
+	case *int64:
+		return entry.readToInt64(field, dest)
+	case *float64:
+		return entry.readToFloat64(field, dest)
+	case *bool:
+		return entry.readToBool(field, dest)
+	case *time.Time:
+		return entry.readToTime(field, dest)
+	case *time.Duration:
+		return entry.readToDuration(field, dest)
+	case *[]string:
+		return entry.readToStringArray(field, dest)
+	case *[]interface{}:
+		return entry.readToInterfaceArray(field, dest)
 	default:
 		return fmt.Errorf("can not read to unsupported type '%T'", dest)
 	}
@@ -105,9 +161,12 @@ func (entry *Entry) readToInterfaceMap(field FieldInterface, dest *map[string]in
 		return fmt.Errorf("field '%s' is missing and can not be read as a map[string]interface{}", field)
 	}
 
-	if m, ok := val.(map[string]interface{}); ok {
+	switch m := val.(type) {
+	case map[string]interface{}:
 		*dest = m
-	} else {
+	case *OrderedMap:
+		*dest = m.Map()
+	default:
 		return fmt.Errorf("field '%s' of type '%T' can not be cast to a map[string]interface{}", field, val)
 	}
 
@@ -121,14 +180,20 @@ func (entry *Entry) readToStringMap(field FieldInterface, dest *map[string]strin
 		return fmt.Errorf("field '%s' is missing and can not be read as a map[string]string{}", field)
 	}
 
+	if orderedMap, ok := val.(*OrderedMap); ok {
+		val = orderedMap.Map()
+	}
+
 	switch m := val.(type) {
+	case map[string]string:
+		*dest = m
 	case map[string]interface{}:
 		newDest := make(map[string]string)
 		for k, v := range m {
 			if vStr, ok := v.(string); ok {
 				newDest[k] = vStr
 			} else {
-				return fmt.Errorf("can not cast map members '%s' of type '%s' to string", k, v)
+				return fmt.Errorf("field '%s' can not cast map member '%s' of type '%T' to string", field, k, v)
 			}
 		}
 		*dest = newDest
@@ -137,27 +202,160 @@ func (entry *Entry) readToStringMap(field FieldInterface, dest *map[string]strin
 		for k, v := range m {
 			kStr, ok := k.(string)
 			if !ok {
-				return fmt.Errorf("can not cast map key of type '%T' to string", k)
+				return fmt.Errorf("field '%s' can not cast map key of type '%T' to string", field, k)
 			}
 			vStr, ok := v.(string)
 			if !ok {
-				return fmt.Errorf("can not cast map value of type '%T' to string", v)
+				return fmt.Errorf("field '%s' can not cast map value of type '%T' to string", field, v)
 			}
 			newDest[kStr] = vStr
 		}
 		*dest = newDest
+	default:
+		return fmt.Errorf("field '%s' of type '%T' can not be cast to a map[string]string", field, val)
+	}
+
+	return nil
+}
+
+// readToInt reads a field to a designated int pointer.
+func (entry *Entry) readToInt(field FieldInterface, dest *int) error {
+	var i int64
+	if err := entry.readToInt64(field, &i); err != nil {
+		return err
+	}
+	if int64(int(i)) != i {
+		return fmt.Errorf("field '%s' with value %d overflows an int", field, i)
+	}
+
+	*dest = int(i)
+	return nil
+}
+
+// readToInt64 reads a field to a designated int64 pointer.
+func (entry *Entry) readToInt64(field FieldInterface, dest *int64) error {
+	val, ok := entry.Get(field)
+	if !ok {
+		return fmt.Errorf("field '%s' is missing and can not be read as an int", field)
+	}
+
+	i, err := toInt64(val)
+	if err != nil {
+		return fmt.Errorf("field '%s' of type '%T' can not be converted to an int: %s", field, val, err)
+	}
+
+	*dest = i
+	return nil
+}
+
+// readToFloat64 reads a field to a designated float64 pointer.
+func (entry *Entry) readToFloat64(field FieldInterface, dest *float64) error {
+	val, ok := entry.Get(field)
+	if !ok {
+		return fmt.Errorf("field '%s' is missing and can not be read as a float", field)
+	}
+
+	f, err := toFloat64(val)
+	if err != nil {
+		return fmt.Errorf("field '%s' of type '%T' can not be converted to a float: %s", field, val, err)
+	}
+
+	*dest = f
+	return nil
+}
+
+// readToBool reads a field to a designated bool pointer.
+func (entry *Entry) readToBool(field FieldInterface, dest *bool) error {
+	val, ok := entry.Get(field)
+	if !ok {
+		return fmt.Errorf("field '%s' is missing and can not be read as a bool", field)
+	}
+
+	b, err := toBool(val)
+	if err != nil {
+		return fmt.Errorf("field '%s' of type '%T' can not be converted to a bool: %s", field, val, err)
+	}
+
+	*dest = b
+	return nil
+}
+
+// readToTime reads a field to a designated time pointer.
+func (entry *Entry) readToTime(field FieldInterface, dest *time.Time) error {
+	val, ok := entry.Get(field)
+	if !ok {
+		return fmt.Errorf("field '%s' is missing and can not be read as a time", field)
+	}
+
+	t, err := toTime(val)
+	if err != nil {
+		return fmt.Errorf("field '%s' of type '%T' can not be converted to a time: %s", field, val, err)
+	}
+
+	*dest = t
+	return nil
+}
+
+// readToDuration reads a field to a designated duration pointer.
+func (entry *Entry) readToDuration(field FieldInterface, dest *time.Duration) error {
+	val, ok := entry.Get(field)
+	if !ok {
+		return fmt.Errorf("field '%s' is missing and can not be read as a duration", field)
+	}
+
+	d, err := toDuration(val)
+	if err != nil {
+		return fmt.Errorf("field '%s' of type '%T' can not be converted to a duration: %s", field, val, err)
+	}
+
+	*dest = d
+	return nil
+}
+
+// readToStringArray reads a field to a designated string array pointer.
+func (entry *Entry) readToStringArray(field FieldInterface, dest *[]string) error {
+	val, ok := entry.Get(field)
+	if !ok {
+		return fmt.Errorf("field '%s' is missing and can not be read as a []string", field)
+	}
+
+	a, err := toStringArray(val)
+	if err != nil {
+		return fmt.Errorf("field '%s' of type '%T' can not be converted to a []string: %s", field, val, err)
+	}
+
+	*dest = a
+	return nil
+}
+
+// readToInterfaceArray reads a field to a designated interface array pointer.
+func (entry *Entry) readToInterfaceArray(field FieldInterface, dest *[]interface{}) error {
+	val, ok := entry.Get(field)
+	if !ok {
+		return fmt.Errorf("field '%s' is missing and can not be read as a []interface{}", field)
+	}
+
+	a, err := toInterfaceArray(val)
+	if err != nil {
+		return fmt.Errorf("field '%s' of type '%T' can not be converted to a []interface{}: %s", field, val, err)
 	}
 
+	*dest = a
 	return nil
 }
 
 // Copy will return a deep copy of the entry.
 func (entry *Entry) Copy() *Entry {
 	return &Entry{
-		Timestamp: entry.Timestamp,
-		Severity:  entry.Severity,
-		Labels:    copyStringMap(entry.Labels),
-		Resource:  copyStringMap(entry.Resource),
-		Record:    copyValue(entry.Record),
+		Timestamp:         entry.Timestamp,
+		ObservedTimestamp: entry.ObservedTimestamp,
+		Severity:          entry.Severity,
+		SeverityText:      entry.SeverityText,
+		TraceID:           copyByteArray(entry.TraceID),
+		SpanID:            copyByteArray(entry.SpanID),
+		TraceFlags:        copyByteArray(entry.TraceFlags),
+		Labels:            copyStringMap(entry.Labels),
+		Resource:          copyStringMap(entry.Resource),
+		Record:            copyValue(entry.Record),
 	}
 }
@@ -1,6 +1,8 @@
 package entry
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -116,30 +118,236 @@ func TestRead(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, "test", i)
 	})
+
+	t.Run("map[string]string from string error", func(t *testing.T) {
+		var m map[string]string
+		err := testEntry.Read(NewRecordField("string_field"), &m)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "can not be cast to a map[string]string")
+	})
+}
+
+func TestReadConversions(t *testing.T) {
+	testEntry := &Entry{
+		Record: map[string]interface{}{
+			"int_field":      42,
+			"string_int":     "42",
+			"float_field":    1.5,
+			"json_number":    json.Number("7"),
+			"bool_string":    "true",
+			"time_string":    "2020-09-24T12:30:00Z",
+			"epoch_field":    1600950600,
+			"duration_field": "1m30s",
+			"string_array":   []interface{}{"a", "b"},
+			"mixed_array":    []interface{}{"a", 1},
+			"invalid_string": "not a number",
+		},
+	}
+
+	t.Run("int from int", func(t *testing.T) {
+		var i int
+		require.NoError(t, testEntry.Read(NewRecordField("int_field"), &i))
+		require.Equal(t, 42, i)
+	})
+
+	t.Run("int from string", func(t *testing.T) {
+		var i int
+		require.NoError(t, testEntry.Read(NewRecordField("string_int"), &i))
+		require.Equal(t, 42, i)
+	})
+
+	t.Run("int64 from json.Number", func(t *testing.T) {
+		var i int64
+		require.NoError(t, testEntry.Read(NewRecordField("json_number"), &i))
+		require.Equal(t, int64(7), i)
+	})
+
+	t.Run("int from fractional float error", func(t *testing.T) {
+		var i int
+		err := testEntry.Read(NewRecordField("float_field"), &i)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'float_field' of type 'float64' can not be converted to an int")
+	})
+
+	t.Run("int from invalid string error", func(t *testing.T) {
+		var i int
+		err := testEntry.Read(NewRecordField("invalid_string"), &i)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "'not a number' is not a valid integer")
+	})
+
+	t.Run("int missing error", func(t *testing.T) {
+		var i int
+		err := testEntry.Read(NewRecordField("missing"), &i)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'missing' is missing and can not be read as an int")
+	})
+
+	t.Run("float64 from float", func(t *testing.T) {
+		var f float64
+		require.NoError(t, testEntry.Read(NewRecordField("float_field"), &f))
+		require.Equal(t, 1.5, f)
+	})
+
+	t.Run("float64 from string", func(t *testing.T) {
+		var f float64
+		require.NoError(t, testEntry.Read(NewRecordField("string_int"), &f))
+		require.Equal(t, 42.0, f)
+	})
+
+	t.Run("bool from string", func(t *testing.T) {
+		var b bool
+		require.NoError(t, testEntry.Read(NewRecordField("bool_string"), &b))
+		require.True(t, b)
+	})
+
+	t.Run("bool from int error", func(t *testing.T) {
+		var b bool
+		err := testEntry.Read(NewRecordField("int_field"), &b)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "can not be converted to a bool")
+	})
+
+	t.Run("time from string", func(t *testing.T) {
+		var ts time.Time
+		require.NoError(t, testEntry.Read(NewRecordField("time_string"), &ts))
+		require.Equal(t, time.Date(2020, 9, 24, 12, 30, 0, 0, time.UTC), ts)
+	})
+
+	t.Run("time from epoch", func(t *testing.T) {
+		var ts time.Time
+		require.NoError(t, testEntry.Read(NewRecordField("epoch_field"), &ts))
+		require.True(t, time.Date(2020, 9, 24, 12, 30, 0, 0, time.UTC).Equal(ts))
+	})
+
+	t.Run("duration from string", func(t *testing.T) {
+		var d time.Duration
+		require.NoError(t, testEntry.Read(NewRecordField("duration_field"), &d))
+		require.Equal(t, 90*time.Second, d)
+	})
+
+	t.Run("duration from int", func(t *testing.T) {
+		var d time.Duration
+		require.NoError(t, testEntry.Read(NewRecordField("int_field"), &d))
+		require.Equal(t, 42*time.Second, d)
+	})
+
+	t.Run("[]string from []interface{}", func(t *testing.T) {
+		var a []string
+		require.NoError(t, testEntry.Read(NewRecordField("string_array"), &a))
+		require.Equal(t, []string{"a", "b"}, a)
+	})
+
+	t.Run("[]string from mixed array error", func(t *testing.T) {
+		var a []string
+		err := testEntry.Read(NewRecordField("mixed_array"), &a)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "element 1 of type 'int' is not a string")
+	})
+
+	t.Run("[]interface{} from []interface{}", func(t *testing.T) {
+		var a []interface{}
+		require.NoError(t, testEntry.Read(NewRecordField("mixed_array"), &a))
+		require.Equal(t, []interface{}{"a", 1}, a)
+	})
+
+	t.Run("[]interface{} from string error", func(t *testing.T) {
+		var a []interface{}
+		err := testEntry.Read(NewRecordField("invalid_string"), &a)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "can not be converted to a []interface{}")
+	})
 }
 
 func TestCopy(t *testing.T) {
 	entry := New()
 	entry.Severity = Severity(0)
+	entry.SeverityText = "ok"
 	entry.Timestamp = time.Time{}
 	entry.Record = "test"
 	entry.Labels = map[string]string{"label": "value"}
 	entry.Resource = map[string]string{"resource": "value"}
+	entry.ObservedTimestamp = time.Time{}
+	entry.TraceID = []byte{0x01}
+	entry.SpanID = []byte{0x02}
+	entry.TraceFlags = []byte{0x03}
 	copy := entry.Copy()
 
 	entry.Severity = Severity(1)
+	entry.SeverityText = "1"
 	entry.Timestamp = time.Now()
 	entry.Record = "new"
 	entry.Labels = map[string]string{"label": "new value"}
 	entry.Resource = map[string]string{"resource": "new value"}
+	entry.ObservedTimestamp = time.Now()
+	entry.TraceID[0] = 0xff
+	entry.SpanID[0] = 0xff
+	entry.TraceFlags[0] = 0xff
 
 	require.Equal(t, time.Time{}, copy.Timestamp)
+	require.Equal(t, time.Time{}, copy.ObservedTimestamp)
+	require.Equal(t, []byte{0x01}, copy.TraceID)
+	require.Equal(t, []byte{0x02}, copy.SpanID)
+	require.Equal(t, []byte{0x03}, copy.TraceFlags)
 	require.Equal(t, Severity(0), copy.Severity)
+	require.Equal(t, "ok", copy.SeverityText)
 	require.Equal(t, map[string]string{"label": "value"}, copy.Labels)
 	require.Equal(t, map[string]string{"resource": "value"}, copy.Resource)
 	require.Equal(t, "test", copy.Record)
 }
 
+func TestCopyNestedRecord(t *testing.T) {
+	entry := New()
+	entry.Record = map[string]interface{}{
+		"nested": map[string]interface{}{"key": "value"},
+	}
+	copies := []*Entry{entry.Copy(), entry.Copy()}
+
+	// Modifying a nested map in place does not modify the other copies
+	nested, ok := copies[0].Get(NewRecordField("nested"))
+	require.True(t, ok)
+	nested.(map[string]interface{})["key"] = "new value"
+
+	expected := map[string]interface{}{
+		"nested": map[string]interface{}{"key": "value"},
+	}
+	require.Equal(t, expected, entry.Record)
+	require.Equal(t, expected, copies[1].Record)
+}
+
+func TestCopyOrderedMap(t *testing.T) {
+	entry := New()
+	entry.Record = testOrderedMap()
+	copy := entry.Copy()
+
+	err := entry.Set(NewRecordField("mango", "yak"), "new")
+	require.NoError(t, err)
+
+	require.Equal(t, testOrderedMap(), copy.Record)
+}
+
+func TestMarshalJSONObservedTimestamp(t *testing.T) {
+	entry := New()
+	entry.Timestamp = time.Date(2020, 9, 24, 10, 12, 13, 0, time.UTC)
+	entry.Record = "test"
+
+	marshalled, err := json.Marshal(entry)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"timestamp":"2020-09-24T10:12:13Z","severity":0,"record":"test"}`, string(marshalled))
+
+	var unmarshalled Entry
+	require.NoError(t, json.Unmarshal(marshalled, &unmarshalled))
+	require.True(t, unmarshalled.ObservedTimestamp.IsZero())
+
+	entry.ObservedTimestamp = entry.Timestamp.Add(time.Second)
+	marshalled, err = json.Marshal(entry)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"timestamp":"2020-09-24T10:12:13Z","observed_timestamp":"2020-09-24T10:12:14Z","severity":0,"record":"test"}`, string(marshalled))
+
+	require.NoError(t, json.Unmarshal(marshalled, &unmarshalled))
+	require.True(t, entry.ObservedTimestamp.Equal(unmarshalled.ObservedTimestamp))
+}
+
 func TestFieldFromString(t *testing.T) {
 	cases := []struct {
 		name          string
@@ -232,3 +440,44 @@ func TestReadToInterfaceMissingField(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "can not be read as a interface{}")
 }
+
+func TestReadOrderedMap(t *testing.T) {
+	entry := New()
+	entry.Record = testOrderedMap()
+
+	var interfaceMap map[string]interface{}
+	err := entry.Read(NewRecordField(), &interfaceMap)
+	require.NoError(t, err)
+	require.Equal(t, testOrderedMap().Map(), interfaceMap)
+
+	var stringMap map[string]string
+	err = entry.Read(NewRecordField("mango"), &stringMap)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"yak": "y", "bee": "b"}, stringMap)
+}
+
+func benchmarkEntry() *Entry {
+	entry := New()
+	entry.Labels = map[string]string{"label1": "value1", "label2": "value2"}
+	entry.Resource = map[string]string{"host.name": "host", "service.name": "service"}
+	record := map[string]interface{}{
+		"message": "this is a test message",
+		"nested": map[string]interface{}{
+			"list": []interface{}{"a", "b", "c", "d"},
+		},
+	}
+	for i := 0; i < 20; i++ {
+		record[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value%d", i)
+	}
+	entry.Record = record
+	return entry
+}
+
+func BenchmarkCopy(b *testing.B) {
+	entry := benchmarkEntry()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = entry.Copy()
+	}
+}
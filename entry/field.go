@@ -65,6 +65,11 @@ func fieldFromString(s string) (Field, error) {
 			return Field{}, fmt.Errorf("resource fields cannot be nested")
 		}
 		return Field{ResourceField{split[1]}}, nil
+	case traceIDPrefix, spanIDPrefix, traceFlagsPrefix:
+		if len(split) != 1 {
+			return Field{}, fmt.Errorf("trace fields cannot be nested")
+		}
+		return traceFieldFromPrefix(split[0]), nil
 	case recordPrefix, "$":
 		return Field{RecordField{split[1:]}}, nil
 	default:
@@ -97,6 +102,8 @@ const (
 	OUT_BRACKET
 	// IN_UNBRACKETED_TOKEN is the state field split on any token outside brackets
 	IN_UNBRACKETED_TOKEN
+	// IN_INDEX is the state of a field split inside an unquoted array index
+	IN_INDEX
 )
 
 func splitField(s string) ([]string, error) {
@@ -116,12 +123,27 @@ func splitField(s string) ([]string, error) {
 			tokenStart = i
 			state = IN_UNBRACKETED_TOKEN
 		case IN_BRACKET:
+			if c == '-' || c == '*' || (c >= '0' && c <= '9') {
+				state = IN_INDEX
+				tokenStart = i
+				continue
+			}
 			if !(c == '\'' || c == '"') {
 				return nil, fmt.Errorf("strings in brackets must be surrounded by quotes")
 			}
 			state = IN_QUOTE
 			quoteChar = c
 			tokenStart = i + 1
+		case IN_INDEX:
+			if c != ']' {
+				continue
+			}
+			index := s[tokenStart:i]
+			if !isIndexKey(index) {
+				return nil, fmt.Errorf("'%s' is not a valid array index", index)
+			}
+			fields = append(fields, index)
+			state = OUT_BRACKET
 		case IN_QUOTE:
 			if c == quoteChar {
 				fields = append(fields, s[tokenStart:i])
@@ -154,7 +176,7 @@ func splitField(s string) ([]string, error) {
 	}
 
 	switch state {
-	case IN_BRACKET, OUT_QUOTE:
+	case IN_BRACKET, OUT_QUOTE, IN_INDEX:
 		return nil, fmt.Errorf("found unclosed left bracket")
 	case IN_QUOTE:
 		if quoteChar == '"' {
@@ -109,6 +109,11 @@ func TestFieldUnmarshalYAML(t *testing.T) {
 			[]byte(`"$.test1.test2"`),
 			NewRecordField("test1", "test2"),
 		},
+		{
+			"FieldWithIndex",
+			[]byte(`"$record.test1[-1].test2"`),
+			NewRecordField("test1", "-1", "test2"),
+		},
 	}
 
 	for _, tc := range cases {
@@ -186,6 +191,16 @@ func TestFieldMarshalYAML(t *testing.T) {
 			NewResourceField("test.1"),
 			"$resource['test.1']\n",
 		},
+		{
+			"FieldWithIndex",
+			NewRecordField("test1", "0", "test2"),
+			"test1[0].test2\n",
+		},
+		{
+			"FieldWithWildcard",
+			NewRecordField("test1", "*"),
+			"test1[*]\n",
+		},
 	}
 
 	for _, tc := range cases {
@@ -226,6 +241,15 @@ func TestSplitField(t *testing.T) {
 		{"BracketMissingQuotes", `$record[test]`, nil, true},
 		{"CharacterBetweenBracketAndQuote", `$record["test"a]`, nil, true},
 		{"CharacterOutsideBracket", `$record["test"]a`, nil, true},
+		{"ArrayIndex", `$record.items[0]`, []string{"$record", "items", "0"}, false},
+		{"ArrayIndexThenDot", `$record.items[10].name`, []string{"$record", "items", "10", "name"}, false},
+		{"NegativeArrayIndex", `$record.items[-1]`, []string{"$record", "items", "-1"}, false},
+		{"ArrayWildcard", `$record.items[*].name`, []string{"$record", "items", "*", "name"}, false},
+		{"NestedArrayIndex", `$record.items[0][1]`, []string{"$record", "items", "0", "1"}, false},
+		{"QuotedKeyThenIndex", `$record["items"][0]`, []string{"$record", "items", "0"}, false},
+		{"InvalidArrayIndex", `$record.items[0a]`, nil, true},
+		{"UnclosedArrayIndex", `$record.items[0`, nil, true},
+		{"CharacterAfterArrayIndex", `$record.items[0]a`, nil, true},
 	}
 
 	for _, tc := range cases {
@@ -21,6 +21,7 @@ func (l LabelField) Get(entry *Entry) (interface{}, bool) {
 
 // Set will set the label value on an entry
 func (l LabelField) Set(entry *Entry, val interface{}) error {
+	entry.ownLabels()
 	if entry.Labels == nil {
 		entry.Labels = make(map[string]string, 1)
 	}
@@ -35,6 +36,7 @@ func (l LabelField) Set(entry *Entry, val interface{}) error {
 
 // Delete will delete a label from an entry
 func (l LabelField) Delete(entry *Entry) (interface{}, bool) {
+	entry.ownLabels()
 	if entry.Labels == nil {
 		return "", false
 	}
@@ -0,0 +1,302 @@
+package entry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// OrderedMap is a map of string keys to values that remembers the order in which
+// keys were inserted. It can be used as a record, or as a value within a record,
+// in place of a map[string]interface{} when the order of keys should be preserved.
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap will create a new, empty ordered map.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{
+		keys:   []string{},
+		values: map[string]interface{}{},
+	}
+}
+
+// Len will return the number of keys in the map.
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Keys will return the keys of the map in insertion order.
+func (m *OrderedMap) Keys() []string {
+	keys := make([]string, len(m.keys))
+	copy(keys, m.keys)
+	return keys
+}
+
+// Get will return the value of a key and whether it exists.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Set will set the value of a key. A new key is added to the end of the map,
+// while an existing key keeps its position.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = map[string]interface{}{}
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete will remove a key from the map.
+// It will return the deleted value and whether the key existed.
+func (m *OrderedMap) Delete(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	if !ok {
+		return nil, false
+	}
+
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+	return value, true
+}
+
+// Copy will deep copy the map.
+func (m *OrderedMap) Copy() *OrderedMap {
+	mapCopy := &OrderedMap{
+		keys:   make([]string, len(m.keys)),
+		values: make(map[string]interface{}, len(m.values)),
+	}
+	copy(mapCopy.keys, m.keys)
+	for k, v := range m.values {
+		mapCopy.values[k] = copyValue(v)
+	}
+	return mapCopy
+}
+
+// Values will return the values of the map by key, without their order.
+// The returned map belongs to the ordered map, so it must not be modified.
+func (m *OrderedMap) Values() map[string]interface{} {
+	return m.values
+}
+
+// Map will return the contents of the ordered map as a map[string]interface{}.
+// Ordered maps nested within the map are converted as well.
+func (m *OrderedMap) Map() map[string]interface{} {
+	result := make(map[string]interface{}, len(m.values))
+	for k, v := range m.values {
+		result[k] = PlainValue(v)
+	}
+	return result
+}
+
+// PlainValue will return a value with any ordered maps it contains converted to
+// a map[string]interface{}. Values without ordered maps are returned unchanged.
+func PlainValue(value interface{}) interface{} {
+	plain, _ := plainValue(value)
+	return plain
+}
+
+// plainValue will convert the ordered maps in a value, and return whether the value
+// had to be changed. Maps and arrays are only copied if one of their elements changed.
+func plainValue(value interface{}) (interface{}, bool) {
+	switch typed := value.(type) {
+	case *OrderedMap:
+		return typed.Map(), true
+	case map[string]interface{}:
+		var result map[string]interface{}
+		for k, v := range typed {
+			plain, changed := plainValue(v)
+			if !changed {
+				continue
+			}
+			if result == nil {
+				result = make(map[string]interface{}, len(typed))
+				for key, elem := range typed {
+					result[key] = elem
+				}
+			}
+			result[k] = plain
+		}
+		if result == nil {
+			return typed, false
+		}
+		return result, true
+	case []interface{}:
+		var result []interface{}
+		for i, v := range typed {
+			plain, changed := plainValue(v)
+			if !changed {
+				continue
+			}
+			if result == nil {
+				result = make([]interface{}, len(typed))
+				copy(result, typed)
+			}
+			result[i] = plain
+		}
+		if result == nil {
+			return typed, false
+		}
+		return result, true
+	default:
+		return value, false
+	}
+}
+
+// rangeMap will call a function for each key and value of a map[string]interface{}
+// or ordered map. Ordered maps are walked in insertion order.
+func rangeMap(m interface{}, f func(key string, value interface{})) {
+	switch typed := m.(type) {
+	case map[string]interface{}:
+		for k, v := range typed {
+			f(k, v)
+		}
+	case *OrderedMap:
+		for _, k := range typed.keys {
+			f(k, typed.values[k])
+		}
+	}
+}
+
+/****************
+  Serialization
+****************/
+
+// MarshalJSON will marshal the map as a JSON object with keys in insertion order.
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON will unmarshal a JSON object into the map, keeping the order of its keys.
+// Nested objects are unmarshalled as ordered maps.
+func (m *OrderedMap) UnmarshalJSON(raw []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected a JSON object")
+	}
+
+	parsed, err := decodeObject(decoder)
+	if err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after JSON object")
+	}
+
+	*m = *parsed
+	return nil
+}
+
+// decodeObject will decode the remainder of a JSON object after its opening delimiter.
+func decodeObject(decoder *json.Decoder) (*OrderedMap, error) {
+	m := NewOrderedMap()
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a JSON object key, but found %v", token)
+		}
+
+		value, err := decodeValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		m.Set(key, value)
+	}
+
+	// Consume the closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// decodeArray will decode the remainder of a JSON array after its opening delimiter.
+func decodeArray(decoder *json.Decoder) ([]interface{}, error) {
+	array := []interface{}{}
+	for decoder.More() {
+		value, err := decodeValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+
+	// Consume the closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return array, nil
+}
+
+// decodeValue will decode the next JSON value.
+func decodeValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		return decodeObject(decoder)
+	case '[':
+		return decodeArray(decoder)
+	default:
+		return nil, fmt.Errorf("unexpected JSON delimiter %s", delim)
+	}
+}
+
+// MarshalYAML will marshal the map as a YAML mapping with keys in insertion order.
+func (m *OrderedMap) MarshalYAML() (interface{}, error) {
+	slice := make(yaml.MapSlice, 0, len(m.keys))
+	for _, k := range m.keys {
+		slice = append(slice, yaml.MapItem{Key: k, Value: m.values[k]})
+	}
+	return slice, nil
+}
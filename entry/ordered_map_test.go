@@ -0,0 +1,162 @@
+package entry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func testOrderedMap() *OrderedMap {
+	m := NewOrderedMap()
+	m.Set("zebra", "z")
+	m.Set("apple", "a")
+	nested := NewOrderedMap()
+	nested.Set("yak", "y")
+	nested.Set("bee", "b")
+	m.Set("mango", nested)
+	return m
+}
+
+func TestOrderedMapSet(t *testing.T) {
+	m := testOrderedMap()
+	require.Equal(t, []string{"zebra", "apple", "mango"}, m.Keys())
+	require.Equal(t, 3, m.Len())
+
+	m.Set("apple", "new")
+	require.Equal(t, []string{"zebra", "apple", "mango"}, m.Keys())
+	value, ok := m.Get("apple")
+	require.True(t, ok)
+	require.Equal(t, "new", value)
+
+	m.Set("banana", "b")
+	require.Equal(t, []string{"zebra", "apple", "mango", "banana"}, m.Keys())
+}
+
+func TestOrderedMapZeroValue(t *testing.T) {
+	var m OrderedMap
+	_, ok := m.Get("key")
+	require.False(t, ok)
+
+	m.Set("key", "value")
+	value, ok := m.Get("key")
+	require.True(t, ok)
+	require.Equal(t, "value", value)
+}
+
+func TestOrderedMapDelete(t *testing.T) {
+	m := testOrderedMap()
+
+	value, ok := m.Delete("apple")
+	require.True(t, ok)
+	require.Equal(t, "a", value)
+	require.Equal(t, []string{"zebra", "mango"}, m.Keys())
+
+	_, ok = m.Get("apple")
+	require.False(t, ok)
+
+	value, ok = m.Delete("apple")
+	require.False(t, ok)
+	require.Nil(t, value)
+}
+
+func TestOrderedMapCopy(t *testing.T) {
+	m := testOrderedMap()
+	mapCopy := m.Copy()
+
+	m.Set("zebra", "new")
+	nested, _ := m.Get("mango")
+	nested.(*OrderedMap).Set("yak", "new")
+
+	require.Equal(t, testOrderedMap(), mapCopy)
+}
+
+func TestOrderedMapMap(t *testing.T) {
+	expected := map[string]interface{}{
+		"zebra": "z",
+		"apple": "a",
+		"mango": map[string]interface{}{
+			"yak": "y",
+			"bee": "b",
+		},
+	}
+	require.Equal(t, expected, testOrderedMap().Map())
+}
+
+func TestPlainValue(t *testing.T) {
+	plainMap := map[string]interface{}{"key": "value"}
+	require.Equal(t, plainMap, PlainValue(plainMap))
+
+	value := map[string]interface{}{
+		"ordered": testOrderedMap(),
+		"array":   []interface{}{"a", testOrderedMap()},
+		"string":  "value",
+	}
+	expectedOrdered := testOrderedMap().Map()
+	expected := map[string]interface{}{
+		"ordered": expectedOrdered,
+		"array":   []interface{}{"a", expectedOrdered},
+		"string":  "value",
+	}
+	require.Equal(t, expected, PlainValue(value))
+
+	// The original value should not be modified
+	require.IsType(t, &OrderedMap{}, value["ordered"])
+}
+
+func TestOrderedMapMarshalJSON(t *testing.T) {
+	m := testOrderedMap()
+	m.Set("array", []interface{}{1, "two", NewOrderedMap()})
+
+	marshalled, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.Equal(t, `{"zebra":"z","apple":"a","mango":{"yak":"y","bee":"b"},"array":[1,"two",{}]}`, string(marshalled))
+}
+
+func TestOrderedMapUnmarshalJSON(t *testing.T) {
+	raw := `{"zebra":"z","apple":"a","mango":{"yak":"y","bee":"b"},"array":[1,"two",{"c":true},null]}`
+
+	m := NewOrderedMap()
+	err := json.Unmarshal([]byte(raw), m)
+	require.NoError(t, err)
+
+	expected := testOrderedMap()
+	c := NewOrderedMap()
+	c.Set("c", true)
+	expected.Set("array", []interface{}{float64(1), "two", c, nil})
+	require.Equal(t, expected, m)
+
+	marshalled, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.Equal(t, raw, string(marshalled))
+}
+
+func TestOrderedMapUnmarshalJSONFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Array", `["a"]`, "expected a JSON object"},
+		{"String", `"a"`, "expected a JSON object"},
+		{"Unclosed", `{"a":"b"`, ""},
+		{"TrailingData", `{"a":"b"} {}`, "unexpected data after JSON object"},
+		{"Invalid", `{"a":}`, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewOrderedMap()
+			err := m.UnmarshalJSON([]byte(tc.input))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestOrderedMapMarshalYAML(t *testing.T) {
+	marshalled, err := yaml.Marshal(testOrderedMap())
+	require.NoError(t, err)
+	require.Equal(t, "zebra: z\napple: a\nmango:\n  yak: \"y\"\n  bee: b\n", string(marshalled))
+}
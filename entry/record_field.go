@@ -3,6 +3,7 @@ package entry
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -42,118 +43,307 @@ func (f RecordField) String() string {
 
 // Get will retrieve a value from an entry's record using the field.
 // It will return the value and whether the field existed.
+// A map or array is taken from the entry's own copy of a shared record,
+// so that it can be modified in place.
 func (f RecordField) Get(entry *Entry) (interface{}, bool) {
-	var currentValue interface{} = entry.Record
-
-	for _, key := range f.Keys {
-		currentRecord, ok := currentValue.(map[string]interface{})
-		if !ok {
-			return nil, false
-		}
-
-		currentValue, ok = currentRecord[key]
-		if !ok {
-			return nil, false
-		}
+	value, ok := getValue(entry.Record, f.Keys)
+	if ok && entry.recordRef != nil && isContainer(value) {
+		entry.ownRecord()
+		return getValue(entry.Record, f.Keys)
 	}
-
-	return currentValue, true
+	return value, ok
 }
 
 // Set will set a value on an entry's record using the field.
 // If a key already exists, it will be overwritten.
 // If mergeMaps is set to true, map values will be merged together.
 func (f RecordField) Set(entry *Entry, value interface{}) error {
-	mapValue, isMapValue := value.(map[string]interface{})
-	if isMapValue {
-		f.Merge(entry, mapValue)
-		return nil
-	}
-
-	if f.isRoot() {
-		entry.Record = value
-		return nil
-	}
+	entry.ownRecord()
 
-	currentMap, ok := entry.Record.(map[string]interface{})
-	if !ok {
-		currentMap = map[string]interface{}{}
-		entry.Record = currentMap
+	switch value.(type) {
+	case map[string]interface{}, *OrderedMap:
+		return f.merge(entry, value)
 	}
 
-	for i, key := range f.Keys {
-		if i == len(f.Keys)-1 {
-			currentMap[key] = value
-			break
-		}
-		currentMap = f.getNestedMap(currentMap, key)
+	record, err := setValue(entry.Record, f.Keys, func(interface{}) interface{} {
+		return value
+	})
+	if err != nil {
+		return err
 	}
+	entry.Record = record
 	return nil
 }
 
 // Merge will attempt to merge the contents of a map into an entry's record.
 // It will overwrite any intermediate values as necessary.
 func (f RecordField) Merge(entry *Entry, mapValues map[string]interface{}) {
-	currentMap, ok := entry.Record.(map[string]interface{})
-	if !ok {
-		currentMap = map[string]interface{}{}
-		entry.Record = currentMap
-	}
+	_ = f.merge(entry, mapValues)
+}
 
-	for _, key := range f.Keys {
-		currentMap = f.getNestedMap(currentMap, key)
-	}
+// merge will merge the contents of a map or ordered map into an entry's record,
+// returning an error if an array index in the field is out of range.
+// If the current value is not a map, it is replaced with a map of the same kind as the values.
+func (f RecordField) merge(entry *Entry, mapValues interface{}) error {
+	entry.ownRecord()
+
+	record, err := setValue(entry.Record, f.Keys, func(current interface{}) interface{} {
+		switch currentMap := current.(type) {
+		case map[string]interface{}:
+			rangeMap(mapValues, func(key string, value interface{}) {
+				currentMap[key] = value
+			})
+			return currentMap
+		case *OrderedMap:
+			rangeMap(mapValues, currentMap.Set)
+			return currentMap
+		}
 
-	for key, value := range mapValues {
-		currentMap[key] = value
+		if _, ok := mapValues.(*OrderedMap); ok {
+			newMap := NewOrderedMap()
+			rangeMap(mapValues, newMap.Set)
+			return newMap
+		}
+
+		newMap := map[string]interface{}{}
+		rangeMap(mapValues, func(key string, value interface{}) {
+			newMap[key] = value
+		})
+		return newMap
+	})
+	if err != nil {
+		return err
 	}
+	entry.Record = record
+	return nil
 }
 
 // Delete removes a value from an entry's record using the field.
 // It will return the deleted value and whether the field existed.
 func (f RecordField) Delete(entry *Entry) (interface{}, bool) {
+	entry.ownRecord()
+
 	if f.isRoot() {
 		oldRecord := entry.Record
 		entry.Record = nil
 		return oldRecord, true
 	}
 
-	currentValue := entry.Record
-	for i, key := range f.Keys {
-		currentMap, ok := currentValue.(map[string]interface{})
+	record, deleted, ok := deleteValue(entry.Record, f.Keys)
+	if ok {
+		entry.Record = record
+	}
+	return deleted, ok
+}
+
+// getValue will walk the keys from the current value and return the value found.
+// A wildcard key will return an array of the values found in each element of an array.
+func getValue(current interface{}, keys []string) (interface{}, bool) {
+	if len(keys) == 0 {
+		return current, true
+	}
+
+	key := keys[0]
+	switch typed := current.(type) {
+	case map[string]interface{}:
+		next, ok := typed[key]
+		if !ok {
+			return nil, false
+		}
+		return getValue(next, keys[1:])
+	case *OrderedMap:
+		next, ok := typed.Get(key)
+		if !ok {
+			return nil, false
+		}
+		return getValue(next, keys[1:])
+	case []interface{}:
+		if key == wildcardKey {
+			results := make([]interface{}, 0, len(typed))
+			for _, elem := range typed {
+				if result, ok := getValue(elem, keys[1:]); ok {
+					results = append(results, result)
+				}
+			}
+			return results, len(results) > 0
+		}
+
+		index, ok := arrayIndex(key, len(typed))
 		if !ok {
-			break
+			return nil, false
+		}
+		return getValue(typed[index], keys[1:])
+	default:
+		return nil, false
+	}
+}
+
+// setValue will walk the keys from the current value and replace the value found
+// with the result of the set function. Missing or non-map intermediate values
+// are replaced with maps, while ordered maps are kept. Index and wildcard keys must
+// select elements of an existing array, except that a numeric key of a map is used as
+// a map key, as when getting a value. It returns the updated current value.
+func setValue(current interface{}, keys []string, set func(interface{}) interface{}) (interface{}, error) {
+	if len(keys) == 0 {
+		return set(current), nil
+	}
+
+	key := keys[0]
+	if array, ok := current.([]interface{}); ok && isIndexKey(key) {
+		if key == wildcardKey {
+			for i, elem := range array {
+				newElem, err := setValue(elem, keys[1:], set)
+				if err != nil {
+					return nil, err
+				}
+				array[i] = newElem
+			}
+			return array, nil
 		}
 
-		currentValue, ok = currentMap[key]
+		index, ok := arrayIndex(key, len(array))
 		if !ok {
-			break
+			return nil, fmt.Errorf("array index %s is out of range for array of length %d", key, len(array))
+		}
+		newElem, err := setValue(array[index], keys[1:], set)
+		if err != nil {
+			return nil, err
 		}
+		array[index] = newElem
+		return array, nil
+	}
 
-		if i == len(f.Keys)-1 {
-			delete(currentMap, key)
-			return currentValue, true
+	_, isMap := current.(map[string]interface{})
+	_, isOrderedMap := current.(*OrderedMap)
+	if key == wildcardKey || (isIndexKey(key) && !isMap && !isOrderedMap) {
+		return nil, fmt.Errorf("array index %s does not select an element, as the value is not an array", key)
+	}
+
+	if orderedMap, ok := current.(*OrderedMap); ok {
+		next, _ := orderedMap.Get(key)
+		newValue, err := setValue(next, keys[1:], set)
+		if err != nil {
+			return nil, err
 		}
+		orderedMap.Set(key, newValue)
+		return orderedMap, nil
+	}
+
+	currentMap, ok := current.(map[string]interface{})
+	if !ok {
+		currentMap = map[string]interface{}{}
 	}
 
-	return nil, false
+	newValue, err := setValue(currentMap[key], keys[1:], set)
+	if err != nil {
+		return nil, err
+	}
+	currentMap[key] = newValue
+	return currentMap, nil
 }
 
-// getNestedMap will get a nested map assigned to a key.
-// If the map does not exist, it will create and return it.
-func (f RecordField) getNestedMap(currentMap map[string]interface{}, key string) map[string]interface{} {
-	currentValue, ok := currentMap[key]
-	if !ok {
-		currentMap[key] = map[string]interface{}{}
+// deleteValue will walk the keys from the current value and remove the value found.
+// It returns the updated current value, the deleted value, and whether the keys existed.
+// Deleting with a wildcard returns an array of the values deleted from each element.
+func deleteValue(current interface{}, keys []string) (interface{}, interface{}, bool) {
+	key := keys[0]
+	last := len(keys) == 1
+
+	switch typed := current.(type) {
+	case map[string]interface{}:
+		next, ok := typed[key]
+		if !ok {
+			return current, nil, false
+		}
+		if last {
+			delete(typed, key)
+			return typed, next, true
+		}
+
+		newNext, deleted, ok := deleteValue(next, keys[1:])
+		if ok {
+			typed[key] = newNext
+		}
+		return typed, deleted, ok
+	case *OrderedMap:
+		next, ok := typed.Get(key)
+		if !ok {
+			return current, nil, false
+		}
+		if last {
+			typed.Delete(key)
+			return typed, next, true
+		}
+
+		newNext, deleted, ok := deleteValue(next, keys[1:])
+		if ok {
+			typed.Set(key, newNext)
+		}
+		return typed, deleted, ok
+	case []interface{}:
+		if key == wildcardKey {
+			if last {
+				return []interface{}{}, typed, true
+			}
+
+			deleted := make([]interface{}, 0, len(typed))
+			for i, elem := range typed {
+				newElem, deletedElem, ok := deleteValue(elem, keys[1:])
+				if ok {
+					typed[i] = newElem
+					deleted = append(deleted, deletedElem)
+				}
+			}
+			return typed, deleted, len(deleted) > 0
+		}
+
+		index, ok := arrayIndex(key, len(typed))
+		if !ok {
+			return current, nil, false
+		}
+		if last {
+			newArray := make([]interface{}, 0, len(typed)-1)
+			newArray = append(newArray, typed[:index]...)
+			newArray = append(newArray, typed[index+1:]...)
+			return newArray, typed[index], true
+		}
+
+		newElem, deleted, ok := deleteValue(typed[index], keys[1:])
+		if ok {
+			typed[index] = newElem
+		}
+		return typed, deleted, ok
+	default:
+		return current, nil, false
 	}
+}
 
-	nextMap, ok := currentValue.(map[string]interface{})
-	if !ok {
-		nextMap = map[string]interface{}{}
-		currentMap[key] = nextMap
+// wildcardKey is a key that selects every element of an array.
+const wildcardKey = "*"
+
+// isIndexKey returns a boolean indicating if a key can be used to select array elements.
+func isIndexKey(key string) bool {
+	if key == wildcardKey {
+		return true
 	}
+	_, err := strconv.Atoi(key)
+	return err == nil
+}
 
-	return nextMap
+// arrayIndex converts a key to an index of an array with the given length.
+// Negative indices count back from the end of the array.
+func arrayIndex(key string, length int) (int, bool) {
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, false
+	}
+	if index < 0 {
+		index += length
+	}
+	if index < 0 || index >= length {
+		return 0, false
+	}
+	return index, true
 }
 
 /****************
@@ -195,7 +385,10 @@ func (f RecordField) MarshalYAML() (interface{}, error) {
 
 // fromJSONDot creates a field from JSON dot notation.
 func fromJSONDot(value string) RecordField {
-	keys := strings.Split(value, ".")
+	keys, err := splitField(value)
+	if err != nil {
+		keys = strings.Split(value, ".")
+	}
 
 	if keys[0] == "$" || keys[0] == recordPrefix {
 		keys = keys[1:]
@@ -227,6 +420,12 @@ func toJSONDot(field RecordField) string {
 		}
 	} else {
 		for i, key := range field.Keys {
+			if isIndexKey(key) {
+				b.WriteString("[")
+				b.WriteString(key)
+				b.WriteString("]")
+				continue
+			}
 			if i != 0 {
 				b.WriteString(".")
 			}
@@ -22,6 +22,16 @@ func nestedMap() map[string]interface{} {
 	}
 }
 
+func testArrayRecord() map[string]interface{} {
+	return map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "first"},
+			map[string]interface{}{"name": "second"},
+			"raw_item",
+		},
+	}
+}
+
 func TestRecordFieldGet(t *testing.T) {
 	cases := []struct {
 		name        string
@@ -79,6 +89,48 @@ func TestRecordFieldGet(t *testing.T) {
 			"raw string",
 			true,
 		},
+		{
+			"ArrayIndex",
+			NewRecordField("items", "1", "name"),
+			testArrayRecord(),
+			"second",
+			true,
+		},
+		{
+			"NegativeArrayIndex",
+			NewRecordField("items", "-1"),
+			testArrayRecord(),
+			"raw_item",
+			true,
+		},
+		{
+			"ArrayIndexOutOfRange",
+			NewRecordField("items", "3"),
+			testArrayRecord(),
+			nil,
+			false,
+		},
+		{
+			"ArrayNonIndexKey",
+			NewRecordField("items", "name"),
+			testArrayRecord(),
+			nil,
+			false,
+		},
+		{
+			"ArrayWildcard",
+			NewRecordField("items", "*", "name"),
+			testArrayRecord(),
+			[]interface{}{"first", "second"},
+			true,
+		},
+		{
+			"ArrayWildcardNoMatches",
+			NewRecordField("items", "*", "missing"),
+			testArrayRecord(),
+			[]interface{}{},
+			false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -169,6 +221,65 @@ func TestRecordFieldDelete(t *testing.T) {
 			nil,
 			false,
 		},
+		{
+			"ArrayElement",
+			NewRecordField("items", "0"),
+			testArrayRecord(),
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "second"},
+					"raw_item",
+				},
+			},
+			map[string]interface{}{"name": "first"},
+			true,
+		},
+		{
+			"ArrayElementField",
+			NewRecordField("items", "-2", "name"),
+			testArrayRecord(),
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "first"},
+					map[string]interface{}{},
+					"raw_item",
+				},
+			},
+			"second",
+			true,
+		},
+		{
+			"ArrayIndexOutOfRange",
+			NewRecordField("items", "5"),
+			testArrayRecord(),
+			testArrayRecord(),
+			nil,
+			false,
+		},
+		{
+			"ArrayWildcard",
+			NewRecordField("items", "*"),
+			testArrayRecord(),
+			map[string]interface{}{
+				"items": []interface{}{},
+			},
+			testArrayRecord()["items"],
+			true,
+		},
+		{
+			"ArrayWildcardField",
+			NewRecordField("items", "*", "name"),
+			testArrayRecord(),
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{},
+					map[string]interface{}{},
+					"raw_item",
+				},
+			},
+			[]interface{}{"first", "second"},
+			true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -176,7 +287,9 @@ func TestRecordFieldDelete(t *testing.T) {
 			entry := New()
 			entry.Record = tc.record
 
-			entry.Delete(tc.field)
+			val, ok := entry.Delete(tc.field)
+			require.Equal(t, tc.expectedOk, ok)
+			require.Equal(t, tc.expectedReturned, val)
 			assert.Equal(t, tc.expectedRecord, entry.Record)
 		})
 	}
@@ -261,6 +374,69 @@ func TestRecordFieldSet(t *testing.T) {
 				},
 			},
 		},
+		{
+			"ArrayElement",
+			NewRecordField("items", "2"),
+			testArrayRecord(),
+			"new_value",
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "first"},
+					map[string]interface{}{"name": "second"},
+					"new_value",
+				},
+			},
+		},
+		{
+			"ArrayElementField",
+			NewRecordField("items", "-3", "name"),
+			testArrayRecord(),
+			"new_value",
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "new_value"},
+					map[string]interface{}{"name": "second"},
+					"raw_item",
+				},
+			},
+		},
+		{
+			"ArrayWildcardField",
+			NewRecordField("items", "*", "tag"),
+			testArrayRecord(),
+			"new_value",
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "first", "tag": "new_value"},
+					map[string]interface{}{"name": "second", "tag": "new_value"},
+					map[string]interface{}{"tag": "new_value"},
+				},
+			},
+		},
+		{
+			"ArrayMergedValue",
+			NewRecordField("items", "0"),
+			testArrayRecord(),
+			map[string]interface{}{
+				"merged_key": "merged_value",
+			},
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "first", "merged_key": "merged_value"},
+					map[string]interface{}{"name": "second"},
+					"raw_item",
+				},
+			},
+		},
+		{
+			"NonIndexKeyOverwritesArray",
+			NewRecordField("items", "name"),
+			testArrayRecord(),
+			"new_value",
+			map[string]interface{}{
+				"items": map[string]interface{}{"name": "new_value"},
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -273,6 +449,84 @@ func TestRecordFieldSet(t *testing.T) {
 	}
 }
 
+func TestRecordFieldSetIndexOutOfRange(t *testing.T) {
+	entry := New()
+	entry.Record = testArrayRecord()
+
+	err := entry.Set(NewRecordField("items", "3"), "new_value")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "out of range")
+	require.Equal(t, testArrayRecord(), entry.Record)
+}
+
+func TestRecordFieldSetIndexNotArray(t *testing.T) {
+	cases := []struct {
+		name   string
+		field  Field
+		record interface{}
+		setTo  interface{}
+	}{
+		{
+			"MissingIndex",
+			NewRecordField("items", "0"),
+			map[string]interface{}{},
+			"new_value",
+		},
+		{
+			"MissingWildcard",
+			NewRecordField("items", "*"),
+			map[string]interface{}{},
+			"new_value",
+		},
+		{
+			"StringIndex",
+			NewRecordField("a", "0"),
+			map[string]interface{}{"a": "str"},
+			"new_value",
+		},
+		{
+			"MapWildcard",
+			NewRecordField("a", "*"),
+			map[string]interface{}{"a": map[string]interface{}{"b": "c"}},
+			"new_value",
+		},
+		{
+			"ElementIndex",
+			NewRecordField("items", "2", "0"),
+			testArrayRecord(),
+			"new_value",
+		},
+		{
+			"MissingIndexMergedValue",
+			NewRecordField("items", "0"),
+			map[string]interface{}{},
+			map[string]interface{}{"merged_key": "merged_value"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := New()
+			entry.Record = tc.record
+
+			err := entry.Set(tc.field, tc.setTo)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "not an array")
+		})
+	}
+}
+
+func TestRecordFieldSetNumericMapKey(t *testing.T) {
+	entry := New()
+	entry.Record = map[string]interface{}{"codes": map[string]interface{}{"200": "ok"}}
+
+	require.NoError(t, entry.Set(NewRecordField("codes", "404"), "not found"))
+	expected := map[string]interface{}{
+		"codes": map[string]interface{}{"200": "ok", "404": "not found"},
+	}
+	require.Equal(t, expected, entry.Record)
+}
+
 func TestRecordFieldParent(t *testing.T) {
 	t.Run("Simple", func(t *testing.T) {
 		field := RecordField{[]string{"child"}}
@@ -352,3 +606,51 @@ func TestRecordFieldFromJSONDot(t *testing.T) {
 	expectedField := RecordField{Keys: []string{"test"}}
 	require.Equal(t, expectedField, recordField)
 }
+
+func TestRecordFieldFromJSONDotWithIndex(t *testing.T) {
+	jsonDot := "$record.items[0].name"
+	recordField := fromJSONDot(jsonDot)
+	expectedField := RecordField{Keys: []string{"items", "0", "name"}}
+	require.Equal(t, expectedField, recordField)
+}
+
+func TestRecordFieldOrderedMap(t *testing.T) {
+	entry := New()
+	entry.Record = testOrderedMap()
+
+	value, ok := entry.Get(NewRecordField("mango", "yak"))
+	require.True(t, ok)
+	require.Equal(t, "y", value)
+
+	err := entry.Set(NewRecordField("mango", "ant"), "a")
+	require.NoError(t, err)
+	err = entry.Set(NewRecordField("cherry", "pit"), "p")
+	require.NoError(t, err)
+
+	value, ok = entry.Delete(NewRecordField("apple"))
+	require.True(t, ok)
+	require.Equal(t, "a", value)
+
+	marshalled, err := json.Marshal(entry.Record)
+	require.NoError(t, err)
+	require.Equal(t, `{"zebra":"z","mango":{"yak":"y","bee":"b","ant":"a"},"cherry":{"pit":"p"}}`, string(marshalled))
+}
+
+func TestRecordFieldSetOrderedMap(t *testing.T) {
+	entry := New()
+	entry.Record = "unparsed"
+
+	err := entry.Set(NewRecordField(), testOrderedMap())
+	require.NoError(t, err)
+	require.Equal(t, testOrderedMap(), entry.Record)
+
+	values := NewOrderedMap()
+	values.Set("yak", "new")
+	values.Set("ant", "a")
+	err = entry.Set(NewRecordField("mango"), values)
+	require.NoError(t, err)
+
+	marshalled, err := json.Marshal(entry.Record)
+	require.NoError(t, err)
+	require.Equal(t, `{"zebra":"z","apple":"a","mango":{"yak":"new","bee":"b","ant":"a"}}`, string(marshalled))
+}
@@ -21,6 +21,7 @@ func (r ResourceField) Get(entry *Entry) (interface{}, bool) {
 
 // Set will set the resource value on an entry
 func (r ResourceField) Set(entry *Entry, val interface{}) error {
+	entry.ownResource()
 	if entry.Resource == nil {
 		entry.Resource = make(map[string]string, 1)
 	}
@@ -35,6 +36,7 @@ func (r ResourceField) Set(entry *Entry, val interface{}) error {
 
 // Delete will delete a resource key from an entry
 func (r ResourceField) Delete(entry *Entry) (interface{}, bool) {
+	entry.ownResource()
 	if entry.Resource == nil {
 		return "", false
 	}
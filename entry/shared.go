@@ -0,0 +1,120 @@
+package entry
+
+import "sync/atomic"
+
+// sharedRef counts the entries that share a value after an entry was shared.
+// A shared value is only copied when one of the entries sharing it needs to modify it.
+type sharedRef struct {
+	count int64
+}
+
+// share will add a holder to a shared value, creating the reference if the value was not
+// shared yet. A new reference is held by both the original entry and the entry sharing it.
+func share(ref *sharedRef) *sharedRef {
+	if ref == nil {
+		return &sharedRef{count: 2}
+	}
+	atomic.AddInt64(&ref.count, 1)
+	return ref
+}
+
+// owned returns true if the value is held by a single entry, which can then modify it in place.
+func (r *sharedRef) owned() bool {
+	return r == nil || atomic.LoadInt64(&r.count) == 1
+}
+
+// release will remove a holder from a shared value. It must only be called after
+// the holder has finished reading the value.
+func (r *sharedRef) release() {
+	if r != nil {
+		atomic.AddInt64(&r.count, -1)
+	}
+}
+
+// Share will return a copy of the entry that shares its labels, resource and record with it.
+// The shared values are copied by the first entry that modifies them through a field,
+// Set, Delete, AddLabel or AddResourceKey, or reads a map or array from the record with Get.
+// The entry is marked as sharing its values, so it must not be in use by another goroutine.
+func (entry *Entry) Share() *Entry {
+	entry.labelsRef = share(entry.labelsRef)
+	entry.resourceRef = share(entry.resourceRef)
+	entry.recordRef = share(entry.recordRef)
+
+	return &Entry{
+		Timestamp:         entry.Timestamp,
+		ObservedTimestamp: entry.ObservedTimestamp,
+		Severity:          entry.Severity,
+		SeverityText:      entry.SeverityText,
+		TraceID:           copyByteArray(entry.TraceID),
+		SpanID:            copyByteArray(entry.SpanID),
+		TraceFlags:        copyByteArray(entry.TraceFlags),
+		Labels:            entry.Labels,
+		Resource:          entry.Resource,
+		Record:            entry.Record,
+		labelsRef:         entry.labelsRef,
+		resourceRef:       entry.resourceRef,
+		recordRef:         entry.recordRef,
+	}
+}
+
+// Release will stop the entry from sharing its values, so that the other entries sharing
+// them can modify them without copying. It must only be called once the entry is no longer used.
+func (entry *Entry) Release() {
+	entry.labelsRef.release()
+	entry.resourceRef.release()
+	entry.recordRef.release()
+	entry.labelsRef, entry.resourceRef, entry.recordRef = nil, nil, nil
+}
+
+// ownRecord will ensure that the record of the entry is not shared with any other
+// entry, copying it if necessary, so that it can be modified in place.
+func (entry *Entry) ownRecord() {
+	if entry.recordRef == nil {
+		return
+	}
+	if !entry.recordRef.owned() {
+		entry.Record = copyValue(entry.Record)
+		entry.recordRef.release()
+	}
+	entry.recordRef = nil
+}
+
+// ownLabels will ensure that the labels of the entry are not shared with any other
+// entry, copying them if necessary, so that they can be modified in place.
+func (entry *Entry) ownLabels() {
+	if entry.labelsRef == nil {
+		return
+	}
+	if !entry.labelsRef.owned() {
+		if entry.Labels != nil {
+			entry.Labels = copyStringMap(entry.Labels)
+		}
+		entry.labelsRef.release()
+	}
+	entry.labelsRef = nil
+}
+
+// ownResource will ensure that the resource of the entry is not shared with any other
+// entry, copying it if necessary, so that it can be modified in place.
+func (entry *Entry) ownResource() {
+	if entry.resourceRef == nil {
+		return
+	}
+	if !entry.resourceRef.owned() {
+		if entry.Resource != nil {
+			entry.Resource = copyStringMap(entry.Resource)
+		}
+		entry.resourceRef.release()
+	}
+	entry.resourceRef = nil
+}
+
+// isContainer returns true if a value can be modified in place by whoever reads it.
+func isContainer(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, *OrderedMap, []interface{}, map[string]string, []string, []byte, []int:
+		return true
+	default:
+		return false
+	}
+}
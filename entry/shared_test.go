@@ -0,0 +1,193 @@
+package entry
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSharedEntry() *Entry {
+	entry := New()
+	entry.Labels = map[string]string{"label": "value"}
+	entry.Resource = map[string]string{"resource": "value"}
+	entry.Record = map[string]interface{}{
+		"message": "test",
+		"nested":  map[string]interface{}{"key": "value"},
+		"items":   []interface{}{"a", "b"},
+	}
+	return entry
+}
+
+// sameValue returns true if two maps or arrays are the same value in memory
+func sameValue(a, b interface{}) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+func TestShare(t *testing.T) {
+	entry := testSharedEntry()
+	shared := entry.Share()
+
+	require.NotSame(t, entry, shared)
+	require.True(t, sameValue(entry.Record, shared.Record))
+	require.True(t, sameValue(entry.Labels, shared.Labels))
+	require.True(t, sameValue(entry.Resource, shared.Resource))
+	require.Equal(t, int64(2), entry.recordRef.count)
+	require.Same(t, entry.recordRef, shared.recordRef)
+
+	// Sharing again adds a holder to the same reference
+	again := shared.Share()
+	require.Same(t, entry.recordRef, again.recordRef)
+	require.Equal(t, int64(3), entry.recordRef.count)
+}
+
+func TestShareModify(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(*Entry)
+	}{
+		{"SetRecord", func(e *Entry) { require.NoError(t, e.Set(NewRecordField("nested", "key"), "new")) }},
+		{"MergeRecord", func(e *Entry) { e.Set(NewRecordField("nested"), map[string]interface{}{"key": "new"}) }},
+		{"DeleteRecord", func(e *Entry) { e.Delete(NewRecordField("nested", "key")) }},
+		{"GetNestedMap", func(e *Entry) {
+			nested, _ := e.Get(NewRecordField("nested"))
+			nested.(map[string]interface{})["key"] = "new"
+		}},
+		{"GetArray", func(e *Entry) {
+			items, _ := e.Get(NewRecordField("items"))
+			items.([]interface{})[0] = "new"
+		}},
+		{"GetRecord", func(e *Entry) {
+			record, _ := e.Get(NewRecordField())
+			delete(record.(map[string]interface{}), "message")
+		}},
+		{"SetLabel", func(e *Entry) { require.NoError(t, e.Set(NewLabelField("label"), "new")) }},
+		{"DeleteLabel", func(e *Entry) { e.Delete(NewLabelField("label")) }},
+		{"AddLabel", func(e *Entry) { e.AddLabel("label", "new") }},
+		{"SetResource", func(e *Entry) { require.NoError(t, e.Set(NewResourceField("resource"), "new")) }},
+		{"DeleteResource", func(e *Entry) { e.Delete(NewResourceField("resource")) }},
+		{"AddResourceKey", func(e *Entry) { e.AddResourceKey("resource", "new") }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := testSharedEntry()
+			shared := entry.Share()
+
+			tc.modify(shared)
+			require.Equal(t, testSharedEntry().Record, entry.Record)
+			require.Equal(t, testSharedEntry().Labels, entry.Labels)
+			require.Equal(t, testSharedEntry().Resource, entry.Resource)
+
+			// The other entry is now the only holder, so it modifies its values in place
+			record, labels, resource := entry.Record, entry.Labels, entry.Resource
+			tc.modify(entry)
+			require.True(t, sameValue(record, entry.Record))
+			require.True(t, sameValue(labels, entry.Labels))
+			require.True(t, sameValue(resource, entry.Resource))
+		})
+	}
+}
+
+func TestShareGetValueDoesNotCopy(t *testing.T) {
+	entry := testSharedEntry()
+	shared := entry.Share()
+
+	value, ok := shared.Get(NewRecordField("message"))
+	require.True(t, ok)
+	require.Equal(t, "test", value)
+	value, ok = shared.Get(NewLabelField("label"))
+	require.True(t, ok)
+	require.Equal(t, "value", value)
+
+	require.True(t, sameValue(entry.Record, shared.Record))
+	require.NotNil(t, shared.recordRef)
+}
+
+func TestShareOrderedMap(t *testing.T) {
+	entry := New()
+	entry.Record = testOrderedMap()
+	shared := entry.Share()
+
+	require.NoError(t, shared.Set(NewRecordField("mango", "yak"), "new"))
+	require.Equal(t, testOrderedMap(), entry.Record)
+}
+
+func TestShareNilLabels(t *testing.T) {
+	entry := New()
+	shared := entry.Share()
+
+	shared.AddLabel("label", "value")
+	require.Nil(t, entry.Labels)
+	require.Equal(t, map[string]string{"label": "value"}, shared.Labels)
+}
+
+func TestShareRelease(t *testing.T) {
+	entry := testSharedEntry()
+	shared := entry.Share()
+	shared.Release()
+	require.Nil(t, shared.recordRef)
+
+	// The released entry no longer holds the values, so the other entry modifies them in place
+	record := entry.Record
+	require.NoError(t, entry.Set(NewRecordField("message"), "new"))
+	require.True(t, sameValue(record, entry.Record))
+	require.Nil(t, entry.recordRef)
+
+	// Releasing an entry that is not shared does nothing
+	New().Release()
+}
+
+func TestShareCopy(t *testing.T) {
+	entry := testSharedEntry()
+	shared := entry.Share()
+
+	// Copying a shared entry does not change the entries sharing its values
+	copied := shared.Copy()
+	require.Nil(t, copied.recordRef)
+	require.Equal(t, int64(2), entry.recordRef.count)
+	require.False(t, sameValue(copied.Record, shared.Record))
+}
+
+func TestShareConcurrentModify(t *testing.T) {
+	entry := testSharedEntry()
+	entries := []*Entry{entry}
+	for i := 0; i < 7; i++ {
+		entries = append(entries, entry.Share())
+	}
+
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *Entry) {
+			defer wg.Done()
+			if i%2 == 0 {
+				_, _ = e.Get(NewRecordField("message"))
+				return
+			}
+			nested, _ := e.Get(NewRecordField("nested"))
+			nested.(map[string]interface{})["key"] = i
+		}(i, e)
+	}
+	wg.Wait()
+
+	for i, e := range entries {
+		value, ok := e.Get(NewRecordField("nested", "key"))
+		require.True(t, ok)
+		if i%2 == 0 {
+			require.Equal(t, "value", value)
+			continue
+		}
+		require.Equal(t, i, value)
+	}
+}
+
+func BenchmarkShare(b *testing.B) {
+	entry := benchmarkEntry()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = entry.Share()
+	}
+}
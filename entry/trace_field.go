@@ -0,0 +1,156 @@
+package entry
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	traceIDPrefix    = "$trace_id"
+	spanIDPrefix     = "$span_id"
+	traceFlagsPrefix = "$trace_flags"
+)
+
+// TraceIDField is the path to an entry's trace ID
+type TraceIDField struct{}
+
+// Get will return the trace ID as a hex string and a boolean indicating if it exists
+func (f TraceIDField) Get(entry *Entry) (interface{}, bool) {
+	return getTraceValue(entry.TraceID)
+}
+
+// Set will set the trace ID of an entry from a hex string
+func (f TraceIDField) Set(entry *Entry, value interface{}) error {
+	traceID, err := toTraceValue(value, 16)
+	if err != nil {
+		return fmt.Errorf("set trace id: %s", err)
+	}
+	entry.TraceID = traceID
+	return nil
+}
+
+// Delete will remove the trace ID from an entry
+func (f TraceIDField) Delete(entry *Entry) (interface{}, bool) {
+	val, ok := f.Get(entry)
+	entry.TraceID = nil
+	return val, ok
+}
+
+func (f TraceIDField) String() string {
+	return traceIDPrefix
+}
+
+// NewTraceIDField will create a new trace ID field
+func NewTraceIDField() Field {
+	return Field{TraceIDField{}}
+}
+
+// SpanIDField is the path to an entry's span ID
+type SpanIDField struct{}
+
+// Get will return the span ID as a hex string and a boolean indicating if it exists
+func (f SpanIDField) Get(entry *Entry) (interface{}, bool) {
+	return getTraceValue(entry.SpanID)
+}
+
+// Set will set the span ID of an entry from a hex string
+func (f SpanIDField) Set(entry *Entry, value interface{}) error {
+	spanID, err := toTraceValue(value, 8)
+	if err != nil {
+		return fmt.Errorf("set span id: %s", err)
+	}
+	entry.SpanID = spanID
+	return nil
+}
+
+// Delete will remove the span ID from an entry
+func (f SpanIDField) Delete(entry *Entry) (interface{}, bool) {
+	val, ok := f.Get(entry)
+	entry.SpanID = nil
+	return val, ok
+}
+
+func (f SpanIDField) String() string {
+	return spanIDPrefix
+}
+
+// NewSpanIDField will create a new span ID field
+func NewSpanIDField() Field {
+	return Field{SpanIDField{}}
+}
+
+// TraceFlagsField is the path to an entry's trace flags
+type TraceFlagsField struct{}
+
+// Get will return the trace flags as a hex string and a boolean indicating if they exist
+func (f TraceFlagsField) Get(entry *Entry) (interface{}, bool) {
+	return getTraceValue(entry.TraceFlags)
+}
+
+// Set will set the trace flags of an entry from a hex string
+func (f TraceFlagsField) Set(entry *Entry, value interface{}) error {
+	traceFlags, err := toTraceValue(value, 1)
+	if err != nil {
+		return fmt.Errorf("set trace flags: %s", err)
+	}
+	entry.TraceFlags = traceFlags
+	return nil
+}
+
+// Delete will remove the trace flags from an entry
+func (f TraceFlagsField) Delete(entry *Entry) (interface{}, bool) {
+	val, ok := f.Get(entry)
+	entry.TraceFlags = nil
+	return val, ok
+}
+
+func (f TraceFlagsField) String() string {
+	return traceFlagsPrefix
+}
+
+// NewTraceFlagsField will create a new trace flags field
+func NewTraceFlagsField() Field {
+	return Field{TraceFlagsField{}}
+}
+
+// traceFieldFromPrefix will create the trace field matching a field prefix.
+func traceFieldFromPrefix(prefix string) Field {
+	switch prefix {
+	case spanIDPrefix:
+		return NewSpanIDField()
+	case traceFlagsPrefix:
+		return NewTraceFlagsField()
+	default:
+		return NewTraceIDField()
+	}
+}
+
+// getTraceValue will return a trace value as a hex string, if it is set.
+func getTraceValue(value []byte) (interface{}, bool) {
+	if len(value) == 0 {
+		return "", false
+	}
+	return hex.EncodeToString(value), true
+}
+
+// toTraceValue will decode a hex string to a trace value of the expected length in bytes.
+func toTraceValue(value interface{}, length int) ([]byte, error) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return nil, fmt.Errorf("type '%T' can not be used as a trace value", value)
+	}
+
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid hex string", s)
+	}
+	if len(decoded) != length {
+		return nil, fmt.Errorf("expected %d bytes, but found %d", length, len(decoded))
+	}
+	return decoded, nil
+}
@@ -0,0 +1,103 @@
+package entry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceIDField(t *testing.T) {
+	entry := New()
+	field := NewTraceIDField()
+
+	_, ok := entry.Get(field)
+	require.False(t, ok)
+
+	err := entry.Set(field, "0af7651916cd43dd8448eb211c80319c")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c}, entry.TraceID)
+
+	val, ok := entry.Get(field)
+	require.True(t, ok)
+	require.Equal(t, "0af7651916cd43dd8448eb211c80319c", val)
+
+	val, ok = entry.Delete(field)
+	require.True(t, ok)
+	require.Equal(t, "0af7651916cd43dd8448eb211c80319c", val)
+	require.Nil(t, entry.TraceID)
+}
+
+func TestSpanIDField(t *testing.T) {
+	entry := New()
+	field := NewSpanIDField()
+
+	err := entry.Set(field, []byte("b7ad6b7169203331"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31}, entry.SpanID)
+
+	val, ok := entry.Get(field)
+	require.True(t, ok)
+	require.Equal(t, "b7ad6b7169203331", val)
+}
+
+func TestTraceFlagsField(t *testing.T) {
+	entry := New()
+	field := NewTraceFlagsField()
+
+	err := entry.Set(field, "01")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01}, entry.TraceFlags)
+
+	val, ok := entry.Delete(field)
+	require.True(t, ok)
+	require.Equal(t, "01", val)
+	require.Nil(t, entry.TraceFlags)
+}
+
+func TestTraceFieldSetErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		field    Field
+		value    interface{}
+		expected string
+	}{
+		{"InvalidHex", NewTraceIDField(), "not hex", "is not a valid hex string"},
+		{"WrongLength", NewSpanIDField(), "0af7651916cd43dd8448eb211c80319c", "expected 8 bytes, but found 16"},
+		{"WrongType", NewTraceFlagsField(), 1, "type 'int' can not be used as a trace value"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := New()
+			err := entry.Set(tc.field, tc.value)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestTraceFieldFromString(t *testing.T) {
+	cases := []struct {
+		input     string
+		expected  Field
+		expectErr bool
+	}{
+		{"$trace_id", NewTraceIDField(), false},
+		{"$span_id", NewSpanIDField(), false},
+		{"$trace_flags", NewTraceFlagsField(), false},
+		{"$trace_id.nested", Field{}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			f, err := fieldFromString(tc.input)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, f)
+			require.Equal(t, tc.input, f.String())
+		})
+	}
+}
@@ -45,5 +45,6 @@ type DropOutput struct {
 
 // Process will drop the incoming entry.
 func (p *DropOutput) Process(ctx context.Context, entry *entry.Entry) error {
+	entry.Release()
 	return nil
 }
@@ -109,5 +109,6 @@ func (fo *FileOutput) Process(ctx context.Context, entry *entry.Entry) error {
 		}
 	}
 
+	entry.Release()
 	return nil
 }
@@ -2,6 +2,7 @@ package googlecloud
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"net/url"
@@ -233,6 +234,16 @@ func (p *GoogleCloudOutput) toLogNamePath(logName string) string {
 	return fmt.Sprintf("projects/%s/logs/%s", p.projectID, url.PathEscape(logName))
 }
 
+func (p *GoogleCloudOutput) toTracePath(traceID string) string {
+	return fmt.Sprintf("projects/%s/traces/%s", p.projectID, traceID)
+}
+
+// traceFlagSampled is the W3C trace flag indicating that a trace was sampled
+const traceFlagSampled = 0x01
+
+// severityTextLabel is the label used to send the original severity text of an entry
+const severityTextLabel = "severity_text"
+
 func (p *GoogleCloudOutput) createProtobufEntry(e *entry.Entry) (newEntry *logpb.LogEntry, err error) {
 	ts, err := ptypes.TimestampProto(e.Timestamp)
 	if err != nil {
@@ -273,7 +284,23 @@ func (p *GoogleCloudOutput) createProtobufEntry(e *entry.Entry) (newEntry *logpb
 		}
 	}
 
+	if newEntry.Trace == "" && len(e.TraceID) != 0 {
+		newEntry.Trace = p.toTracePath(hex.EncodeToString(e.TraceID))
+	}
+
+	if newEntry.SpanId == "" && len(e.SpanID) != 0 {
+		newEntry.SpanId = hex.EncodeToString(e.SpanID)
+	}
+
+	if len(e.TraceFlags) != 0 {
+		newEntry.TraceSampled = e.TraceFlags[0]&traceFlagSampled != 0
+	}
+
 	newEntry.Severity = convertSeverity(e.Severity)
+	if e.SeverityText != "" {
+		newEntry.Labels = withLabel(e.Labels, severityTextLabel, e.SeverityText)
+	}
+
 	err = setPayload(newEntry, e.Record)
 	if err != nil {
 		return nil, errors.Wrap(err, "set entry payload")
@@ -195,6 +195,96 @@ func TestGoogleCloudOutput(t *testing.T) {
 				return req
 			}(),
 		},
+		{
+			"EntryTraceContext",
+			googleCloudBasicConfig(),
+			&entry.Entry{
+				Timestamp:  now,
+				TraceID:    []byte{0x06, 0x79, 0x68, 0x66, 0x73, 0x8c, 0x85, 0x9f, 0x2f, 0x19, 0xb7, 0xcf, 0xb3, 0x21, 0x48, 0x24},
+				SpanID:     []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x4a},
+				TraceFlags: []byte{0x01},
+				Record: map[string]interface{}{
+					"message": "test message",
+				},
+			},
+			func() *logpb.WriteLogEntriesRequest {
+				req := googleCloudBasicWriteEntriesRequest()
+				req.Entries = []*logpb.LogEntry{
+					{
+						Trace:        "projects/test_project_id/traces/06796866738c859f2f19b7cfb3214824",
+						SpanId:       "000000000000004a",
+						TraceSampled: true,
+						Timestamp:    protoTs,
+						Payload: &logpb.LogEntry_JsonPayload{JsonPayload: jsonMapToProtoStruct(map[string]interface{}{
+							"message": "test message",
+						})},
+					},
+				}
+				return req
+			}(),
+		},
+		{
+			"OrderedMapRecord",
+			googleCloudBasicConfig(),
+			&entry.Entry{
+				Timestamp: now,
+				Record: func() *entry.OrderedMap {
+					nested := entry.NewOrderedMap()
+					nested.Set("key", "value")
+					record := entry.NewOrderedMap()
+					record.Set("message", "test message")
+					record.Set("nested", nested)
+					return record
+				}(),
+			},
+			func() *logpb.WriteLogEntriesRequest {
+				req := googleCloudBasicWriteEntriesRequest()
+				req.Entries = []*logpb.LogEntry{
+					{
+						Timestamp: protoTs,
+						Payload: &logpb.LogEntry_JsonPayload{JsonPayload: jsonMapToProtoStruct(map[string]interface{}{
+							"message": "test message",
+							"nested": map[string]interface{}{
+								"key": "value",
+							},
+						})},
+					},
+				}
+				return req
+			}(),
+		},
+		{
+			"SeverityText",
+			googleCloudBasicConfig(),
+			&entry.Entry{
+				Timestamp:    now,
+				Severity:     entry.Warning,
+				SeverityText: "WARN",
+				Labels: map[string]string{
+					"label": "value",
+				},
+				Record: map[string]interface{}{
+					"message": "test message",
+				},
+			},
+			func() *logpb.WriteLogEntriesRequest {
+				req := googleCloudBasicWriteEntriesRequest()
+				req.Entries = []*logpb.LogEntry{
+					{
+						Severity: sev.LogSeverity_WARNING,
+						Labels: map[string]string{
+							"label":         "value",
+							"severity_text": "WARN",
+						},
+						Timestamp: protoTs,
+						Payload: &logpb.LogEntry_JsonPayload{JsonPayload: jsonMapToProtoStruct(map[string]interface{}{
+							"message": "test message",
+						})},
+					},
+				}
+				return req
+			}(),
+		},
 	}
 
 	for _, tc := range cases {
@@ -6,10 +6,11 @@ import (
 	"reflect"
 
 	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/observiq/stanza/entry"
 	logpb "google.golang.org/genproto/googleapis/logging/v2"
 )
 
-func setPayload(entry *logpb.LogEntry, record interface{}) (err error) {
+func setPayload(logEntry *logpb.LogEntry, record interface{}) (err error) {
 	// Protect against the panic condition inside `jsonValueToStructValue`
 	defer func() {
 		if r := recover(); r != nil {
@@ -18,18 +19,21 @@ func setPayload(entry *logpb.LogEntry, record interface{}) (err error) {
 	}()
 	switch p := record.(type) {
 	case string:
-		entry.Payload = &logpb.LogEntry_TextPayload{TextPayload: p}
+		logEntry.Payload = &logpb.LogEntry_TextPayload{TextPayload: p}
 	case []byte:
-		entry.Payload = &logpb.LogEntry_TextPayload{TextPayload: string(p)}
+		logEntry.Payload = &logpb.LogEntry_TextPayload{TextPayload: string(p)}
 	case map[string]interface{}:
 		s := jsonMapToProtoStruct(p)
-		entry.Payload = &logpb.LogEntry_JsonPayload{JsonPayload: s}
+		logEntry.Payload = &logpb.LogEntry_JsonPayload{JsonPayload: s}
+	case *entry.OrderedMap:
+		s := jsonMapToProtoStruct(p.Map())
+		logEntry.Payload = &logpb.LogEntry_JsonPayload{JsonPayload: s}
 	case map[string]string:
 		fields := map[string]*structpb.Value{}
 		for k, v := range p {
 			fields[k] = jsonValueToStructValue(v)
 		}
-		entry.Payload = &logpb.LogEntry_JsonPayload{JsonPayload: &structpb.Struct{Fields: fields}}
+		logEntry.Payload = &logpb.LogEntry_JsonPayload{JsonPayload: &structpb.Struct{Fields: fields}}
 	default:
 		return fmt.Errorf("cannot convert record of type %T to a protobuf representation", record)
 	}
@@ -57,6 +61,8 @@ func jsonValueToStructValue(v interface{}) *structpb.Value {
 		return &structpb.Value{Kind: &structpb.Value_NullValue{}}
 	case map[string]interface{}:
 		return &structpb.Value{Kind: &structpb.Value_StructValue{StructValue: jsonMapToProtoStruct(x)}}
+	case *entry.OrderedMap:
+		return &structpb.Value{Kind: &structpb.Value_StructValue{StructValue: jsonMapToProtoStruct(x.Map())}}
 	case map[string]map[string]string:
 		fields := map[string]*structpb.Value{}
 		for k, v := range x {
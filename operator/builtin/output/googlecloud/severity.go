@@ -45,3 +45,14 @@ func convertSeverity(s entry.Severity) sev.LogSeverity {
 		return sev.LogSeverity_DEFAULT
 	}
 }
+
+// withLabel will return a copy of the labels with an additional key/value pair,
+// so that the labels of the original entry are not modified
+func withLabel(labels map[string]string, key, value string) map[string]string {
+	newLabels := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		newLabels[k] = v
+	}
+	newLabels[key] = value
+	return newLabels
+}
@@ -84,6 +84,28 @@ func TestNewRelicOutput(t *testing.T) {
 			}},
 			`[{"common":{"attributes":{"plugin":{"type":"stanza","version":"unknown"}}},"logs":[{"timestamp":1476089932000,"attributes":{"labels":null,"resource":null,"severity":"default"},"message":"testlog"}]}]` + "\n",
 		},
+		{
+			"TraceContext",
+			nil,
+			[]*entry.Entry{{
+				Timestamp: time.Date(2016, 10, 10, 8, 58, 52, 0, time.UTC),
+				TraceID:   []byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+				SpanID:    []byte{0x92, 0xc3, 0x79, 0x2d, 0x54, 0xba, 0x94, 0xf3},
+				Record:    "test",
+			}},
+			`[{"common":{"attributes":{"plugin":{"type":"stanza","version":"unknown"}}},"logs":[{"timestamp":1476089932000,"attributes":{"labels":null,"resource":null,"severity":"default","span.id":"92c3792d54ba94f3","trace.id":"480140f3d770a5ae32f0a22b6a812cff"},"message":"test"}]}]` + "\n",
+		},
+		{
+			"SeverityText",
+			nil,
+			[]*entry.Entry{{
+				Timestamp:    time.Date(2016, 10, 10, 8, 58, 52, 0, time.UTC),
+				Severity:     entry.Warning,
+				SeverityText: "WARN",
+				Record:       "test",
+			}},
+			`[{"common":{"attributes":{"plugin":{"type":"stanza","version":"unknown"}}},"logs":[{"timestamp":1476089932000,"attributes":{"labels":null,"resource":null,"severity":"warning","severity_text":"WARN"},"message":"test"}]}]` + "\n",
+		},
 	}
 
 	for _, tc := range cases {
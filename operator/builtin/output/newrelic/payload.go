@@ -1,6 +1,8 @@
 package newrelic
 
 import (
+	"encoding/hex"
+
 	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/internal/version"
 )
@@ -58,6 +60,16 @@ func LogMessageFromEntry(entry *entry.Entry, messageField entry.Field) *LogMessa
 	logMessage.Attributes["resource"] = entry.Resource
 	logMessage.Attributes["labels"] = entry.Labels
 	logMessage.Attributes["severity"] = entry.Severity.String()
+	if entry.SeverityText != "" {
+		logMessage.Attributes["severity_text"] = entry.SeverityText
+	}
+
+	if len(entry.TraceID) != 0 {
+		logMessage.Attributes["trace.id"] = hex.EncodeToString(entry.TraceID)
+	}
+	if len(entry.SpanID) != 0 {
+		logMessage.Attributes["span.id"] = hex.EncodeToString(entry.SpanID)
+	}
 
 	return logMessage
 }
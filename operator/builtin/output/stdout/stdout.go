@@ -61,5 +61,6 @@ func (o *StdoutOperator) Process(ctx context.Context, entry *entry.Entry) error
 		return err
 	}
 	o.mux.Unlock()
+	entry.Release()
 	return nil
 }
@@ -31,8 +31,9 @@ func TestStdoutOperator(t *testing.T) {
 
 	ts := time.Unix(1591042864, 0)
 	e := &entry.Entry{
-		Timestamp: ts,
-		Record:    "test record",
+		Timestamp:         ts,
+		ObservedTimestamp: ts,
+		Record:            "test record",
 	}
 	err = operator.Process(context.Background(), e)
 	require.NoError(t, err)
@@ -40,6 +41,6 @@ func TestStdoutOperator(t *testing.T) {
 	marshalledTimestamp, err := json.Marshal(ts)
 	require.NoError(t, err)
 
-	expected := `{"timestamp":` + string(marshalledTimestamp) + `,"severity":0,"record":"test record"}` + "\n"
+	expected := `{"timestamp":` + string(marshalledTimestamp) + `,"severity":0,"record":"test record","observed_timestamp":` + string(marshalledTimestamp) + `}` + "\n"
 	require.Equal(t, expected, buf.String())
 }
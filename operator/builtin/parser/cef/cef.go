@@ -0,0 +1,199 @@
+package cef
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("cef_parser", func() operator.Builder { return NewCEFParserConfig("") })
+}
+
+const (
+	cefPrefix       = "CEF:"
+	cefHeaderFields = 7
+	severityField   = "severity"
+	extensionsField = "extensions"
+	prefixField     = "prefix"
+)
+
+// headerFields are the names of the fields of a CEF header, in order
+var headerFields = [cefHeaderFields]string{
+	"version",
+	"device_vendor",
+	"device_product",
+	"device_version",
+	"signature_id",
+	"name",
+	severityField,
+}
+
+// NewCEFParserConfig creates a new CEF parser config with default values
+func NewCEFParserConfig(operatorID string) *CEFParserConfig {
+	return &CEFParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "cef_parser"),
+	}
+}
+
+// CEFParserConfig is the configuration of a CEF parser operator.
+type CEFParserConfig struct {
+	helper.ParserConfig `yaml:",inline"`
+}
+
+// Build will build a CEF parser operator.
+func (c CEFParserConfig) Build(context operator.BuildContext) (operator.Operator, error) {
+	// Unless a severity block is configured, the severity of the entry is parsed from the CEF severity
+	if c.SeverityParserConfig == nil {
+		if parseTo, ok := c.ParseTo.FieldInterface.(entry.RecordField); ok {
+			c.SeverityParserConfig = defaultSeverityParserConfig(entry.Field{FieldInterface: parseTo.Child(severityField)})
+		}
+	}
+
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	cefParser := &CEFParser{
+		ParserOperator: parserOperator,
+	}
+
+	return cefParser, nil
+}
+
+// defaultSeverityParserConfig maps the CEF severity, which is either a number from 0 to 10
+// or one of Unknown, Low, Medium, High or Very-High, to the severity of an entry
+func defaultSeverityParserConfig(parseFrom entry.Field) *helper.SeverityParserConfig {
+	return &helper.SeverityParserConfig{
+		ParseFrom: &parseFrom,
+		Preserve:  true,
+		Preset:    "none",
+		Mapping: map[interface{}]interface{}{
+			"default":  "unknown",
+			"info":     []interface{}{map[interface{}]interface{}{"min": 0, "max": 3}, "low"},
+			"warning":  []interface{}{map[interface{}]interface{}{"min": 4, "max": 6}, "medium"},
+			"error":    []interface{}{map[interface{}]interface{}{"min": 7, "max": 8}, "high"},
+			"critical": []interface{}{map[interface{}]interface{}{"min": 9, "max": 10}, "very-high"},
+		},
+	}
+}
+
+// CEFParser is an operator that parses ArcSight Common Event Format messages.
+type CEFParser struct {
+	helper.ParserOperator
+}
+
+// Process will parse an entry as a CEF message.
+func (c *CEFParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return c.ParserOperator.ProcessWith(ctx, entry, c.parse)
+}
+
+// parse will parse a value as a CEF message.
+func (c *CEFParser) parse(value interface{}) (interface{}, error) {
+	var message string
+	switch v := value.(type) {
+	case string:
+		message = v
+	case []byte:
+		message = string(v)
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed as CEF", value)
+	}
+
+	// Messages are often sent with a syslog header, which is kept as the prefix
+	start := strings.Index(message, cefPrefix)
+	if start == -1 {
+		return nil, fmt.Errorf("value is not a CEF message")
+	}
+
+	parsedValues := make(map[string]interface{}, cefHeaderFields+2)
+	if prefix := strings.TrimSpace(message[:start]); prefix != "" {
+		parsedValues[prefixField] = prefix
+	}
+
+	header, extension, err := splitHeader(message[start+len(cefPrefix):])
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range header {
+		parsedValues[headerFields[i]] = field
+	}
+
+	extensions, err := parseExtension(extension)
+	if err != nil {
+		return nil, err
+	}
+	parsedValues[extensionsField] = extensions
+
+	return parsedValues, nil
+}
+
+// splitHeader will split the pipe delimited fields of a CEF header from the extension.
+// Pipes and backslashes in header fields are escaped with a backslash.
+func splitHeader(message string) ([]string, string, error) {
+	fields := make([]string, 0, cefHeaderFields)
+	var field strings.Builder
+	for i := 0; i < len(message); i++ {
+		switch {
+		case message[i] == '\\' && i+1 < len(message) && (message[i+1] == '|' || message[i+1] == '\\'):
+			field.WriteByte(message[i+1])
+			i++
+		case message[i] == '|':
+			fields = append(fields, field.String())
+			field.Reset()
+			if len(fields) == cefHeaderFields {
+				return fields, message[i+1:], nil
+			}
+		default:
+			field.WriteByte(message[i])
+		}
+	}
+
+	return nil, "", errors.NewError(
+		fmt.Sprintf("CEF header has %d of %d fields", len(fields), cefHeaderFields),
+		"ensure that the message has a complete CEF header ending with a pipe",
+	)
+}
+
+// extensionKey matches the key of an extension pair, which starts the
+// extension or follows a space, and ends at an unescaped equals sign
+var extensionKey = regexp.MustCompile(`(?:^| )([A-Za-z0-9_.\[\]-]+)=`)
+
+// extensionUnescaper unescapes the values of extension pairs
+var extensionUnescaper = strings.NewReplacer(`\\`, `\`, `\=`, `=`, `\n`, "\n", `\r`, "\r", `\|`, `|`)
+
+// parseExtension will parse the space delimited key value pairs of a CEF extension.
+// A value ends at the space before the next key, so values may contain spaces.
+func parseExtension(extension string) (map[string]interface{}, error) {
+	extension = strings.TrimRight(extension, " \t\r\n")
+	extensions := map[string]interface{}{}
+	if extension == "" {
+		return extensions, nil
+	}
+
+	keys := extensionKey.FindAllStringSubmatchIndex(extension, -1)
+	if len(keys) == 0 || keys[0][0] != 0 {
+		return nil, errors.NewError(
+			"CEF extension does not start with a key",
+			"ensure that the extension is a list of key=value pairs, with equals signs in values escaped as \\=",
+		)
+	}
+
+	for i, key := range keys {
+		end := len(extension)
+		if i+1 < len(keys) {
+			end = keys[i+1][0]
+		}
+		name := extension[key[2]:key[3]]
+		extensions[name] = extensionUnescaper.Replace(extension[key[1]:end])
+	}
+
+	return extensions, nil
+}
@@ -0,0 +1,253 @@
+package cef
+
+import (
+	"context"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParser(t *testing.T, modify func(*CEFParserConfig)) *CEFParser {
+	cfg := NewCEFParserConfig("test")
+	modify(cfg)
+	op, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return op.(*CEFParser)
+}
+
+func TestCEFImplementations(t *testing.T) {
+	require.Implements(t, (*operator.Operator)(nil), new(CEFParser))
+}
+
+func TestCEFParser(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			"HeaderOnly",
+			"CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|",
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "Security",
+				"device_product": "threatmanager",
+				"device_version": "1.0",
+				"signature_id":   "100",
+				"name":           "worm successfully stopped",
+				"severity":       "10",
+				"extensions":     map[string]interface{}{},
+			},
+		},
+		{
+			"Extensions",
+			"CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 spt=1232",
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "Security",
+				"device_product": "threatmanager",
+				"device_version": "1.0",
+				"signature_id":   "100",
+				"name":           "worm successfully stopped",
+				"severity":       "10",
+				"extensions": map[string]interface{}{
+					"src": "10.0.0.1",
+					"dst": "2.1.2.2",
+					"spt": "1232",
+				},
+			},
+		},
+		{
+			"HeaderEscapes",
+			`CEF:0|security|threat\|manager|1.0|100|detected a \\ in packet|Medium|`,
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "security",
+				"device_product": "threat|manager",
+				"device_version": "1.0",
+				"signature_id":   "100",
+				"name":           `detected a \ in packet`,
+				"severity":       "Medium",
+				"extensions":     map[string]interface{}{},
+			},
+		},
+		{
+			"ExtensionEscapes",
+			`CEF:0|security|threatmanager|1.0|100|detected|1|msg=a \= b c:\\temp|x detected\nnext line act=blocked a \| b`,
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "security",
+				"device_product": "threatmanager",
+				"device_version": "1.0",
+				"signature_id":   "100",
+				"name":           "detected",
+				"severity":       "1",
+				"extensions": map[string]interface{}{
+					"msg": "a = b c:\\temp|x detected\nnext line",
+					"act": "blocked a | b",
+				},
+			},
+		},
+		{
+			"ExtensionValuesWithSpaces",
+			"CEF:0|Vendor|Product|2.0|42|Login|Low|suser=John Smith cs1Label=Reason cs1=bad password entered ",
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "Vendor",
+				"device_product": "Product",
+				"device_version": "2.0",
+				"signature_id":   "42",
+				"name":           "Login",
+				"severity":       "Low",
+				"extensions": map[string]interface{}{
+					"suser":    "John Smith",
+					"cs1Label": "Reason",
+					"cs1":      "bad password entered",
+				},
+			},
+		},
+		{
+			"SyslogPrefix",
+			[]byte("Sep 19 08:26:10 host CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1"),
+			map[string]interface{}{
+				"prefix":         "Sep 19 08:26:10 host",
+				"version":        "0",
+				"device_vendor":  "Security",
+				"device_product": "threatmanager",
+				"device_version": "1.0",
+				"signature_id":   "100",
+				"name":           "worm successfully stopped",
+				"severity":       "10",
+				"extensions":     map[string]interface{}{"src": "10.0.0.1"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, func(*CEFParserConfig) {})
+			parsed, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestCEFParserSeverity(t *testing.T) {
+	cases := []struct {
+		name         string
+		severity     string
+		expected     entry.Severity
+		expectedText string
+	}{
+		{"Zero", "0", entry.Info, "0"},
+		{"Three", "3", entry.Info, "3"},
+		{"Four", "4", entry.Warning, "4"},
+		{"Six", "6", entry.Warning, "6"},
+		{"Seven", "7", entry.Error, "7"},
+		{"Eight", "8", entry.Error, "8"},
+		{"Nine", "9", entry.Critical, "9"},
+		{"Ten", "10", entry.Critical, "10"},
+		{"Low", "Low", entry.Info, "Low"},
+		{"Medium", "Medium", entry.Warning, "Medium"},
+		{"High", "High", entry.Error, "High"},
+		{"VeryHigh", "Very-High", entry.Critical, "Very-High"},
+		{"Unknown", "Unknown", entry.Default, "Unknown"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, func(*CEFParserConfig) {})
+
+			var output *entry.Entry
+			mockOutput := &testutil.Operator{}
+			mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				output = args[1].(*entry.Entry)
+			}).Return(nil)
+			parser.OutputOperators = []operator.Operator{mockOutput}
+
+			e := entry.New()
+			e.Record = "CEF:0|Vendor|Product|1.0|100|event|" + tc.severity + "|src=10.0.0.1"
+			err := parser.Process(context.Background(), e)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, output.Severity)
+			require.Equal(t, tc.expectedText, output.SeverityText)
+
+			// The CEF severity is preserved in the record
+			record := output.Record.(map[string]interface{})
+			require.Equal(t, tc.severity, record["severity"])
+		})
+	}
+}
+
+func TestCEFParserSeverityOverride(t *testing.T) {
+	parser := newTestParser(t, func(cfg *CEFParserConfig) {
+		parseFrom := entry.NewRecordField("extensions", "sev")
+		cfg.SeverityParserConfig = &helper.SeverityParserConfig{
+			ParseFrom: &parseFrom,
+			Preset:    "none",
+			Mapping:   map[interface{}]interface{}{"alert": "urgent"},
+		}
+	})
+
+	var output *entry.Entry
+	mockOutput := &testutil.Operator{}
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		output = args[1].(*entry.Entry)
+	}).Return(nil)
+	parser.OutputOperators = []operator.Operator{mockOutput}
+
+	e := entry.New()
+	e.Record = "CEF:0|Vendor|Product|1.0|100|event|1|sev=urgent"
+	err := parser.Process(context.Background(), e)
+	require.NoError(t, err)
+	require.Equal(t, entry.Alert, output.Severity)
+}
+
+func TestCEFParserFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{
+			"NotCEF",
+			"just a regular log line",
+			"value is not a CEF message",
+		},
+		{
+			"IncompleteHeader",
+			"CEF:0|Vendor|Product|1.0|100|event",
+			"CEF header has 5 of 7 fields",
+		},
+		{
+			"EscapedHeaderPipe",
+			`CEF:0|Vendor|Product|1.0|100|event|10\|`,
+			"CEF header has 6 of 7 fields",
+		},
+		{
+			"ExtensionWithoutKey",
+			"CEF:0|Vendor|Product|1.0|100|event|10|no key here",
+			"CEF extension does not start with a key",
+		},
+		{
+			"InvalidType",
+			map[string]interface{}{},
+			"type 'map[string]interface {}' cannot be parsed as CEF",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, func(*CEFParserConfig) {})
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("container_parser", func() operator.Builder { return NewContainerParserConfig("") })
+}
+
+const (
+	autoFormat   = "auto"
+	dockerFormat = "docker"
+	criFormat    = "cri"
+)
+
+// Resource keys of the kubernetes metadata parsed from file paths
+const (
+	namespaceKey     = "k8s.namespace.name"
+	podNameKey       = "k8s.pod.name"
+	podUIDKey        = "k8s.pod.uid"
+	containerNameKey = "k8s.container.name"
+)
+
+// NewContainerParserConfig creates a new container parser config with default values
+func NewContainerParserConfig(operatorID string) *ContainerParserConfig {
+	return &ContainerParserConfig{
+		ParserConfig:            helper.NewParserConfig(operatorID, "container_parser"),
+		Format:                  autoFormat,
+		AddMetadataFromFilePath: true,
+		FilePathField:           entry.NewLabelField("file_path"),
+		MaxLogSize:              1024 * 1024,
+		ForceFlushPeriod:        helper.Duration{Duration: 5 * time.Second},
+	}
+}
+
+// ContainerParserConfig is the configuration of a container parser operator.
+type ContainerParserConfig struct {
+	helper.ParserConfig `yaml:",inline"`
+
+	Format                  string          `json:"format"                      yaml:"format"`
+	AddMetadataFromFilePath bool            `json:"add_metadata_from_file_path" yaml:"add_metadata_from_file_path"`
+	FilePathField           entry.Field     `json:"file_path_field"             yaml:"file_path_field"`
+	MaxLogSize              int             `json:"max_log_size,omitempty"      yaml:"max_log_size,omitempty"`
+	ForceFlushPeriod        helper.Duration `json:"force_flush_period"          yaml:"force_flush_period"`
+}
+
+// Build will build a container parser operator.
+func (c ContainerParserConfig) Build(context operator.BuildContext) (operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Format {
+	case autoFormat, dockerFormat, criFormat:
+	default:
+		return nil, errors.NewError(
+			fmt.Sprintf("invalid container log format '%s'", c.Format),
+			fmt.Sprintf("specify a format of %s, %s or %s", autoFormat, dockerFormat, criFormat),
+		)
+	}
+
+	if c.MaxLogSize <= 0 {
+		return nil, fmt.Errorf("max_log_size must be greater than zero")
+	}
+
+	if c.ForceFlushPeriod.Raw() <= 0 {
+		return nil, fmt.Errorf("force_flush_period must be greater than zero")
+	}
+
+	containerParser := &ContainerParser{
+		ParserOperator:          parserOperator,
+		format:                  c.Format,
+		addMetadataFromFilePath: c.AddMetadataFromFilePath,
+		filePathField:           c.FilePathField,
+		maxLogSize:              c.MaxLogSize,
+		forceFlushPeriod:        c.ForceFlushPeriod.Raw(),
+		pending:                 map[string]*pendingLine{},
+	}
+
+	return containerParser, nil
+}
+
+// ContainerParser is an operator that parses the logs written by container runtimes.
+type ContainerParser struct {
+	helper.ParserOperator
+
+	format                  string
+	addMetadataFromFilePath bool
+	filePathField           entry.Field
+	maxLogSize              int
+	forceFlushPeriod        time.Duration
+
+	// pending holds the partial lines of each file and stream that are waiting to be joined
+	pending map[string]*pendingLine
+	mux     sync.Mutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// containerLine is a line of a container log
+type containerLine struct {
+	timestamp time.Time
+	stream    string
+	log       string
+	partial   bool
+}
+
+// pendingLine is a log that has been split into partial lines by the container runtime
+type pendingLine struct {
+	entry     *entry.Entry
+	timestamp time.Time
+	stream    string
+	log       strings.Builder
+	updated   time.Time
+}
+
+// Start will start flushing partial lines that are not completed within the force flush period.
+func (c *ContainerParser) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.forceFlushPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.flush(ctx, time.Now().Add(-c.forceFlushPeriod))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop will stop the operator and flush any partial lines.
+func (c *ContainerParser) Stop() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+	c.flush(context.Background(), time.Now())
+	return nil
+}
+
+// flush will send the partial lines that were last updated before a time
+func (c *ContainerParser) flush(ctx context.Context, before time.Time) {
+	c.mux.Lock()
+	flushed := make([]*pendingLine, 0, len(c.pending))
+	for key, pending := range c.pending {
+		if pending.updated.Before(before) {
+			flushed = append(flushed, pending)
+			delete(c.pending, key)
+		}
+	}
+	c.mux.Unlock()
+
+	for _, pending := range flushed {
+		_ = c.emit(ctx, pending)
+	}
+}
+
+// Process will parse an entry as a line of a container log, and join partial lines.
+func (c *ContainerParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return c.ProcessIf(ctx, entry, c.process)
+}
+
+// process will parse an entry that matches the if expression.
+func (c *ContainerParser) process(ctx context.Context, entry *entry.Entry) error {
+	value, ok := entry.Get(c.ParseFrom)
+	if !ok {
+		err := errors.NewError(
+			"Entry is missing the expected parse_from field.",
+			"Ensure that all incoming entries contain the parse_from field.",
+			"parse_from", c.ParseFrom.String(),
+		)
+		return c.HandleEntryError(ctx, entry, err)
+	}
+
+	line, err := c.parseLine(value)
+	if err != nil {
+		return c.HandleEntryError(ctx, entry, err)
+	}
+
+	key := line.stream
+	if filePath, ok := entry.Get(c.filePathField); ok {
+		key = fmt.Sprintf("%v:%s", filePath, line.stream)
+	}
+
+	c.mux.Lock()
+	pending, ok := c.pending[key]
+	if !ok {
+		pending = &pendingLine{entry: entry, timestamp: line.timestamp, stream: line.stream}
+	}
+	pending.log.WriteString(line.log)
+	pending.updated = time.Now()
+
+	// Lines are sent when they are complete, or when they reach the maximum size
+	if line.partial && pending.log.Len() < c.maxLogSize {
+		c.pending[key] = pending
+		c.mux.Unlock()
+		return nil
+	}
+	delete(c.pending, key)
+	c.mux.Unlock()
+
+	return c.emit(ctx, pending)
+}
+
+// emit will send a complete line
+func (c *ContainerParser) emit(ctx context.Context, pending *pendingLine) error {
+	entry := pending.entry
+	entry.Timestamp = pending.timestamp
+
+	if c.addMetadataFromFilePath {
+		c.addMetadata(entry)
+	}
+
+	record := map[string]interface{}{
+		"stream": pending.stream,
+		"log":    pending.log.String(),
+	}
+	return c.ParseWith(ctx, entry, func(interface{}) (interface{}, error) {
+		return record, nil
+	})
+}
+
+// parseLine will parse a line of a container log in the configured format
+func (c *ContainerParser) parseLine(value interface{}) (*containerLine, error) {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed as a container log", value)
+	}
+
+	format := c.format
+	if format == autoFormat {
+		format = detectFormat(raw)
+	}
+
+	if format == dockerFormat {
+		return parseDocker(raw)
+	}
+	return parseCRI(raw)
+}
+
+// detectFormat will detect the format of a line. Docker writes JSON
+// objects, and CRI runtimes write lines that start with a timestamp.
+func detectFormat(raw string) string {
+	if strings.HasPrefix(raw, "{") {
+		return dockerFormat
+	}
+	return criFormat
+}
+
+// dockerLine is a line of the Docker json-file log format
+type dockerLine struct {
+	Log    string `json:"log"`
+	Stream string `json:"stream"`
+	Time   string `json:"time"`
+}
+
+// parseDocker will parse a line of the Docker json-file log format.
+// Docker splits long lines into partial lines that do not end with a newline.
+func parseDocker(raw string) (*containerLine, error) {
+	var parsed dockerLine
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, errors.Wrap(err, "parse docker log")
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, parsed.Time)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse docker log time")
+	}
+
+	return &containerLine{
+		timestamp: timestamp,
+		stream:    parsed.Stream,
+		log:       strings.TrimSuffix(parsed.Log, "\n"),
+		partial:   !strings.HasSuffix(parsed.Log, "\n"),
+	}, nil
+}
+
+// parseCRI will parse a line of the CRI log format, which is
+// <time> <stream> <tag> <log>, where the tag is P for partial lines and F for full lines
+func parseCRI(raw string) (*containerLine, error) {
+	parts := strings.SplitN(raw, " ", 4)
+	if len(parts) < 3 {
+		return nil, errors.NewError(
+			"container log line is not in the CRI format",
+			"ensure that the container runtime writes logs in the CRI or Docker json-file format",
+		)
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "parse CRI log time")
+	}
+
+	line := &containerLine{
+		timestamp: timestamp,
+		stream:    parts[1],
+		partial:   strings.HasPrefix(parts[2], "P"),
+	}
+	if len(parts) == 4 {
+		line.log = parts[3]
+	}
+	return line, nil
+}
+
+// podLogPath matches the paths of pod logs, which are
+// /var/log/pods/<namespace>_<pod_name>_<pod_uid>/<container_name>/<restart_count>.log
+var podLogPath = regexp.MustCompile(`/([^/_]+)_([^/_]+)_([^/_]+)/([^/]+)/[0-9]+\.log$`)
+
+// containerLogPath matches the paths of the links to container logs, which are
+// /var/log/containers/<pod_name>_<namespace>_<container_name>-<container_id>.log
+var containerLogPath = regexp.MustCompile(`/([^/_]+)_([^/_]+)_([^/]+)-[0-9a-f]{64}\.log$`)
+
+// addMetadata will add the kubernetes metadata in the file path of an entry to its resource
+func (c *ContainerParser) addMetadata(entry *entry.Entry) {
+	value, ok := entry.Get(c.filePathField)
+	if !ok {
+		return
+	}
+	filePath, ok := value.(string)
+	if !ok {
+		return
+	}
+
+	if match := podLogPath.FindStringSubmatch(filePath); match != nil {
+		entry.AddResourceKey(namespaceKey, match[1])
+		entry.AddResourceKey(podNameKey, match[2])
+		entry.AddResourceKey(podUIDKey, match[3])
+		entry.AddResourceKey(containerNameKey, match[4])
+		return
+	}
+
+	if match := containerLogPath.FindStringSubmatch(filePath); match != nil {
+		entry.AddResourceKey(podNameKey, match[1])
+		entry.AddResourceKey(namespaceKey, match[2])
+		entry.AddResourceKey(containerNameKey, match[3])
+	}
+}
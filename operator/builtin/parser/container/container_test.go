@@ -0,0 +1,377 @@
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const podLogFile = "/var/log/pods/kube-system_coredns-5644d7b6d9-mzngq_5a4a2b5c-2f0d-4f57-9b1e-3c0c3f5a1d2e/coredns/0.log"
+
+func newTestParser(t *testing.T, modify func(*ContainerParserConfig)) (*ContainerParser, *[]*entry.Entry) {
+	cfg := NewContainerParserConfig("test")
+	modify(cfg)
+	op, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := op.(*ContainerParser)
+
+	outputs := []*entry.Entry{}
+	mockOutput := &testutil.Operator{}
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		outputs = append(outputs, args[1].(*entry.Entry))
+	}).Return(nil)
+	parser.OutputOperators = []operator.Operator{mockOutput}
+
+	return parser, &outputs
+}
+
+func newTestEntry(line, filePath string) *entry.Entry {
+	e := entry.New()
+	e.Record = line
+	if filePath != "" {
+		e.AddLabel("file_path", filePath)
+	}
+	return e
+}
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	timestamp, err := time.Parse(time.RFC3339Nano, value)
+	require.NoError(t, err)
+	return timestamp
+}
+
+func TestContainerImplementations(t *testing.T) {
+	require.Implements(t, (*operator.Operator)(nil), new(ContainerParser))
+}
+
+func TestContainerParser(t *testing.T) {
+	cases := []struct {
+		name           string
+		format         string
+		lines          []string
+		expectedTime   string
+		expectedRecord map[string]interface{}
+	}{
+		{
+			"CRI",
+			autoFormat,
+			[]string{"2020-09-24T13:05:09.123456789Z stdout F server started"},
+			"2020-09-24T13:05:09.123456789Z",
+			map[string]interface{}{"stream": "stdout", "log": "server started"},
+		},
+		{
+			"CRIEmptyLog",
+			criFormat,
+			[]string{"2020-09-24T13:05:09.123456789+02:00 stderr F"},
+			"2020-09-24T13:05:09.123456789+02:00",
+			map[string]interface{}{"stream": "stderr", "log": ""},
+		},
+		{
+			"CRIPartial",
+			autoFormat,
+			[]string{
+				"2020-09-24T13:05:09.1Z stdout P a very ",
+				"2020-09-24T13:05:09.2Z stdout P long line ",
+				"2020-09-24T13:05:09.3Z stdout F that was split",
+			},
+			"2020-09-24T13:05:09.1Z",
+			map[string]interface{}{"stream": "stdout", "log": "a very long line that was split"},
+		},
+		{
+			"Docker",
+			autoFormat,
+			[]string{`{"log":"server started\n","stream":"stdout","time":"2020-09-24T13:05:09.123456789Z"}`},
+			"2020-09-24T13:05:09.123456789Z",
+			map[string]interface{}{"stream": "stdout", "log": "server started"},
+		},
+		{
+			"DockerPartial",
+			dockerFormat,
+			[]string{
+				`{"log":"a very ","stream":"stderr","time":"2020-09-24T13:05:09.1Z"}`,
+				`{"log":"long line\n","stream":"stderr","time":"2020-09-24T13:05:09.2Z"}`,
+			},
+			"2020-09-24T13:05:09.1Z",
+			map[string]interface{}{"stream": "stderr", "log": "a very long line"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, outputs := newTestParser(t, func(cfg *ContainerParserConfig) {
+				cfg.Format = tc.format
+			})
+
+			for _, line := range tc.lines {
+				err := parser.Process(context.Background(), newTestEntry(line, podLogFile))
+				require.NoError(t, err)
+			}
+
+			require.Len(t, *outputs, 1)
+			output := (*outputs)[0]
+			require.Equal(t, tc.expectedRecord, output.Record)
+			require.True(t, mustParseTime(t, tc.expectedTime).Equal(output.Timestamp))
+		})
+	}
+}
+
+func TestContainerParserPartialLinesPerStream(t *testing.T) {
+	parser, outputs := newTestParser(t, func(*ContainerParserConfig) {})
+	otherFile := "/var/log/pods/default_web-1_0f1e2d3c-4b5a-6978-8a9b-0c1d2e3f4a5b/web/0.log"
+
+	lines := []struct {
+		line     string
+		filePath string
+	}{
+		{"2020-09-24T13:05:09Z stdout P out ", podLogFile},
+		{"2020-09-24T13:05:09Z stderr P err ", podLogFile},
+		{"2020-09-24T13:05:09Z stdout P other ", otherFile},
+		{"2020-09-24T13:05:09Z stderr F line", podLogFile},
+		{"2020-09-24T13:05:09Z stdout F line", otherFile},
+		{"2020-09-24T13:05:09Z stdout F line", podLogFile},
+	}
+	for _, l := range lines {
+		err := parser.Process(context.Background(), newTestEntry(l.line, l.filePath))
+		require.NoError(t, err)
+	}
+
+	require.Len(t, *outputs, 3)
+	require.Equal(t, map[string]interface{}{"stream": "stderr", "log": "err line"}, (*outputs)[0].Record)
+	require.Equal(t, map[string]interface{}{"stream": "stdout", "log": "other line"}, (*outputs)[1].Record)
+	require.Equal(t, "web-1", (*outputs)[1].Resource[podNameKey])
+	require.Equal(t, map[string]interface{}{"stream": "stdout", "log": "out line"}, (*outputs)[2].Record)
+}
+
+func TestContainerParserMaxLogSize(t *testing.T) {
+	parser, outputs := newTestParser(t, func(cfg *ContainerParserConfig) {
+		cfg.MaxLogSize = 8
+	})
+
+	lines := []string{
+		"2020-09-24T13:05:09Z stdout P 12345",
+		"2020-09-24T13:05:09Z stdout P 67890",
+		"2020-09-24T13:05:09Z stdout F end",
+	}
+	for _, line := range lines {
+		err := parser.Process(context.Background(), newTestEntry(line, podLogFile))
+		require.NoError(t, err)
+	}
+
+	require.Len(t, *outputs, 2)
+	require.Equal(t, "1234567890", (*outputs)[0].Record.(map[string]interface{})["log"])
+	require.Equal(t, "end", (*outputs)[1].Record.(map[string]interface{})["log"])
+}
+
+func TestContainerParserForceFlush(t *testing.T) {
+	parser, _ := newTestParser(t, func(cfg *ContainerParserConfig) {
+		cfg.ForceFlushPeriod = helper.Duration{Duration: 10 * time.Millisecond}
+	})
+
+	// Flushed lines are sent from another goroutine
+	outputs := make(chan *entry.Entry, 1)
+	mockOutput := &testutil.Operator{}
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		outputs <- args[1].(*entry.Entry)
+	}).Return(nil)
+	parser.OutputOperators = []operator.Operator{mockOutput}
+
+	err := parser.Start()
+	require.NoError(t, err)
+	defer parser.Stop()
+
+	err = parser.Process(context.Background(), newTestEntry("2020-09-24T13:05:09Z stdout P never finished", podLogFile))
+	require.NoError(t, err)
+
+	select {
+	case output := <-outputs:
+		require.Equal(t, "never finished", output.Record.(map[string]interface{})["log"])
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for the partial line to be flushed")
+	}
+}
+
+func TestContainerParserStopFlush(t *testing.T) {
+	parser, outputs := newTestParser(t, func(*ContainerParserConfig) {})
+
+	err := parser.Start()
+	require.NoError(t, err)
+
+	err = parser.Process(context.Background(), newTestEntry("2020-09-24T13:05:09Z stdout P never finished", podLogFile))
+	require.NoError(t, err)
+	require.Len(t, *outputs, 0)
+
+	err = parser.Stop()
+	require.NoError(t, err)
+	require.Len(t, *outputs, 1)
+}
+
+func TestContainerParserMetadata(t *testing.T) {
+	cases := []struct {
+		name     string
+		filePath string
+		disabled bool
+		expected map[string]string
+	}{
+		{
+			"PodLogPath",
+			podLogFile,
+			false,
+			map[string]string{
+				namespaceKey:     "kube-system",
+				podNameKey:       "coredns-5644d7b6d9-mzngq",
+				podUIDKey:        "5a4a2b5c-2f0d-4f57-9b1e-3c0c3f5a1d2e",
+				containerNameKey: "coredns",
+			},
+		},
+		{
+			"ContainerLogPath",
+			"/var/log/containers/coredns-5644d7b6d9-mzngq_kube-system_core-dns-8f8e7a3b0c9d2e1f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f.log",
+			false,
+			map[string]string{
+				namespaceKey:     "kube-system",
+				podNameKey:       "coredns-5644d7b6d9-mzngq",
+				containerNameKey: "core-dns",
+			},
+		},
+		{
+			"OtherPath",
+			"/var/log/app.log",
+			false,
+			nil,
+		},
+		{
+			"MissingPath",
+			"",
+			false,
+			nil,
+		},
+		{
+			"Disabled",
+			podLogFile,
+			true,
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, outputs := newTestParser(t, func(cfg *ContainerParserConfig) {
+				cfg.AddMetadataFromFilePath = !tc.disabled
+			})
+
+			err := parser.Process(context.Background(), newTestEntry("2020-09-24T13:05:09Z stdout F line", tc.filePath))
+			require.NoError(t, err)
+			require.Len(t, *outputs, 1)
+			require.Equal(t, tc.expected, (*outputs)[0].Resource)
+		})
+	}
+}
+
+func TestContainerParserIfSkip(t *testing.T) {
+	parser, outputs := newTestParser(t, func(cfg *ContainerParserConfig) {
+		cfg.IfExpr = `$labels.file_path != ""`
+	})
+
+	err := parser.Process(context.Background(), newTestEntry("not a container log", ""))
+	require.NoError(t, err)
+	require.Len(t, *outputs, 1)
+	require.Equal(t, "not a container log", (*outputs)[0].Record)
+}
+
+func TestContainerParserFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   string
+		input    interface{}
+		expected string
+	}{
+		{
+			"NotCRI",
+			autoFormat,
+			"not_a_container_log",
+			"container log line is not in the CRI format",
+		},
+		{
+			"InvalidCRITime",
+			criFormat,
+			"yesterday stdout F line",
+			"parse CRI log time",
+		},
+		{
+			"InvalidJSON",
+			autoFormat,
+			`{"log":`,
+			"parse docker log",
+		},
+		{
+			"InvalidDockerTime",
+			dockerFormat,
+			`{"log":"line\n","stream":"stdout","time":"yesterday"}`,
+			"parse docker log time",
+		},
+		{
+			"DockerLineAsCRI",
+			criFormat,
+			`{"log":"line\n","stream":"stdout","time":"2020-09-24T13:05:09Z"}`,
+			"container log line is not in the CRI format",
+		},
+		{
+			"InvalidType",
+			autoFormat,
+			map[string]interface{}{},
+			"type 'map[string]interface {}' cannot be parsed as a container log",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, _ := newTestParser(t, func(cfg *ContainerParserConfig) {
+				cfg.Format = tc.format
+			})
+			_, err := parser.parseLine(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestContainerParserConfigBuildFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*ContainerParserConfig)
+		expected string
+	}{
+		{
+			"InvalidFormat",
+			func(cfg *ContainerParserConfig) { cfg.Format = "podman" },
+			"invalid container log format 'podman'",
+		},
+		{
+			"InvalidMaxLogSize",
+			func(cfg *ContainerParserConfig) { cfg.MaxLogSize = -1 },
+			"max_log_size must be greater than zero",
+		},
+		{
+			"InvalidForceFlushPeriod",
+			func(cfg *ContainerParserConfig) { cfg.ForceFlushPeriod = helper.Duration{} },
+			"force_flush_period must be greater than zero",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewContainerParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
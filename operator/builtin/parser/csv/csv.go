@@ -0,0 +1,478 @@
+package csv
+
+import (
+	"container/list"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("csv_parser", func() operator.Builder { return NewCSVParserConfig("") })
+}
+
+const (
+	stringType = "string"
+	intType    = "int"
+	floatType  = "float"
+	boolType   = "bool"
+)
+
+// defaultMaxSources is the default number of sources that headers are kept for
+const defaultMaxSources = 1000
+
+// NewCSVParserConfig creates a new CSV parser config with default values
+func NewCSVParserConfig(operatorID string) *CSVParserConfig {
+	return &CSVParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "csv_parser"),
+		Delimiter:    ",",
+		HeaderSource: entry.NewLabelField("file_path"),
+		MaxSources:   defaultMaxSources,
+	}
+}
+
+// CSVParserConfig is the configuration of a CSV parser operator.
+type CSVParserConfig struct {
+	helper.ParserConfig `yaml:",inline"`
+
+	Header              []string          `json:"header,omitempty"                 yaml:"header,omitempty"`
+	HeaderFromFirstLine bool              `json:"header_from_first_line,omitempty" yaml:"header_from_first_line,omitempty"`
+	HeaderSource        entry.Field       `json:"header_source,omitempty"          yaml:"header_source,omitempty"`
+	MaxSources          int               `json:"max_sources,omitempty"            yaml:"max_sources,omitempty"`
+	Delimiter           string            `json:"delimiter,omitempty"              yaml:"delimiter,omitempty"`
+	LazyQuotes          bool              `json:"lazy_quotes,omitempty"            yaml:"lazy_quotes,omitempty"`
+	Types               map[string]string `json:"types,omitempty"                  yaml:"types,omitempty"`
+	PreserveOrder       bool              `json:"preserve_order,omitempty"         yaml:"preserve_order,omitempty"`
+}
+
+// Build will build a CSV parser operator.
+func (c CSVParserConfig) Build(context operator.BuildContext) (operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(c.Header) == 0 && !c.HeaderFromFirstLine:
+		return nil, errors.NewError(
+			"missing required field 'header'",
+			"specify the names of the columns with 'header', or set 'header_from_first_line' to true",
+		)
+	case len(c.Header) != 0 && c.HeaderFromFirstLine:
+		return nil, errors.NewError(
+			"'header' and 'header_from_first_line' can not be used together",
+			"remove 'header' to read the header from the first line, or remove 'header_from_first_line'",
+		)
+	}
+
+	if len(c.Header) != 0 {
+		if err := validateHeader(c.Header); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.MaxSources <= 0 {
+		return nil, errors.NewError(
+			"'max_sources' must be greater than zero",
+			"specify the number of sources to keep headers for, or remove 'max_sources' to use the default",
+			"max_sources", strconv.Itoa(c.MaxSources),
+		)
+	}
+
+	delimiter, err := parseDelimiter(c.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	for column, columnType := range c.Types {
+		switch columnType {
+		case stringType, intType, floatType, boolType:
+		default:
+			return nil, errors.NewError(
+				fmt.Sprintf("invalid type '%s' for column '%s'", columnType, column),
+				fmt.Sprintf("specify one of %s, %s, %s or %s", stringType, intType, floatType, boolType),
+				"column", column,
+			)
+		}
+
+		if len(c.Header) != 0 && !contains(c.Header, column) {
+			return nil, errors.NewError(
+				fmt.Sprintf("type specified for column '%s', which is not in the header", column),
+				"ensure that every column in 'types' is also in 'header'",
+				"column", column,
+			)
+		}
+	}
+
+	csvParser := &CSVParser{
+		ParserOperator:      parserOperator,
+		header:              c.Header,
+		headerFromFirstLine: c.HeaderFromFirstLine,
+		headerSource:        c.HeaderSource,
+		headers:             newHeaderCache(c.MaxSources),
+		delimiter:           delimiter,
+		lazyQuotes:          c.LazyQuotes,
+		types:               c.Types,
+		preserveOrder:       c.PreserveOrder,
+	}
+
+	return csvParser, nil
+}
+
+// parseDelimiter will return the rune of a delimiter, which must be a single character
+func parseDelimiter(delimiter string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, errors.NewError(
+			fmt.Sprintf("invalid delimiter '%s'", delimiter),
+			"specify a single character other than a quote or newline as the delimiter",
+		)
+	}
+	return r, nil
+}
+
+// validateHeader will return an error if a header has an empty or duplicate column name
+func validateHeader(header []string) error {
+	columns := make(map[string]struct{}, len(header))
+	for _, column := range header {
+		if column == "" {
+			return errors.NewError(
+				"header contains an empty column name",
+				"ensure that every column in the header has a name",
+			)
+		}
+		if _, ok := columns[column]; ok {
+			return errors.NewError(
+				fmt.Sprintf("header contains the column '%s' more than once", column),
+				"ensure that every column in the header has a unique name",
+				"column", column,
+			)
+		}
+		columns[column] = struct{}{}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CSVParser is an operator that parses CSV.
+type CSVParser struct {
+	helper.ParserOperator
+	header              []string
+	headerFromFirstLine bool
+	headerSource        entry.Field
+	delimiter           rune
+	lazyQuotes          bool
+	types               map[string]string
+	preserveOrder       bool
+
+	// headers holds the header read from the first line of each source
+	headers *headerCache
+}
+
+// sourceHeader is the header of a source, along with the line it was read from
+type sourceHeader struct {
+	line    string
+	columns []string
+
+	// parsed is true once a record of the source was parsed with the header
+	parsed bool
+
+	// previous is the header that this header replaced, which is restored if the
+	// line read as this header was a record, and the records that follow match it
+	previous *sourceHeader
+}
+
+// headerCache holds the headers of the most recently used sources.
+// The header of the least recently used source is removed once there are too many sources.
+type headerCache struct {
+	maxSources int
+	sources    map[string]*list.Element
+	order      *list.List
+	mutex      sync.Mutex
+}
+
+// cachedHeader is the header of a source in a header cache
+type cachedHeader struct {
+	source string
+	header *sourceHeader
+}
+
+func newHeaderCache(maxSources int) *headerCache {
+	return &headerCache{
+		maxSources: maxSources,
+		sources:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+// get will return the header of a source, and mark the source as the most recently used
+func (h *headerCache) get(source string) (*sourceHeader, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	element, ok := h.sources[source]
+	if !ok {
+		return nil, false
+	}
+	h.order.MoveToFront(element)
+	return element.Value.(*cachedHeader).header, true
+}
+
+// set will set the header of a source, removing the least recently used source if there are too many
+func (h *headerCache) set(source string, header *sourceHeader) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if element, ok := h.sources[source]; ok {
+		element.Value.(*cachedHeader).header = header
+		h.order.MoveToFront(element)
+		return
+	}
+
+	h.sources[source] = h.order.PushFront(&cachedHeader{source: source, header: header})
+	if h.order.Len() > h.maxSources {
+		oldest := h.order.Remove(h.order.Back()).(*cachedHeader)
+		delete(h.sources, oldest.source)
+	}
+}
+
+// len will return the number of sources with a header
+func (h *headerCache) len() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.order.Len()
+}
+
+// Process will parse an entry as CSV.
+func (c *CSVParser) Process(ctx context.Context, entry *entry.Entry) error {
+	if !c.headerFromFirstLine {
+		return c.ParserOperator.ProcessWith(ctx, entry, c.parse)
+	}
+	return c.ProcessIf(ctx, entry, c.processWithHeader)
+}
+
+// processWithHeader will parse an entry that matches the if expression with the header of its source.
+func (c *CSVParser) processWithHeader(ctx context.Context, entry *entry.Entry) error {
+	source := c.source(entry)
+	header, ok := c.headers.get(source)
+	if !ok {
+		return c.readHeader(ctx, entry, source)
+	}
+
+	// A file that is rotated or truncated will repeat its header,
+	// which is dropped rather than parsed as a record
+	value, _ := entry.Get(c.ParseFrom)
+	line := lineOf(value)
+	if line == header.line {
+		return nil
+	}
+
+	fields, readErr := c.readRecord(value)
+
+	// A file that is rotated may start with a different header. A line is only read as
+	// a new header if it can not be a record of the current header, and the current
+	// header was used for a record, so that a header is not replaced twice in a row.
+	if readErr == nil && header.parsed && len(fields) != len(header.columns) && validateHeader(fields) == nil {
+		c.Infow("Replacing the header of a source", "source", source, "header", line)
+		c.headers.set(source, &sourceHeader{line: line, columns: fields, previous: header})
+		return nil
+	}
+
+	return c.ParseWith(ctx, entry, func(interface{}) (interface{}, error) {
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		// The line that replaced the header was a record if the records
+		// that follow it match the header that it replaced instead
+		restore := !header.parsed && header.previous != nil &&
+			len(fields) != len(header.columns) && len(fields) == len(header.previous.columns)
+		if restore {
+			c.Warnw("Restoring the previous header of a source", "source", source, "header", header.previous.line)
+			header = header.previous
+		}
+
+		parsed, err := c.parseFields(fields, header.columns)
+		if err != nil {
+			return nil, err
+		}
+		if restore || !header.parsed {
+			c.headers.set(source, &sourceHeader{line: header.line, columns: header.columns, parsed: true})
+		}
+		return parsed, nil
+	})
+}
+
+// source will return the source of an entry, which identifies the header that applies to it
+func (c *CSVParser) source(entry *entry.Entry) string {
+	value, ok := entry.Get(c.headerSource)
+	if !ok {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// readHeader will read the header of a source from an entry. The entry is not sent on.
+func (c *CSVParser) readHeader(ctx context.Context, entry *entry.Entry, source string) error {
+	value, ok := entry.Get(c.ParseFrom)
+	if !ok {
+		err := errors.NewError(
+			"Entry is missing the expected parse_from field.",
+			"Ensure that all incoming entries contain the parse_from field.",
+			"parse_from", c.ParseFrom.String(),
+		)
+		return c.HandleEntryError(ctx, entry, err)
+	}
+
+	columns, err := c.readRecord(value)
+	if err != nil {
+		return c.HandleEntryError(ctx, entry, errors.Wrap(err, "read header"))
+	}
+
+	if err := validateHeader(columns); err != nil {
+		return c.HandleEntryError(ctx, entry, errors.Wrap(err, "read header"))
+	}
+
+	c.headers.set(source, &sourceHeader{line: lineOf(value), columns: columns})
+	return nil
+}
+
+// lineOf will return the string form of a value that is parsed as a line
+func lineOf(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+// parse will parse a value as CSV using the configured header.
+func (c *CSVParser) parse(value interface{}) (interface{}, error) {
+	fields, err := c.readRecord(value)
+	if err != nil {
+		return nil, err
+	}
+	return c.parseFields(fields, c.header)
+}
+
+// parseFields will convert the fields of a CSV record, using the header to name them.
+func (c *CSVParser) parseFields(fields []string, header []string) (interface{}, error) {
+	if len(fields) != len(header) {
+		return nil, errors.NewError(
+			fmt.Sprintf("expected %d fields but got %d", len(header), len(fields)),
+			"ensure that every record has a field for each column in the header",
+		)
+	}
+
+	if c.preserveOrder {
+		parsedValues := entry.NewOrderedMap()
+		for i, column := range header {
+			converted, err := c.convert(column, fields[i])
+			if err != nil {
+				return nil, err
+			}
+			parsedValues.Set(column, converted)
+		}
+		return parsedValues, nil
+	}
+
+	parsedValues := make(map[string]interface{}, len(header))
+	for i, column := range header {
+		converted, err := c.convert(column, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		parsedValues[column] = converted
+	}
+	return parsedValues, nil
+}
+
+// readRecord will read a single CSV record from a value.
+func (c *CSVParser) readRecord(value interface{}) ([]string, error) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed as csv", value)
+	}
+
+	reader := csv.NewReader(strings.NewReader(s))
+	reader.Comma = c.delimiter
+	reader.LazyQuotes = c.lazyQuotes
+	reader.FieldsPerRecord = -1
+
+	fields, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("value does not contain a csv record")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if _, err := reader.Read(); err != io.EOF {
+		return nil, fmt.Errorf("value contains more than one csv record")
+	}
+
+	return fields, nil
+}
+
+// convert will convert the field of a column to the type specified for the column.
+// Empty fields of columns with a type other than string are parsed as nil.
+func (c *CSVParser) convert(column, field string) (interface{}, error) {
+	columnType := c.types[column]
+	if columnType == "" || columnType == stringType {
+		return field, nil
+	}
+
+	if field == "" {
+		return nil, nil
+	}
+
+	var converted interface{}
+	var err error
+	switch columnType {
+	case intType:
+		converted, err = strconv.Atoi(field)
+	case floatType:
+		converted, err = strconv.ParseFloat(field, 64)
+	case boolType:
+		converted, err = strconv.ParseBool(field)
+	}
+
+	if err != nil {
+		return nil, errors.NewError(
+			fmt.Sprintf("failed to convert column '%s' to %s", column, columnType),
+			"ensure that the values of the column match its type",
+			"column", column,
+			"type", columnType,
+			"value", field,
+		)
+	}
+	return converted, nil
+}
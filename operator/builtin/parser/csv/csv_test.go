@@ -0,0 +1,476 @@
+package csv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParser(t *testing.T, cfg *CSVParserConfig) (*CSVParser, *[]*entry.Entry) {
+	op, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser := op.(*CSVParser)
+
+	outputs := []*entry.Entry{}
+	mockOutput := &testutil.Operator{}
+	mockOutput.On("ID").Return("output")
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		outputs = append(outputs, args[1].(*entry.Entry))
+	}).Return(nil)
+	parser.OutputOperators = []operator.Operator{mockOutput}
+
+	return parser, &outputs
+}
+
+func TestCSVImplementations(t *testing.T) {
+	require.Implements(t, (*operator.Operator)(nil), new(CSVParser))
+}
+
+func TestCSVParserConfigBuildFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*CSVParserConfig)
+		expected string
+	}{
+		{
+			"MissingHeader",
+			func(cfg *CSVParserConfig) { cfg.Header = nil },
+			"missing required field 'header'",
+		},
+		{
+			"HeaderAndFirstLine",
+			func(cfg *CSVParserConfig) { cfg.HeaderFromFirstLine = true },
+			"can not be used together",
+		},
+		{
+			"DuplicateColumn",
+			func(cfg *CSVParserConfig) { cfg.Header = []string{"id", "id"} },
+			"header contains the column 'id' more than once",
+		},
+		{
+			"EmptyColumn",
+			func(cfg *CSVParserConfig) { cfg.Header = []string{"id", ""} },
+			"header contains an empty column name",
+		},
+		{
+			"LongDelimiter",
+			func(cfg *CSVParserConfig) { cfg.Delimiter = "||" },
+			"invalid delimiter",
+		},
+		{
+			"QuoteDelimiter",
+			func(cfg *CSVParserConfig) { cfg.Delimiter = `"` },
+			"invalid delimiter",
+		},
+		{
+			"InvalidType",
+			func(cfg *CSVParserConfig) { cfg.Types = map[string]string{"id": "number"} },
+			"invalid type 'number' for column 'id'",
+		},
+		{
+			"TypeUnknownColumn",
+			func(cfg *CSVParserConfig) { cfg.Types = map[string]string{"missing": "int"} },
+			"type specified for column 'missing'",
+		},
+		{
+			"ZeroMaxSources",
+			func(cfg *CSVParserConfig) { cfg.MaxSources = 0 },
+			"'max_sources' must be greater than zero",
+		},
+		{
+			"InvalidOnError",
+			func(cfg *CSVParserConfig) { cfg.OnError = "invalid_on_error" },
+			"invalid `on_error` field",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewCSVParserConfig("test")
+			cfg.Header = []string{"id", "message"}
+			tc.modify(cfg)
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestCSVParser(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*CSVParserConfig)
+		input    interface{}
+		expected interface{}
+	}{
+		{
+			"Simple",
+			func(cfg *CSVParserConfig) {},
+			"1,info,started",
+			map[string]interface{}{"id": "1", "level": "info", "message": "started"},
+		},
+		{
+			"Bytes",
+			func(cfg *CSVParserConfig) {},
+			[]byte("1,info,started"),
+			map[string]interface{}{"id": "1", "level": "info", "message": "started"},
+		},
+		{
+			"QuotedDelimiter",
+			func(cfg *CSVParserConfig) {},
+			`1,info,"started, with a comma"`,
+			map[string]interface{}{"id": "1", "level": "info", "message": "started, with a comma"},
+		},
+		{
+			"QuotedNewline",
+			func(cfg *CSVParserConfig) {},
+			"1,info,\"started\non two lines\"",
+			map[string]interface{}{"id": "1", "level": "info", "message": "started\non two lines"},
+		},
+		{
+			"EscapedQuote",
+			func(cfg *CSVParserConfig) {},
+			`1,info,"started ""quoted"""`,
+			map[string]interface{}{"id": "1", "level": "info", "message": `started "quoted"`},
+		},
+		{
+			"TabDelimiter",
+			func(cfg *CSVParserConfig) { cfg.Delimiter = "\t" },
+			"1\tinfo\tstarted, with a comma",
+			map[string]interface{}{"id": "1", "level": "info", "message": "started, with a comma"},
+		},
+		{
+			"UnicodeDelimiter",
+			func(cfg *CSVParserConfig) { cfg.Delimiter = "¦" },
+			"1¦info¦started",
+			map[string]interface{}{"id": "1", "level": "info", "message": "started"},
+		},
+		{
+			"LazyQuotes",
+			func(cfg *CSVParserConfig) { cfg.LazyQuotes = true },
+			`1,info,started "quoted"`,
+			map[string]interface{}{"id": "1", "level": "info", "message": `started "quoted"`},
+		},
+		{
+			"Types",
+			func(cfg *CSVParserConfig) {
+				cfg.Header = []string{"id", "cost", "paid", "message"}
+				cfg.Types = map[string]string{"id": "int", "cost": "float", "paid": "bool", "message": "string"}
+			},
+			"42,3.50,true,invoice",
+			map[string]interface{}{"id": 42, "cost": 3.5, "paid": true, "message": "invoice"},
+		},
+		{
+			"TypesEmpty",
+			func(cfg *CSVParserConfig) {
+				cfg.Types = map[string]string{"id": "int"}
+			},
+			",info,",
+			map[string]interface{}{"id": nil, "level": "info", "message": ""},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewCSVParserConfig("test")
+			cfg.Header = []string{"id", "level", "message"}
+			tc.modify(cfg)
+			parser, outputs := newTestParser(t, cfg)
+
+			e := entry.New()
+			e.Record = tc.input
+			err := parser.Process(context.Background(), e)
+			require.NoError(t, err)
+			require.Len(t, *outputs, 1)
+			require.Equal(t, tc.expected, (*outputs)[0].Record)
+		})
+	}
+}
+
+func TestCSVParserFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*CSVParserConfig)
+		input    interface{}
+		expected string
+	}{
+		{
+			"TooFewFields",
+			func(cfg *CSVParserConfig) {},
+			"1,info",
+			"expected 3 fields but got 2",
+		},
+		{
+			"TooManyFields",
+			func(cfg *CSVParserConfig) {},
+			"1,info,started,extra",
+			"expected 3 fields but got 4",
+		},
+		{
+			"BareQuote",
+			func(cfg *CSVParserConfig) {},
+			`1,info,started "quoted"`,
+			`bare " in non-quoted-field`,
+		},
+		{
+			"MultipleRecords",
+			func(cfg *CSVParserConfig) {},
+			"1,info,started\n2,info,stopped",
+			"value contains more than one csv record",
+		},
+		{
+			"Empty",
+			func(cfg *CSVParserConfig) {},
+			"",
+			"value does not contain a csv record",
+		},
+		{
+			"InvalidType",
+			func(cfg *CSVParserConfig) {},
+			[]int{},
+			"type '[]int' cannot be parsed as csv",
+		},
+		{
+			"ConversionFailure",
+			func(cfg *CSVParserConfig) { cfg.Types = map[string]string{"id": "int"} },
+			"one,info,started",
+			"failed to convert column 'id' to int",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewCSVParserConfig("test")
+			cfg.Header = []string{"id", "level", "message"}
+			tc.modify(cfg)
+			parser, _ := newTestParser(t, cfg)
+
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestCSVParserPreserveOrder(t *testing.T) {
+	cfg := NewCSVParserConfig("test")
+	cfg.Header = []string{"zebra", "apple", "mango"}
+	cfg.PreserveOrder = true
+	parser, _ := newTestParser(t, cfg)
+
+	parsed, err := parser.parse("1,2,3")
+	require.NoError(t, err)
+	orderedMap, ok := parsed.(*entry.OrderedMap)
+	require.True(t, ok)
+	require.Equal(t, []string{"zebra", "apple", "mango"}, orderedMap.Keys())
+}
+
+func TestCSVParserHeaderFromFirstLine(t *testing.T) {
+	cfg := NewCSVParserConfig("test")
+	cfg.HeaderFromFirstLine = true
+	cfg.Types = map[string]string{"amount": "float"}
+	parser, outputs := newTestParser(t, cfg)
+
+	lines := []struct {
+		file string
+		line string
+	}{
+		{"/var/log/billing.csv", "customer,amount"},
+		{"/var/log/app.csv", "level;message"},
+		{"/var/log/billing.csv", "acme,12.5"},
+		{"/var/log/app.csv", "info;started"},
+		// A rotated file repeats its header
+		{"/var/log/billing.csv", "customer,amount"},
+		{"/var/log/billing.csv", "globex,7"},
+		// A file with the same name in another directory has its own header
+		{"/var/log/archive/billing.csv", "amount,customer,region"},
+		{"/var/log/archive/billing.csv", "3.5,initech,eu"},
+	}
+
+	for _, l := range lines {
+		e := entry.New()
+		e.AddLabel("file_path", l.file)
+		e.Record = l.line
+		err := parser.Process(context.Background(), e)
+		require.NoError(t, err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{"customer": "acme", "amount": 12.5},
+		map[string]interface{}{"level;message": "info;started"},
+		map[string]interface{}{"customer": "globex", "amount": float64(7)},
+		map[string]interface{}{"amount": 3.5, "customer": "initech", "region": "eu"},
+	}
+
+	records := make([]interface{}, 0, len(*outputs))
+	for _, output := range *outputs {
+		records = append(records, output.Record)
+	}
+	require.Equal(t, expected, records)
+}
+
+func TestCSVParserHeaderFromFirstLineFailure(t *testing.T) {
+	cfg := NewCSVParserConfig("test")
+	cfg.HeaderFromFirstLine = true
+	cfg.OnError = "drop"
+	parser, outputs := newTestParser(t, cfg)
+
+	e := entry.New()
+	e.Record = "id,id"
+	err := parser.Process(context.Background(), e)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "header contains the column 'id' more than once")
+
+	// The header is read from the next line, since the invalid header was not kept
+	e = entry.New()
+	e.Record = "id,message"
+	err = parser.Process(context.Background(), e)
+	require.NoError(t, err)
+
+	e = entry.New()
+	e.Record = "1,started"
+	err = parser.Process(context.Background(), e)
+	require.NoError(t, err)
+
+	require.Len(t, *outputs, 1)
+	require.Equal(t, map[string]interface{}{"id": "1", "message": "started"}, (*outputs)[0].Record)
+}
+
+func TestCSVParserHeaderFromFirstLineIf(t *testing.T) {
+	cfg := NewCSVParserConfig("test")
+	cfg.HeaderFromFirstLine = true
+	cfg.IfExpr = `$labels.file_name == "billing.csv"`
+	parser, outputs := newTestParser(t, cfg)
+
+	// Entries that are skipped are not used as the header
+	e := entry.New()
+	e.AddLabel("file_name", "other.log")
+	e.Record = "not a header"
+	err := parser.Process(context.Background(), e)
+	require.NoError(t, err)
+	require.Len(t, *outputs, 1)
+	require.Equal(t, "not a header", (*outputs)[0].Record)
+
+	require.Zero(t, parser.headers.len())
+}
+
+// processLines will process lines from a single source, returning the errors of the lines
+func processLines(t *testing.T, parser *CSVParser, lines ...string) []error {
+	errs := make([]error, 0, len(lines))
+	for _, line := range lines {
+		e := entry.New()
+		e.AddLabel("file_path", "/var/log/billing.csv")
+		e.Record = line
+		errs = append(errs, parser.Process(context.Background(), e))
+	}
+	return errs
+}
+
+func outputRecords(outputs []*entry.Entry) []interface{} {
+	records := make([]interface{}, 0, len(outputs))
+	for _, output := range outputs {
+		records = append(records, output.Record)
+	}
+	return records
+}
+
+func TestCSVParserHeaderFromFirstLineChanged(t *testing.T) {
+	cfg := NewCSVParserConfig("test")
+	cfg.HeaderFromFirstLine = true
+	parser, outputs := newTestParser(t, cfg)
+
+	// A rotated file that starts with a different header replaces the header of its source
+	errs := processLines(t, parser,
+		"customer,amount",
+		"acme,12.5",
+		"customer,amount,currency",
+		"globex,7,EUR",
+	)
+	require.Equal(t, []error{nil, nil, nil, nil}, errs)
+
+	expected := []interface{}{
+		map[string]interface{}{"customer": "acme", "amount": "12.5"},
+		map[string]interface{}{"customer": "globex", "amount": "7", "currency": "EUR"},
+	}
+	require.Equal(t, expected, outputRecords(*outputs))
+}
+
+func TestCSVParserHeaderFromFirstLineRestored(t *testing.T) {
+	cfg := NewCSVParserConfig("test")
+	cfg.HeaderFromFirstLine = true
+	parser, outputs := newTestParser(t, cfg)
+
+	// A record with an extra field is read as a header, but the header it replaced
+	// is restored when the records that follow match it instead
+	errs := processLines(t, parser,
+		"customer,amount",
+		"acme,12.5",
+		"initech,3,extra",
+		"globex,7",
+		"hooli,4",
+	)
+	require.Equal(t, []error{nil, nil, nil, nil, nil}, errs)
+
+	expected := []interface{}{
+		map[string]interface{}{"customer": "acme", "amount": "12.5"},
+		map[string]interface{}{"customer": "globex", "amount": "7"},
+		map[string]interface{}{"customer": "hooli", "amount": "4"},
+	}
+	require.Equal(t, expected, outputRecords(*outputs))
+
+	header, ok := parser.headers.get("/var/log/billing.csv")
+	require.True(t, ok)
+	require.Equal(t, []string{"customer", "amount"}, header.columns)
+}
+
+func TestCSVParserHeaderFromFirstLineNotReplacedTwice(t *testing.T) {
+	cfg := NewCSVParserConfig("test")
+	cfg.HeaderFromFirstLine = true
+	cfg.OnError = "drop"
+	parser, outputs := newTestParser(t, cfg)
+
+	// A header that was not used for a record is not replaced
+	errs := processLines(t, parser,
+		"customer,amount",
+		"acme,12.5",
+		"customer,amount,currency",
+		"globex,7,EUR,extra",
+	)
+	require.NoError(t, errs[2])
+	require.Error(t, errs[3])
+	require.Contains(t, errs[3].Error(), "expected 3 fields but got 4")
+	require.Len(t, *outputs, 1)
+
+	header, ok := parser.headers.get("/var/log/billing.csv")
+	require.True(t, ok)
+	require.Equal(t, []string{"customer", "amount", "currency"}, header.columns)
+}
+
+func TestCSVParserHeaderFromFirstLineMaxSources(t *testing.T) {
+	cfg := NewCSVParserConfig("test")
+	cfg.HeaderFromFirstLine = true
+	cfg.MaxSources = 2
+	parser, _ := newTestParser(t, cfg)
+
+	for _, source := range []string{"/a.csv", "/b.csv", "/a.csv", "/c.csv"} {
+		e := entry.New()
+		e.AddLabel("file_path", source)
+		e.Record = "id,message"
+		require.NoError(t, parser.Process(context.Background(), e))
+	}
+
+	// The least recently used source is removed
+	require.Equal(t, 2, parser.headers.len())
+	_, ok := parser.headers.get("/b.csv")
+	require.False(t, ok)
+	_, ok = parser.headers.get("/a.csv")
+	require.True(t, ok)
+	_, ok = parser.headers.get("/c.csv")
+	require.True(t, ok)
+}
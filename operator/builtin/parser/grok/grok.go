@@ -0,0 +1,338 @@
+package grok
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("grok_parser", func() operator.Builder { return NewGrokParserConfig("") })
+}
+
+const (
+	intType   = "int"
+	floatType = "float"
+)
+
+// NewGrokParserConfig creates a new grok parser config with default values
+func NewGrokParserConfig(operatorID string) *GrokParserConfig {
+	return &GrokParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "grok_parser"),
+	}
+}
+
+// GrokParserConfig is the configuration of a grok parser operator.
+type GrokParserConfig struct {
+	helper.ParserConfig `yaml:",inline"`
+
+	Pattern            string            `json:"pattern"                       yaml:"pattern"`
+	PatternDefinitions map[string]string `json:"pattern_definitions,omitempty" yaml:"pattern_definitions,omitempty"`
+	PatternFiles       []string          `json:"pattern_files,omitempty"       yaml:"pattern_files,omitempty"`
+}
+
+// Build will build a grok parser operator.
+func (c GrokParserConfig) Build(context operator.BuildContext) (operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Pattern == "" {
+		return nil, fmt.Errorf("missing required field 'pattern'")
+	}
+
+	patterns, err := readPatterns(strings.NewReader(defaultPatterns))
+	if err != nil {
+		return nil, errors.Wrap(err, "read default patterns")
+	}
+
+	for _, glob := range c.PatternFiles {
+		if err := readPatternFiles(glob, patterns); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, definition := range c.PatternDefinitions {
+		patterns[name] = definition
+	}
+
+	compiler := &grokCompiler{patterns: patterns}
+	expanded, err := compiler.expand(c.Pattern, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, errors.NewError(
+			fmt.Sprintf("compiling grok pattern: %s", err),
+			"ensure that the pattern and the patterns it references are valid Go regular expressions",
+		)
+	}
+
+	if len(compiler.fields) == 0 {
+		return nil, errors.NewError(
+			"no fields in grok pattern",
+			"use references like '%{WORD:my_key}' to specify the key name for the parsed field",
+		)
+	}
+
+	// Map the capture groups of the regular expression to the fields they parse
+	fields := make([]*grokField, len(r.SubexpNames()))
+	for i, group := range r.SubexpNames() {
+		for j := range compiler.fields {
+			if compiler.fields[j].group == group {
+				fields[i] = &compiler.fields[j]
+			}
+		}
+	}
+
+	grokParser := &GrokParser{
+		ParserOperator: parserOperator,
+		regexp:         r,
+		fields:         fields,
+	}
+
+	return grokParser, nil
+}
+
+// readPatternFiles will read the pattern files that match a glob into the patterns
+func readPatternFiles(glob string, patterns map[string]string) error {
+	paths, err := filepath.Glob(glob)
+	if err != nil {
+		return errors.Wrap(err, "find pattern files").WithDetails("pattern_files", glob)
+	}
+	if len(paths) == 0 {
+		return errors.NewError(
+			"no pattern files found",
+			"ensure that the pattern_files exist and are readable by the agent",
+			"pattern_files", glob,
+		)
+	}
+
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return errors.Wrap(err, "open pattern file").WithDetails("path", path)
+		}
+		filePatterns, err := readPatterns(file)
+		file.Close()
+		if err != nil {
+			return errors.Wrap(err, "read pattern file").WithDetails("path", path)
+		}
+		for name, definition := range filePatterns {
+			patterns[name] = definition
+		}
+	}
+	return nil
+}
+
+// readPatterns will read patterns in the format of grok pattern files. Each line has the
+// name of a pattern and its definition separated by whitespace, and lines starting with # are comments.
+func readPatterns(reader io.Reader) (map[string]string, error) {
+	patterns := map[string]string{}
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("line %d is missing a pattern definition", lineNumber)
+		}
+		patterns[parts[0]] = strings.TrimSpace(parts[1])
+	}
+	return patterns, scanner.Err()
+}
+
+// grokReference matches references like %{NAME}, %{NAME:field} and %{NAME:field:type}
+var grokReference = regexp.MustCompile(`%\{(\w+)(?::([^:}]+))?(?::(\w+))?\}`)
+
+// namedGroup matches named capture groups written as (?P<name> or (?<name>
+var namedGroup = regexp.MustCompile(`\(\?P?<(\w+)>`)
+
+// grokField is a field parsed by a capture group of a grok pattern
+type grokField struct {
+	group     string
+	name      string
+	fieldType string
+}
+
+// grokCompiler expands grok patterns into regular expressions
+type grokCompiler struct {
+	patterns map[string]string
+	fields   []grokField
+}
+
+// expand will replace the references in a pattern with the patterns they reference.
+// References with a field name, and named capture groups, are replaced with capture
+// groups named after the index of the field.
+func (g *grokCompiler) expand(pattern string, stack []string) (string, error) {
+	var b strings.Builder
+	last := 0
+	for _, match := range grokReference.FindAllStringSubmatchIndex(pattern, -1) {
+		b.WriteString(g.renameGroups(pattern[last:match[0]]))
+		last = match[1]
+
+		name := pattern[match[2]:match[3]]
+		definition, ok := g.patterns[name]
+		if !ok {
+			return "", errors.NewError(
+				fmt.Sprintf("grok pattern %%{%s} is not defined", name),
+				"define the pattern in pattern_definitions or pattern_files",
+				"pattern", name,
+			)
+		}
+
+		for _, parent := range stack {
+			if parent == name {
+				return "", errors.NewError(
+					fmt.Sprintf("grok pattern %%{%s} references itself", name),
+					"ensure that patterns do not reference themselves directly or through other patterns",
+					"pattern", name,
+				)
+			}
+		}
+
+		expanded, err := g.expand(definition, append(stack, name))
+		if err != nil {
+			return "", err
+		}
+
+		if match[4] == -1 {
+			b.WriteString("(?:" + expanded + ")")
+			continue
+		}
+
+		fieldType := ""
+		if match[6] != -1 {
+			fieldType = pattern[match[6]:match[7]]
+		}
+		switch fieldType {
+		case "", intType, floatType:
+		default:
+			return "", errors.NewError(
+				fmt.Sprintf("invalid type '%s' for grok field '%s'", fieldType, pattern[match[4]:match[5]]),
+				fmt.Sprintf("specify a type of %s or %s", intType, floatType),
+			)
+		}
+
+		group := g.addField(pattern[match[4]:match[5]], fieldType)
+		b.WriteString("(?P<" + group + ">" + expanded + ")")
+	}
+	b.WriteString(g.renameGroups(pattern[last:]))
+
+	return b.String(), nil
+}
+
+// renameGroups will rename the named capture groups in a part of a pattern
+func (g *grokCompiler) renameGroups(pattern string) string {
+	return namedGroup.ReplaceAllStringFunc(pattern, func(match string) string {
+		name := namedGroup.FindStringSubmatch(match)[1]
+		return "(?P<" + g.addField(name, "") + ">"
+	})
+}
+
+// addField will add a field and return the name of its capture group
+func (g *grokCompiler) addField(name, fieldType string) string {
+	group := "field" + strconv.Itoa(len(g.fields))
+	g.fields = append(g.fields, grokField{group: group, name: name, fieldType: fieldType})
+	return group
+}
+
+// GrokParser is an operator that parses grok patterns in an entry.
+type GrokParser struct {
+	helper.ParserOperator
+	regexp *regexp.Regexp
+
+	// fields holds the field parsed by each capture group of the regular expression
+	fields []*grokField
+}
+
+// Process will parse an entry with the grok pattern.
+func (g *GrokParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return g.ParserOperator.ProcessWith(ctx, entry, g.parse)
+}
+
+// parse will parse a value using the grok pattern.
+// Fields that are empty or not matched are not included in the parsed value.
+func (g *GrokParser) parse(value interface{}) (interface{}, error) {
+	var matches []string
+	switch m := value.(type) {
+	case string:
+		matches = g.regexp.FindStringSubmatch(m)
+	case []byte:
+		byteMatches := g.regexp.FindSubmatch(m)
+		if byteMatches != nil {
+			matches = make([]string, len(byteMatches))
+			for i, byteSlice := range byteMatches {
+				matches[i] = string(byteSlice)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed as grok", value)
+	}
+
+	if matches == nil {
+		return nil, fmt.Errorf("grok pattern does not match")
+	}
+
+	parsedValues := map[string]interface{}{}
+	for i, match := range matches {
+		field := g.fields[i]
+		if field == nil || match == "" {
+			continue
+		}
+
+		// The first match of a field that appears more than once in the pattern is kept
+		if _, ok := parsedValues[field.name]; ok {
+			continue
+		}
+
+		converted, err := convert(field, match)
+		if err != nil {
+			return nil, err
+		}
+		parsedValues[field.name] = converted
+	}
+
+	return parsedValues, nil
+}
+
+// convert will convert the value of a field to its type
+func convert(field *grokField, value string) (interface{}, error) {
+	var converted interface{}
+	var err error
+	switch field.fieldType {
+	case intType:
+		converted, err = strconv.Atoi(value)
+	case floatType:
+		converted, err = strconv.ParseFloat(value, 64)
+	default:
+		return value, nil
+	}
+
+	if err != nil {
+		return nil, errors.NewError(
+			fmt.Sprintf("failed to convert field '%s' to %s", field.name, field.fieldType),
+			"ensure that the pattern of the field only matches values of its type",
+			"field", field.name,
+			"value", value,
+		)
+	}
+	return converted, nil
+}
@@ -0,0 +1,355 @@
+package grok
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParser(t *testing.T, pattern string, modify func(*GrokParserConfig)) (*GrokParser, error) {
+	cfg := NewGrokParserConfig("test")
+	cfg.Pattern = pattern
+	modify(cfg)
+	op, err := cfg.Build(testutil.NewBuildContext(t))
+	if err != nil {
+		return nil, err
+	}
+	return op.(*GrokParser), nil
+}
+
+func TestGrokImplementations(t *testing.T) {
+	require.Implements(t, (*operator.Operator)(nil), new(GrokParser))
+}
+
+func TestGrokDefaultPatternsCompile(t *testing.T) {
+	patterns, err := readPatterns(strings.NewReader(defaultPatterns))
+	require.NoError(t, err)
+	require.True(t, len(patterns) > 50)
+
+	for name := range patterns {
+		t.Run(name, func(t *testing.T) {
+			compiler := &grokCompiler{patterns: patterns}
+			expanded, err := compiler.expand("%{"+name+"}", nil)
+			require.NoError(t, err)
+			_, err = regexp.Compile(expanded)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGrokPatterns(t *testing.T) {
+	cases := []struct {
+		name     string
+		pattern  string
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"IPv4",
+			`%{IP:ip}`,
+			`connection from 10.1.255.3 closed`,
+			map[string]interface{}{"ip": "10.1.255.3"},
+		},
+		{
+			"IPv6",
+			`%{IP:ip}`,
+			`connection from 2001:db8::ff00:42:8329 closed`,
+			map[string]interface{}{"ip": "2001:db8::ff00:42:8329"},
+		},
+		{
+			"IPOrHost",
+			`^%{IPORHOST:host}:%{POSINT:port:int}$`,
+			`db-1.example.com:5432`,
+			map[string]interface{}{"host": "db-1.example.com", "port": 5432},
+		},
+		{
+			"HTTPDate",
+			`\[%{HTTPDATE:time}\]`,
+			`[10/Oct/2000:13:55:36 -0700]`,
+			map[string]interface{}{"time": "10/Oct/2000:13:55:36 -0700"},
+		},
+		{
+			"ISO8601",
+			`^%{TIMESTAMP_ISO8601:time} %{LOGLEVEL:level} %{GREEDYDATA:message}$`,
+			`2020-09-24T13:05:09.123Z WARN disk almost full`,
+			map[string]interface{}{"time": "2020-09-24T13:05:09.123Z", "level": "WARN", "message": "disk almost full"},
+		},
+		{
+			"UUIDAndQuotedString",
+			`request %{UUID:id} %{QS:query}`,
+			`request 123e4567-e89b-12d3-a456-426614174000 "select \"a\""`,
+			map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174000", "query": `"select \"a\""`},
+		},
+		{
+			"URI",
+			`%{URI:url}`,
+			`fetching https://user@example.com:8443/a/b?c=d failed`,
+			map[string]interface{}{"url": "https://user@example.com:8443/a/b?c=d", "port": "8443"},
+		},
+		{
+			"SyslogBase",
+			`^%{SYSLOGBASE} %{GREEDYDATA:message}$`,
+			`Sep 24 13:05:09 web-1 sshd[4242]: Accepted publickey for bob`,
+			map[string]interface{}{
+				"timestamp": "Sep 24 13:05:09",
+				"logsource": "web-1",
+				"program":   "sshd",
+				"pid":       "4242",
+				"message":   "Accepted publickey for bob",
+			},
+		},
+		{
+			"CombinedApacheLog",
+			`^%{COMBINEDAPACHELOG}$`,
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+			map[string]interface{}{
+				"clientip":    "127.0.0.1",
+				"ident":       "-",
+				"auth":        "frank",
+				"timestamp":   "10/Oct/2000:13:55:36 -0700",
+				"verb":        "GET",
+				"request":     "/apache_pb.gif",
+				"httpversion": "1.0",
+				"response":    "200",
+				"bytes":       "2326",
+				"referrer":    `"http://www.example.com/start.html"`,
+				"agent":       `"Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+			},
+		},
+		{
+			"TypeSuffixes",
+			`took %{NUMBER:duration:float}ms, %{INT:retries:int} retries, status %{INT:status}`,
+			`took 12.5ms, -1 retries, status 200`,
+			map[string]interface{}{"duration": 12.5, "retries": -1, "status": "200"},
+		},
+		{
+			"FieldNamesWithSymbols",
+			`%{WORD:http.method} %{NOTSPACE:[url][path]}`,
+			`GET /index.html`,
+			map[string]interface{}{"http.method": "GET", "[url][path]": "/index.html"},
+		},
+		{
+			"NamedGroups",
+			`%{WORD:method} (?P<path>\S+) (?<version>HTTP/\d\.\d)`,
+			`GET /index.html HTTP/1.1`,
+			map[string]interface{}{"method": "GET", "path": "/index.html", "version": "HTTP/1.1"},
+		},
+		{
+			"UnmatchedFieldsOmitted",
+			`%{WORD:verb}(?: %{NUMBER:code})?(?: %{GREEDYDATA:rest})?`,
+			`GET`,
+			map[string]interface{}{"verb": "GET"},
+		},
+		{
+			"RepeatedFieldFirstMatch",
+			`(?:%{INT:value}|%{WORD:value}) %{WORD:value}`,
+			`hello world`,
+			map[string]interface{}{"value": "hello"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, err := newTestParser(t, tc.pattern, func(*GrokParserConfig) {})
+			require.NoError(t, err)
+
+			var output *entry.Entry
+			mockOutput := &testutil.Operator{}
+			mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				output = args[1].(*entry.Entry)
+			}).Return(nil)
+			parser.OutputOperators = []operator.Operator{mockOutput}
+
+			e := entry.New()
+			e.Record = tc.input
+			err = parser.Process(context.Background(), e)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, output.Record)
+
+			parsed, err := parser.parse([]byte(tc.input))
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestGrokPatternDefinitions(t *testing.T) {
+	parser, err := newTestParser(t, `%{REQUEST_ID:request_id} %{GREEDYDATA:message}`, func(cfg *GrokParserConfig) {
+		cfg.PatternDefinitions = map[string]string{
+			"REQUEST_ID": `req-%{INT}`,
+			// Definitions override the default patterns
+			"GREEDYDATA": `[a-z ]+`,
+		}
+	})
+	require.NoError(t, err)
+
+	parsed, err := parser.parse("req-42 started server 1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"request_id": "req-42", "message": "started server "}, parsed)
+}
+
+func TestGrokPatternFiles(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "grok")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	files := map[string]string{
+		"app": "# application patterns\n\nAPP_LEVEL (?:TRACE|INFO|FAIL)\nAPP_LINE %{APP_LEVEL:level} %{APP_MESSAGE:message}\n",
+		"msg": "APP_MESSAGE .+\n",
+	}
+	for name, contents := range files {
+		err := ioutil.WriteFile(filepath.Join(tempDir, name), []byte(contents), 0600)
+		require.NoError(t, err)
+	}
+
+	parser, err := newTestParser(t, `^%{APP_LINE}$`, func(cfg *GrokParserConfig) {
+		cfg.PatternFiles = []string{filepath.Join(tempDir, "*")}
+	})
+	require.NoError(t, err)
+
+	parsed, err := parser.parse("FAIL lost connection")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"level": "FAIL", "message": "lost connection"}, parsed)
+}
+
+func TestGrokParserConfigBuildFailure(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "grok")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	invalidFile := filepath.Join(tempDir, "invalid")
+	err = ioutil.WriteFile(invalidFile, []byte("NAME_ONLY\n"), 0600)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		pattern  string
+		modify   func(*GrokParserConfig)
+		expected string
+	}{
+		{
+			"MissingPattern",
+			"",
+			func(*GrokParserConfig) {},
+			"missing required field 'pattern'",
+		},
+		{
+			"UndefinedPattern",
+			"%{MISSING:field}",
+			func(*GrokParserConfig) {},
+			"grok pattern %{MISSING} is not defined",
+		},
+		{
+			"RecursivePattern",
+			"%{A:field}",
+			func(cfg *GrokParserConfig) {
+				cfg.PatternDefinitions = map[string]string{"A": "a%{B}", "B": "b%{A}"}
+			},
+			"grok pattern %{A} references itself",
+		},
+		{
+			"InvalidType",
+			"%{INT:field:bool}",
+			func(*GrokParserConfig) {},
+			"invalid type 'bool' for grok field 'field'",
+		},
+		{
+			"InvalidRegex",
+			"%{BROKEN:field}",
+			func(cfg *GrokParserConfig) {
+				cfg.PatternDefinitions = map[string]string{"BROKEN": "(?<!a)b"}
+			},
+			"compiling grok pattern",
+		},
+		{
+			"NoFields",
+			"%{INT} %{WORD}",
+			func(*GrokParserConfig) {},
+			"no fields in grok pattern",
+		},
+		{
+			"MissingPatternFiles",
+			"%{INT:field}",
+			func(cfg *GrokParserConfig) {
+				cfg.PatternFiles = []string{filepath.Join(tempDir, "missing*")}
+			},
+			"no pattern files found",
+		},
+		{
+			"InvalidPatternFile",
+			"%{INT:field}",
+			func(cfg *GrokParserConfig) {
+				cfg.PatternFiles = []string{invalidFile}
+			},
+			"line 1 is missing a pattern definition",
+		},
+		{
+			"InvalidOnError",
+			"%{INT:field}",
+			func(cfg *GrokParserConfig) { cfg.OnError = "invalid_on_error" },
+			"invalid `on_error` field",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newTestParser(t, tc.pattern, tc.modify)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestGrokParserFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		pattern  string
+		input    interface{}
+		expected string
+	}{
+		{
+			"NoMatch",
+			`^%{INT:value}$`,
+			"abc",
+			"grok pattern does not match",
+		},
+		{
+			"NoMatchBytes",
+			`^%{INT:value}$`,
+			[]byte("abc"),
+			"grok pattern does not match",
+		},
+		{
+			"ConversionFailure",
+			`%{NUMBER:value:int}`,
+			"1.5",
+			"failed to convert field 'value' to int",
+		},
+		{
+			"InvalidType",
+			`%{INT:value}`,
+			[]int{},
+			"type '[]int' cannot be parsed as grok",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser, err := newTestParser(t, tc.pattern, func(*GrokParserConfig) {})
+			require.NoError(t, err)
+			_, err = parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
@@ -24,6 +24,8 @@ func NewJSONParserConfig(operatorID string) *JSONParserConfig {
 // JSONParserConfig is the configuration of a JSON parser operator.
 type JSONParserConfig struct {
 	helper.ParserConfig `yaml:",inline"`
+
+	PreserveOrder bool `json:"preserve_order" yaml:"preserve_order"`
 }
 
 // Build will build a JSON parser operator.
@@ -36,6 +38,7 @@ func (c JSONParserConfig) Build(context operator.BuildContext) (operator.Operato
 	jsonParser := &JSONParser{
 		ParserOperator: parserOperator,
 		json:           jsoniter.ConfigFastest,
+		preserveOrder:  c.PreserveOrder,
 	}
 
 	return jsonParser, nil
@@ -44,7 +47,8 @@ func (c JSONParserConfig) Build(context operator.BuildContext) (operator.Operato
 // JSONParser is an operator that parses JSON.
 type JSONParser struct {
 	helper.ParserOperator
-	json jsoniter.API
+	json          jsoniter.API
+	preserveOrder bool
 }
 
 // Process will parse an entry for JSON.
@@ -54,6 +58,10 @@ func (j *JSONParser) Process(ctx context.Context, entry *entry.Entry) error {
 
 // parse will parse a value as JSON.
 func (j *JSONParser) parse(value interface{}) (interface{}, error) {
+	if j.preserveOrder {
+		return j.parseOrdered(value)
+	}
+
 	var parsedValue map[string]interface{}
 	switch m := value.(type) {
 	case string:
@@ -71,3 +79,23 @@ func (j *JSONParser) parse(value interface{}) (interface{}, error) {
 	}
 	return parsedValue, nil
 }
+
+// parseOrdered will parse a value as JSON into an ordered map.
+func (j *JSONParser) parseOrdered(value interface{}) (interface{}, error) {
+	parsedValue := entry.NewOrderedMap()
+	switch m := value.(type) {
+	case string:
+		err := parsedValue.UnmarshalJSON([]byte(m))
+		if err != nil {
+			return nil, err
+		}
+	case []byte:
+		err := parsedValue.UnmarshalJSON(m)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("type %T cannot be parsed as JSON", value)
+	}
+	return parsedValue, nil
+}
@@ -2,6 +2,7 @@ package json
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -253,3 +254,73 @@ func TestJSONParserWithEmbeddedTimeParser(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONParserPreserveOrder(t *testing.T) {
+	parser, mockOutput := NewFakeJSONOperator()
+	parser.preserveOrder = true
+
+	var output *entry.Entry
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		output = args[1].(*entry.Entry)
+	}).Return(nil)
+
+	input := entry.New()
+	input.Record = map[string]interface{}{
+		"testfield": `{"zebra":1,"apple":{"yak":"a","bee":"b"},"mango":[1,2]}`,
+	}
+	err := parser.Process(context.Background(), input)
+	require.NoError(t, err)
+
+	parsed, ok := output.Get(entry.NewRecordField("testparsed"))
+	require.True(t, ok)
+	orderedMap, ok := parsed.(*entry.OrderedMap)
+	require.True(t, ok)
+	require.Equal(t, []string{"zebra", "apple", "mango"}, orderedMap.Keys())
+
+	marshalled, err := json.Marshal(output.Record)
+	require.NoError(t, err)
+	require.Equal(t, `{"testparsed":{"zebra":1,"apple":{"yak":"a","bee":"b"},"mango":[1,2]}}`, string(marshalled))
+}
+
+func TestJSONParserPreserveOrderFailure(t *testing.T) {
+	parser, _ := NewFakeJSONOperator()
+	parser.preserveOrder = true
+
+	_, err := parser.parse("invalid")
+	require.Error(t, err)
+
+	_, err = parser.parse(`["not", "an", "object"]`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected a JSON object")
+
+	_, err = parser.parse([]int{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "type []int cannot be parsed as JSON")
+}
+
+const benchmarkJSON = `{"time":"2020-09-24T13:05:09Z","level":"info","msg":"request completed","method":"GET",` +
+	`"path":"/api/v1/users","status":"200","dur":"12ms","bytes":"5120","user_agent":"Mozilla/5.0 (X11; Linux x86_64)"}`
+
+func BenchmarkJSONParser(b *testing.B) {
+	cases := []struct {
+		name          string
+		preserveOrder bool
+	}{
+		{"Default", false},
+		{"PreserveOrder", true},
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			parser, _ := NewFakeJSONOperator()
+			parser.preserveOrder = tc.preserveOrder
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.parse(benchmarkJSON); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package keyvalue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("key_value_parser", func() operator.Builder { return NewKeyValueParserConfig("") })
+}
+
+const (
+	// duplicate key handling
+	keepFirst   = "first"
+	keepLast    = "last"
+	collect     = "array"
+	rejectDupes = "error"
+
+	// bare key handling
+	bareBool  = "bool"
+	bareEmpty = "empty"
+	bareDrop  = "drop"
+	bareError = "error"
+)
+
+// NewKeyValueParserConfig creates a new key value parser config with default values
+func NewKeyValueParserConfig(operatorID string) *KeyValueParserConfig {
+	return &KeyValueParserConfig{
+		ParserConfig:  helper.NewParserConfig(operatorID, "key_value_parser"),
+		Delimiter:     "=",
+		DuplicateKeys: keepLast,
+		BareKeys:      bareBool,
+	}
+}
+
+// KeyValueParserConfig is the configuration of a key value parser operator.
+type KeyValueParserConfig struct {
+	helper.ParserConfig `yaml:",inline"`
+
+	Delimiter     string `json:"delimiter,omitempty"      yaml:"delimiter,omitempty"`
+	PairDelimiter string `json:"pair_delimiter,omitempty" yaml:"pair_delimiter,omitempty"`
+	DuplicateKeys string `json:"duplicate_keys,omitempty" yaml:"duplicate_keys,omitempty"`
+	BareKeys      string `json:"bare_keys,omitempty"      yaml:"bare_keys,omitempty"`
+	PreserveOrder bool   `json:"preserve_order,omitempty" yaml:"preserve_order,omitempty"`
+}
+
+// Build will build a key value parser operator.
+func (c KeyValueParserConfig) Build(context operator.BuildContext) (operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Delimiter == "" {
+		return nil, fmt.Errorf("missing required field 'delimiter'")
+	}
+
+	if c.Delimiter == c.PairDelimiter {
+		return nil, errors.NewError(
+			"delimiter and pair_delimiter can not be the same",
+			"specify a pair_delimiter that is different from the delimiter",
+			"delimiter", c.Delimiter,
+		)
+	}
+
+	if strings.ContainsRune(c.Delimiter, '"') || strings.ContainsRune(c.PairDelimiter, '"') {
+		return nil, errors.NewError(
+			"delimiters can not contain quotes",
+			"specify delimiters without quotes, which are used to quote values",
+		)
+	}
+
+	switch c.DuplicateKeys {
+	case keepFirst, keepLast, collect, rejectDupes:
+	default:
+		return nil, errors.NewError(
+			fmt.Sprintf("invalid duplicate_keys '%s'", c.DuplicateKeys),
+			fmt.Sprintf("specify one of %s, %s, %s or %s", keepFirst, keepLast, collect, rejectDupes),
+		)
+	}
+
+	switch c.BareKeys {
+	case bareBool, bareEmpty, bareDrop, bareError:
+	default:
+		return nil, errors.NewError(
+			fmt.Sprintf("invalid bare_keys '%s'", c.BareKeys),
+			fmt.Sprintf("specify one of %s, %s, %s or %s", bareBool, bareEmpty, bareDrop, bareError),
+		)
+	}
+
+	keyValueParser := &KeyValueParser{
+		ParserOperator: parserOperator,
+		delimiter:      c.Delimiter,
+		pairDelimiter:  c.PairDelimiter,
+		duplicateKeys:  c.DuplicateKeys,
+		bareKeys:       c.BareKeys,
+		preserveOrder:  c.PreserveOrder,
+	}
+
+	return keyValueParser, nil
+}
+
+// KeyValueParser is an operator that parses key value pairs.
+type KeyValueParser struct {
+	helper.ParserOperator
+	delimiter     string
+	pairDelimiter string
+	duplicateKeys string
+	bareKeys      string
+	preserveOrder bool
+}
+
+// Process will parse an entry for key value pairs.
+func (kv *KeyValueParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return kv.ParserOperator.ProcessWith(ctx, entry, kv.parse)
+}
+
+// parse will parse a value as key value pairs.
+func (kv *KeyValueParser) parse(value interface{}) (interface{}, error) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed as key value pairs", value)
+	}
+
+	var parsedValues parsedMap
+	if kv.preserveOrder {
+		parsedValues = orderedParsedMap{entry.NewOrderedMap()}
+	} else {
+		parsedValues = plainParsedMap{}
+	}
+
+	for rest := kv.skipPairDelimiters(s); rest != ""; rest = kv.skipPairDelimiters(rest) {
+		var key string
+		var value interface{}
+		var err error
+
+		key, value, rest, err = kv.readPair(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "" {
+			continue
+		}
+
+		if value == nil {
+			switch kv.bareKeys {
+			case bareDrop:
+				continue
+			case bareError:
+				return nil, errors.NewError(
+					fmt.Sprintf("key '%s' does not have a value", key),
+					"ensure that every key is followed by the delimiter and a value, or configure bare_keys",
+					"key", key,
+				)
+			case bareEmpty:
+				value = ""
+			default:
+				value = true
+			}
+		}
+
+		if err := kv.add(parsedValues, key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return parsedValues.value(), nil
+}
+
+// add will add a key and value to the parsed values, handling duplicate keys as configured
+func (kv *KeyValueParser) add(parsedValues parsedMap, key string, value interface{}) error {
+	existing, ok := parsedValues.get(key)
+	if !ok {
+		parsedValues.set(key, value)
+		return nil
+	}
+
+	switch kv.duplicateKeys {
+	case keepFirst:
+	case collect:
+		if values, ok := existing.([]interface{}); ok {
+			parsedValues.set(key, append(values, value))
+		} else {
+			parsedValues.set(key, []interface{}{existing, value})
+		}
+	case rejectDupes:
+		return errors.NewError(
+			fmt.Sprintf("duplicate key '%s'", key),
+			"ensure that every key appears once, or configure duplicate_keys",
+			"key", key,
+		)
+	default:
+		parsedValues.set(key, value)
+	}
+	return nil
+}
+
+// readPair will read a key and its value from the start of s, returning the rest of s.
+// The value of a key without a delimiter is nil, and the key is empty if s starts with whitespace
+// followed by a pair delimiter.
+func (kv *KeyValueParser) readPair(s string) (key string, value interface{}, rest string, err error) {
+	end := kv.indexEnd(s, true)
+	key = strings.TrimSpace(s[:end])
+	rest = s[end:]
+
+	// A key without a delimiter is a bare key, or only whitespace between pair delimiters
+	if !strings.HasPrefix(rest, kv.delimiter) {
+		return key, nil, rest, nil
+	}
+
+	if key == "" {
+		return "", nil, "", fmt.Errorf("missing key before delimiter at '%s'", truncate(s))
+	}
+	rest = rest[len(kv.delimiter):]
+
+	// Whitespace around a quoted value is ignored if whitespace is not the pair delimiter
+	if kv.pairDelimiter != "" {
+		if trimmed := strings.TrimLeftFunc(rest, unicode.IsSpace); strings.HasPrefix(trimmed, `"`) {
+			rest = trimmed
+		}
+	}
+
+	if !strings.HasPrefix(rest, `"`) {
+		end = kv.indexEnd(rest, false)
+		return key, strings.TrimSpace(rest[:end]), rest[end:], nil
+	}
+
+	quoted, rest, err := readQuoted(rest)
+	if err != nil {
+		return "", nil, "", errors.Wrap(err, fmt.Sprintf("value of key '%s'", key))
+	}
+
+	if kv.pairDelimiter != "" {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+	}
+	if rest != "" && kv.skipPairDelimiters(rest) == rest {
+		return "", nil, "", fmt.Errorf("expected a pair delimiter after the quoted value of key '%s'", key)
+	}
+
+	return key, quoted, rest, nil
+}
+
+// readQuoted will read a double quoted string with backslash escapes from the start of s,
+// returning the unquoted string and the rest of s
+func readQuoted(s string) (string, string, error) {
+	escaped := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == '"':
+			unquoted, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid quoted string %s", s[:i+1])
+			}
+			return unquoted, s[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("quoted string is missing a closing quote")
+}
+
+// indexEnd will return the index of the end of a key or unquoted value,
+// which ends at a pair delimiter or, for keys, the delimiter
+func (kv *KeyValueParser) indexEnd(s string, isKey bool) int {
+	for i := 0; i < len(s); {
+		if isKey && strings.HasPrefix(s[i:], kv.delimiter) {
+			return i
+		}
+		if kv.pairDelimiter == "" {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if unicode.IsSpace(r) {
+				return i
+			}
+			i += size
+			continue
+		}
+		if strings.HasPrefix(s[i:], kv.pairDelimiter) {
+			return i
+		}
+		i++
+	}
+	return len(s)
+}
+
+// skipPairDelimiters will remove any leading pair delimiters from s.
+// Whitespace is the pair delimiter if no pair delimiter is configured.
+func (kv *KeyValueParser) skipPairDelimiters(s string) string {
+	if kv.pairDelimiter == "" {
+		return strings.TrimLeftFunc(s, unicode.IsSpace)
+	}
+	for strings.HasPrefix(s, kv.pairDelimiter) {
+		s = s[len(kv.pairDelimiter):]
+	}
+	return s
+}
+
+// truncate will shorten a string that is included in an error message
+func truncate(s string) string {
+	const maxLength = 32
+	if len(s) <= maxLength {
+		return s
+	}
+	return s[:maxLength] + "..."
+}
+
+// parsedMap holds parsed values in a map or an ordered map
+type parsedMap interface {
+	get(key string) (interface{}, bool)
+	set(key string, value interface{})
+	value() interface{}
+}
+
+type plainParsedMap map[string]interface{}
+
+func (m plainParsedMap) get(key string) (interface{}, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m plainParsedMap) set(key string, value interface{}) {
+	m[key] = value
+}
+
+func (m plainParsedMap) value() interface{} {
+	return map[string]interface{}(m)
+}
+
+type orderedParsedMap struct {
+	*entry.OrderedMap
+}
+
+func (m orderedParsedMap) get(key string) (interface{}, bool) {
+	return m.Get(key)
+}
+
+func (m orderedParsedMap) set(key string, value interface{}) {
+	m.Set(key, value)
+}
+
+func (m orderedParsedMap) value() interface{} {
+	return m.OrderedMap
+}
@@ -0,0 +1,302 @@
+package keyvalue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParser(t testing.TB, modify func(*KeyValueParserConfig)) *KeyValueParser {
+	cfg := NewKeyValueParserConfig("test")
+	modify(cfg)
+	op, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return op.(*KeyValueParser)
+}
+
+func TestKeyValueImplementations(t *testing.T) {
+	require.Implements(t, (*operator.Operator)(nil), new(KeyValueParser))
+}
+
+func TestKeyValueParserConfigBuildFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*KeyValueParserConfig)
+		expected string
+	}{
+		{
+			"MissingDelimiter",
+			func(cfg *KeyValueParserConfig) { cfg.Delimiter = "" },
+			"missing required field 'delimiter'",
+		},
+		{
+			"SameDelimiters",
+			func(cfg *KeyValueParserConfig) { cfg.PairDelimiter = "=" },
+			"delimiter and pair_delimiter can not be the same",
+		},
+		{
+			"QuoteDelimiter",
+			func(cfg *KeyValueParserConfig) { cfg.PairDelimiter = `"` },
+			"delimiters can not contain quotes",
+		},
+		{
+			"InvalidDuplicateKeys",
+			func(cfg *KeyValueParserConfig) { cfg.DuplicateKeys = "merge" },
+			"invalid duplicate_keys 'merge'",
+		},
+		{
+			"InvalidBareKeys",
+			func(cfg *KeyValueParserConfig) { cfg.BareKeys = "true" },
+			"invalid bare_keys 'true'",
+		},
+		{
+			"InvalidOnError",
+			func(cfg *KeyValueParserConfig) { cfg.OnError = "invalid_on_error" },
+			"invalid `on_error` field",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewKeyValueParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestKeyValueParser(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*KeyValueParserConfig)
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			"Logfmt",
+			func(cfg *KeyValueParserConfig) {},
+			`level=info msg="started server" dur=12ms`,
+			map[string]interface{}{"level": "info", "msg": "started server", "dur": "12ms"},
+		},
+		{
+			"Bytes",
+			func(cfg *KeyValueParserConfig) {},
+			[]byte(`level=info dur=12ms`),
+			map[string]interface{}{"level": "info", "dur": "12ms"},
+		},
+		{
+			"ExtraWhitespace",
+			func(cfg *KeyValueParserConfig) {},
+			"  level=info \t dur=12ms  ",
+			map[string]interface{}{"level": "info", "dur": "12ms"},
+		},
+		{
+			"Escapes",
+			func(cfg *KeyValueParserConfig) {},
+			`msg="say \"hi\"\n\tthen\\leave" path=C:\tmp`,
+			map[string]interface{}{"msg": "say \"hi\"\n\tthen\\leave", "path": `C:\tmp`},
+		},
+		{
+			"EmptyValues",
+			func(cfg *KeyValueParserConfig) {},
+			`a= b="" c=1`,
+			map[string]interface{}{"a": "", "b": "", "c": "1"},
+		},
+		{
+			"DelimiterInValue",
+			func(cfg *KeyValueParserConfig) {},
+			`query=a=b url="http://host/?x=1 y"`,
+			map[string]interface{}{"query": "a=b", "url": "http://host/?x=1 y"},
+		},
+		{
+			"CustomDelimiters",
+			func(cfg *KeyValueParserConfig) {
+				cfg.Delimiter = ":"
+				cfg.PairDelimiter = ","
+			},
+			`user: alice, action: "log in, then out" , status:ok,`,
+			map[string]interface{}{"user": "alice", "action": "log in, then out", "status": "ok"},
+		},
+		{
+			"MultiCharacterDelimiters",
+			func(cfg *KeyValueParserConfig) {
+				cfg.Delimiter = "=>"
+				cfg.PairDelimiter = "||"
+			},
+			`a=>1||b=>x|y||c=>"2"`,
+			map[string]interface{}{"a": "1", "b": "x|y", "c": "2"},
+		},
+		{
+			"BareKeyBool",
+			func(cfg *KeyValueParserConfig) {},
+			`level=info debug retry=3`,
+			map[string]interface{}{"level": "info", "debug": true, "retry": "3"},
+		},
+		{
+			"BareKeyEmpty",
+			func(cfg *KeyValueParserConfig) { cfg.BareKeys = "empty" },
+			`level=info debug`,
+			map[string]interface{}{"level": "info", "debug": ""},
+		},
+		{
+			"BareKeyDrop",
+			func(cfg *KeyValueParserConfig) { cfg.BareKeys = "drop" },
+			`level=info debug`,
+			map[string]interface{}{"level": "info"},
+		},
+		{
+			"DuplicateLast",
+			func(cfg *KeyValueParserConfig) {},
+			`tag=a tag=b tag=c`,
+			map[string]interface{}{"tag": "c"},
+		},
+		{
+			"DuplicateFirst",
+			func(cfg *KeyValueParserConfig) { cfg.DuplicateKeys = "first" },
+			`tag=a tag=b tag=c`,
+			map[string]interface{}{"tag": "a"},
+		},
+		{
+			"DuplicateArray",
+			func(cfg *KeyValueParserConfig) { cfg.DuplicateKeys = "array" },
+			`tag=a other=1 tag=b tag=c`,
+			map[string]interface{}{"tag": []interface{}{"a", "b", "c"}, "other": "1"},
+		},
+		{
+			"Empty",
+			func(cfg *KeyValueParserConfig) {},
+			"",
+			map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, tc.modify)
+
+			var output *entry.Entry
+			mockOutput := &testutil.Operator{}
+			mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				output = args[1].(*entry.Entry)
+			}).Return(nil)
+			parser.OutputOperators = []operator.Operator{mockOutput}
+
+			e := entry.New()
+			e.Record = tc.input
+			err := parser.Process(context.Background(), e)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, output.Record)
+		})
+	}
+}
+
+func TestKeyValueParserFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*KeyValueParserConfig)
+		input    interface{}
+		expected string
+	}{
+		{
+			"MissingKey",
+			func(cfg *KeyValueParserConfig) {},
+			`level=info =value`,
+			"missing key before delimiter at '=value'",
+		},
+		{
+			"UnterminatedQuote",
+			func(cfg *KeyValueParserConfig) {},
+			`msg="started`,
+			"value of key 'msg': quoted string is missing a closing quote",
+		},
+		{
+			"InvalidEscape",
+			func(cfg *KeyValueParserConfig) {},
+			`msg="\q"`,
+			`invalid quoted string "\q"`,
+		},
+		{
+			"TextAfterQuote",
+			func(cfg *KeyValueParserConfig) {},
+			`msg="started"server`,
+			"expected a pair delimiter after the quoted value of key 'msg'",
+		},
+		{
+			"BareKeyError",
+			func(cfg *KeyValueParserConfig) { cfg.BareKeys = "error" },
+			`level=info debug`,
+			"key 'debug' does not have a value",
+		},
+		{
+			"DuplicateError",
+			func(cfg *KeyValueParserConfig) { cfg.DuplicateKeys = "error" },
+			`tag=a tag=b`,
+			"duplicate key 'tag'",
+		},
+		{
+			"InvalidType",
+			func(cfg *KeyValueParserConfig) {},
+			[]int{},
+			"type '[]int' cannot be parsed as key value pairs",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, tc.modify)
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestKeyValueParserPreserveOrder(t *testing.T) {
+	parser := newTestParser(t, func(cfg *KeyValueParserConfig) {
+		cfg.PreserveOrder = true
+		cfg.DuplicateKeys = "array"
+	})
+
+	parsed, err := parser.parse(`zebra=1 apple=2 mango=3 apple=4`)
+	require.NoError(t, err)
+	orderedMap, ok := parsed.(*entry.OrderedMap)
+	require.True(t, ok)
+	require.Equal(t, []string{"zebra", "apple", "mango"}, orderedMap.Keys())
+
+	apple, _ := orderedMap.Get("apple")
+	require.Equal(t, []interface{}{"2", "4"}, apple)
+}
+
+const benchmarkLine = `time=2020-09-24T13:05:09Z level=info msg="request completed" method=GET ` +
+	`path=/api/v1/users status=200 dur=12ms bytes=5120 user_agent="Mozilla/5.0 (X11; Linux x86_64)"`
+
+func BenchmarkKeyValueParser(b *testing.B) {
+	cases := []struct {
+		name   string
+		modify func(*KeyValueParserConfig)
+	}{
+		{"Default", func(cfg *KeyValueParserConfig) {}},
+		{"PreserveOrder", func(cfg *KeyValueParserConfig) { cfg.PreserveOrder = true }},
+		{"DuplicateArray", func(cfg *KeyValueParserConfig) { cfg.DuplicateKeys = "array" }},
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			parser := newTestParser(b, tc.modify)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.parse(benchmarkLine); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package layout
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/observiq/stanza/operator/helper"
+)
+
+// conversion matches a conversion of a log4j, log4j2 or logback pattern, with its format modifier and options.
+// For example, %-5p, %.30logger, %d{ISO8601} and %d{HH:mm:ss}{UTC}.
+var conversion = regexp.MustCompile(`^%(-)?(\d+)?(?:\.-?\d+)?([a-zA-Z]+|%)((?:\{[^}]*\})*)`)
+
+// conversionOption matches an option of a conversion
+var conversionOption = regexp.MustCompile(`\{([^}]*)\}`)
+
+// conversionWord is a conversion that is extracted as a field
+type conversionWord struct {
+	field   string
+	pattern string
+}
+
+// conversionWords are the conversion words of the log4j, log4j2 and logback dialects, except
+// for those that need options, like dates and MDC values
+var conversionWords = map[string]conversionWord{}
+
+func init() {
+	add := func(field, pattern string, words ...string) {
+		for _, word := range words {
+			conversionWords[word] = conversionWord{field: field, pattern: pattern}
+		}
+	}
+	add("level", `\S+?`, "p", "le", "level")
+	add("thread", `.*?`, "t", "thread", "tn", "threadName")
+	add("thread_id", `\d+`, "T", "tid", "threadId")
+	add("logger", `\S+?`, "c", "lo", "logger")
+	add("class", `[\w.$]+`, "C", "class")
+	add("method", `[\w$<>]+?`, "M", "method")
+	add("file", `\S+?`, "F", "file")
+	add("line", `\d+|\?`, "L", "line")
+	add("location", `\S+?`, "l", "location")
+	add("message", anyText, "m", "msg", "message")
+	add("exception", exceptionPattern, "ex", "exception", "throwable", "xEx", "xException", "xThrowable", "rEx", "rException", "rThrowable", "wEx", "wException")
+	add("relative", `\d+`, "r", "relative")
+	add("ndc", `.*?`, "x", "NDC")
+	add("marker", `.*?`, "marker")
+	add("context", `\S+?`, "cn", "contextName")
+	add("pid", `\d+`, "pid", "processId")
+	add("sequence", `\d+`, "sn", "sequenceNumber")
+	add("uuid", `[\da-fA-F-]+`, "u", "uuid")
+}
+
+// exceptionPattern matches an exception, which starts on a new line with the name of its class, like
+// "java.lang.IllegalStateException: message", followed by its stack trace. It is empty if none was logged.
+const exceptionPattern = `(?:(?m:^[\w$]+(?:\.[\w$]+)+(?::|$)).*)?`
+
+// defaultDateFormat is the format of dates that do not specify a format
+const defaultDateFormat = "yyyy-MM-dd HH:mm:ss,SSS"
+
+// namedDateFormats are the names that can be used instead of a date format in each dialect
+var namedDateFormats = map[string]map[string]string{
+	log4jDialect: {
+		"ISO8601":  "yyyy-MM-dd HH:mm:ss,SSS",
+		"ABSOLUTE": "HH:mm:ss,SSS",
+		"DATE":     "dd MMM yyyy HH:mm:ss,SSS",
+	},
+	log4j2Dialect: {
+		"DEFAULT":       "yyyy-MM-dd HH:mm:ss,SSS",
+		"ISO8601":       "yyyy-MM-dd'T'HH:mm:ss,SSS",
+		"ISO8601_BASIC": "yyyyMMdd'T'HHmmss,SSS",
+		"ABSOLUTE":      "HH:mm:ss,SSS",
+		"DATE":          "dd MMM yyyy HH:mm:ss,SSS",
+	},
+	logbackDialect: {
+		"ISO8601": "yyyy-MM-dd HH:mm:ss,SSS",
+	},
+}
+
+// compileConversionPattern will generate the regex of a log4j, log4j2 or logback conversion pattern
+func compileConversionPattern(dialect, pattern string) (*generatedLayout, error) {
+	b := newLayoutBuilder()
+	for i := 0; i < len(pattern); {
+		if pattern[i] != '%' {
+			next := strings.IndexByte(pattern[i:], '%')
+			if next == -1 {
+				next = len(pattern) - i
+			}
+			b.addLiteral(pattern[i : i+next])
+			i += next
+			continue
+		}
+
+		match := conversion.FindStringSubmatch(pattern[i:])
+		if match == nil {
+			return nil, fmt.Errorf("invalid conversion at position %d", i)
+		}
+		i += len(match[0])
+
+		pad := noPadding
+		switch {
+		case match[2] != "" && match[1] != "":
+			pad = trailingPadding
+		case match[2] != "":
+			pad = leadingPadding
+		}
+
+		options := []string{}
+		for _, option := range conversionOption.FindAllStringSubmatch(match[4], -1) {
+			options = append(options, option[1])
+		}
+
+		if err := addConversion(b, dialect, match[3], options, pad); err != nil {
+			return nil, err
+		}
+	}
+	return b.build(), nil
+}
+
+// addConversion will add a conversion of a pattern to the regex
+func addConversion(b *layoutBuilder, dialect, word string, options []string, pad padding) error {
+	switch word {
+	case "%":
+		b.addLiteral("%")
+		return nil
+	case "n":
+		b.addPattern(`(?:\r?\n)?`)
+		return nil
+	case "d", "date":
+		return addDateConversion(b, dialect, options, pad)
+	case "X", "mdc", "MDC":
+		name := "mdc"
+		if len(options) > 0 && options[0] != "" {
+			name = fieldName(options[0])
+		}
+		return b.addField(name, `.*?`, pad)
+	}
+
+	converter, ok := conversionWords[word]
+	if !ok {
+		return fmt.Errorf("the conversion '%%%s' is not supported", word)
+	}
+	switch converter.field {
+	case "level":
+		b.layout.severity = converter.field
+	case "exception":
+		// An exception is written on a new line, even if the layout does not end the message with %n
+		b.addPattern(`(?:\r?\n)?`)
+	}
+	return b.addField(converter.field, converter.pattern, pad)
+}
+
+// addDateConversion will add a date conversion to the regex. A timestamp is only
+// parsed from dates that include the month and day.
+func addDateConversion(b *layoutBuilder, dialect string, options []string, pad padding) error {
+	format, location := defaultDateFormat, ""
+	switch dialect {
+	case logbackDialect:
+		// Logback separates the time zone from the format with a comma, and formats with a comma are quoted
+		if len(options) > 0 {
+			parts := splitLogbackOption(options[0])
+			if parts[0] != "" {
+				format = parts[0]
+			}
+			if len(parts) > 1 {
+				location = parts[1]
+			}
+		}
+	case log4j2Dialect:
+		if len(options) > 0 && options[0] != "" {
+			format = options[0]
+		}
+		if len(options) > 1 {
+			location = options[1]
+		}
+	default:
+		if len(options) > 0 && options[0] != "" {
+			format = options[0]
+		}
+	}
+
+	if named, ok := namedDateFormats[dialect][format]; ok {
+		format = named
+	}
+
+	// Log4j2 can format dates as seconds or milliseconds since the epoch
+	if dialect == log4j2Dialect && (format == "UNIX" || format == "UNIX_MILLIS") {
+		layout := "s"
+		if format == "UNIX_MILLIS" {
+			layout = "ms"
+		}
+		b.layout.timestamp = &timestampField{field: "timestamp", layout: layout, layoutType: helper.EpochKey}
+		return b.addField("timestamp", `\d+`, pad)
+	}
+
+	date, err := convertDateFormat(format)
+	if err != nil {
+		return err
+	}
+	if date.hasDate {
+		b.layout.timestamp = &timestampField{
+			field:         "timestamp",
+			layout:        date.layout,
+			layoutType:    helper.GotimeKey,
+			location:      location,
+			commaFraction: date.commaFraction,
+		}
+	}
+	return b.addField("timestamp", date.regex, pad)
+}
+
+// splitLogbackOption will split the option of a logback date into the format and time zone
+func splitLogbackOption(option string) []string {
+	option = strings.TrimSpace(option)
+	if strings.HasPrefix(option, `"`) {
+		if end := strings.Index(option[1:], `"`); end != -1 {
+			format := option[1 : end+1]
+			rest := strings.TrimPrefix(strings.TrimSpace(option[end+2:]), ",")
+			if rest = strings.TrimSpace(rest); rest != "" {
+				return []string{format, rest}
+			}
+			return []string{format}
+		}
+	}
+
+	parts := strings.SplitN(option, ",", 2)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// convertedDate is a Java date format converted to a Go layout and a regex
+type convertedDate struct {
+	layout        string
+	regex         string
+	hasDate       bool
+	commaFraction bool
+}
+
+// dateLetter is how a letter of a Java date format is converted, depending on how many times it is repeated
+type dateLetter func(count int) (layout, regex string)
+
+// dateLetters are the letters of Java date formats that can be converted to Go layouts
+var dateLetters = map[byte]dateLetter{
+	'y': func(count int) (string, string) {
+		if count == 2 {
+			return "06", `\d{2}`
+		}
+		return "2006", `\d{4}`
+	},
+	'M': func(count int) (string, string) {
+		switch count {
+		case 1:
+			return "1", `\d{1,2}`
+		case 2:
+			return "01", `\d{2}`
+		case 3:
+			return "Jan", `[A-Za-z]{3}`
+		default:
+			return "January", `[A-Za-z]+`
+		}
+	},
+	'd': numberLetter("2", "02"),
+	'H': numberLetter("15", "15"),
+	'h': numberLetter("3", "03"),
+	'm': numberLetter("4", "04"),
+	's': numberLetter("5", "05"),
+	'E': func(count int) (string, string) {
+		if count <= 3 {
+			return "Mon", `[A-Za-z]{3}`
+		}
+		return "Monday", `[A-Za-z]+`
+	},
+	'a': func(int) (string, string) { return "PM", `[AP]M` },
+	'z': func(int) (string, string) { return "MST", `[A-Za-z]+` },
+	'Z': func(int) (string, string) { return "-0700", `[+-]\d{4}` },
+	'X': func(count int) (string, string) {
+		switch count {
+		case 1:
+			return "Z07", `Z|[+-]\d{2}`
+		case 2:
+			return "Z0700", `Z|[+-]\d{4}`
+		default:
+			return "Z07:00", `Z|[+-]\d{2}:\d{2}`
+		}
+	},
+}
+
+// numberLetter converts a letter that is a number, which is zero padded when the letter is repeated
+func numberLetter(unpadded, padded string) dateLetter {
+	return func(count int) (string, string) {
+		if count == 1 {
+			return unpadded, `\d{1,2}`
+		}
+		return padded, `\d{2}`
+	}
+}
+
+// convertDateFormat will convert a Java date format, as used by log4j and logback, to a Go layout and a regex
+func convertDateFormat(format string) (*convertedDate, error) {
+	var layout, regex strings.Builder
+	hasMonth, hasDay, commaFraction := false, false, false
+	secondsEnd := -1
+
+	for i := 0; i < len(format); {
+		c := format[i]
+		switch {
+		case c == '\'':
+			// Text in single quotes is literal, and two single quotes are a single quote
+			text, length, err := quotedText(format[i:])
+			if err != nil {
+				return nil, fmt.Errorf("date format '%s' %s", format, err)
+			}
+			layout.WriteString(text)
+			regex.WriteString(regexp.QuoteMeta(text))
+			i += length
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			count := 1
+			for i+count < len(format) && format[i+count] == c {
+				count++
+			}
+			i += count
+
+			// Fractional seconds are always after a period in the layout, as time.Parse
+			// only accepts a comma before fractional seconds since Go 1.17
+			if c == 'S' {
+				current := layout.String()
+				if secondsEnd == -1 || len(current) != secondsEnd+1 || !strings.ContainsAny(current[secondsEnd:], ".,") {
+					return nil, fmt.Errorf("date format '%s' has fractional seconds that do not follow the seconds and a '.' or ','", format)
+				}
+				if count > 9 {
+					return nil, fmt.Errorf("date format '%s' has more than 9 digits of fractional seconds", format)
+				}
+				commaFraction = current[secondsEnd] == ','
+				layout.Reset()
+				layout.WriteString(current[:secondsEnd] + "." + strings.Repeat("0", count))
+				fmt.Fprintf(&regex, `\d{%d}`, count)
+				continue
+			}
+
+			letter, ok := dateLetters[c]
+			if !ok {
+				return nil, fmt.Errorf("the letter '%c' of date format '%s' is not supported", c, format)
+			}
+			letterLayout, letterRegex := letter(count)
+			layout.WriteString(letterLayout)
+			regex.WriteString("(?:" + letterRegex + ")")
+
+			switch c {
+			case 'M':
+				hasMonth = true
+			case 'd':
+				hasDay = true
+			case 's':
+				secondsEnd = layout.Len()
+			}
+		default:
+			layout.WriteByte(c)
+			regex.WriteString(regexp.QuoteMeta(string(c)))
+			i++
+		}
+	}
+
+	return &convertedDate{
+		layout:        layout.String(),
+		regex:         regex.String(),
+		hasDate:       hasMonth && hasDay,
+		commaFraction: commaFraction,
+	}, nil
+}
+
+// quotedText will return the literal text of the quoted text at the start of a date format, and its length in the format
+func quotedText(format string) (string, int, error) {
+	if strings.HasPrefix(format, "''") {
+		return "'", 2, nil
+	}
+
+	var text strings.Builder
+	for i := 1; i < len(format); i++ {
+		if format[i] != '\'' {
+			text.WriteByte(format[i])
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '\'' {
+			text.WriteByte('\'')
+			i++
+			continue
+		}
+		return text.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("has an unterminated quote")
+}
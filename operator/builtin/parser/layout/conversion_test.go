@@ -0,0 +1,247 @@
+package layout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileConversionPattern(t *testing.T) {
+	cases := []struct {
+		name     string
+		dialect  string
+		pattern  string
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"Padding",
+			"log4j",
+			"%5p|%-10c|%t",
+			" INFO|app       |main",
+			map[string]interface{}{"level": "INFO", "logger": "app", "thread": "main"},
+		},
+		{
+			"LongNames",
+			"logback",
+			"%date %level [%thread] %logger{10} %class.%method %file:%line - %message%n",
+			"2020-09-24 10:12:13,456 DEBUG [worker 1] c.e.App com.example.App.run App.java:42 - started\n",
+			map[string]interface{}{
+				"timestamp": "2020-09-24 10:12:13,456",
+				"level":     "DEBUG",
+				"thread":    "worker 1",
+				"logger":    "c.e.App",
+				"class":     "com.example.App",
+				"method":    "run",
+				"file":      "App.java",
+				"line":      "42",
+				"message":   "started",
+			},
+		},
+		{
+			"MDC",
+			"log4j2",
+			"%X{request-id} %X{user} %r %m%n",
+			"abc123  15 no user",
+			map[string]interface{}{"request_id": "abc123", "relative": "15", "message": "no user"},
+		},
+		{
+			"Percent",
+			"log4j",
+			"%m 100%%",
+			"loaded 100%",
+			map[string]interface{}{"message": "loaded"},
+		},
+		{
+			"Exception",
+			"log4j2",
+			"%p %m%n%ex",
+			"ERROR failed",
+			map[string]interface{}{"level": "ERROR", "message": "failed"},
+		},
+		{
+			"ExceptionTrace",
+			"log4j2",
+			"%p %m%n%ex",
+			"ERROR failed\njava.lang.RuntimeException: boom\n\tat A.b(A.java:1)",
+			map[string]interface{}{
+				"level":     "ERROR",
+				"message":   "failed",
+				"exception": "java.lang.RuntimeException: boom\n\tat A.b(A.java:1)",
+			},
+		},
+		{
+			"ExceptionMultilineMessage",
+			"logback",
+			"%p %m%n%ex",
+			"ERROR failed to save\nthe file is locked\njava.io.IOException\n\tat A.b(A.java:1)",
+			map[string]interface{}{
+				"level":     "ERROR",
+				"message":   "failed to save\nthe file is locked",
+				"exception": "java.io.IOException\n\tat A.b(A.java:1)",
+			},
+		},
+		{
+			"Unix",
+			"log4j2",
+			"%d{UNIX_MILLIS} %m",
+			"1600942333456 started",
+			map[string]interface{}{"timestamp": "1600942333456", "message": "started"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			generated, err := compileConversionPattern(tc.dialect, tc.pattern)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parseGenerated(t, generated, tc.input))
+		})
+	}
+}
+
+func TestCompileConversionPatternTimestamp(t *testing.T) {
+	cases := []struct {
+		name     string
+		dialect  string
+		pattern  string
+		expected *timestampField
+	}{
+		{
+			"Default",
+			"log4j",
+			"%d %m",
+			&timestampField{field: "timestamp", layout: "2006-01-02 15:04:05.000", layoutType: helper.GotimeKey, commaFraction: true},
+		},
+		{
+			"Log4jISO8601",
+			"log4j",
+			"%d{ISO8601} %m",
+			&timestampField{field: "timestamp", layout: "2006-01-02 15:04:05.000", layoutType: helper.GotimeKey, commaFraction: true},
+		},
+		{
+			"Log4j2ISO8601",
+			"log4j2",
+			"%d{ISO8601} %m",
+			&timestampField{field: "timestamp", layout: "2006-01-02T15:04:05.000", layoutType: helper.GotimeKey, commaFraction: true},
+		},
+		{
+			"Log4j2TimeZone",
+			"log4j2",
+			"%d{yyyy-MM-dd HH:mm:ss}{America/Chicago} %m",
+			&timestampField{field: "timestamp", layout: "2006-01-02 15:04:05", layoutType: helper.GotimeKey, location: "America/Chicago"},
+		},
+		{
+			"LogbackTimeZone",
+			"logback",
+			"%d{yyyy-MM-dd HH:mm:ss, UTC} %m",
+			&timestampField{field: "timestamp", layout: "2006-01-02 15:04:05", layoutType: helper.GotimeKey, location: "UTC"},
+		},
+		{
+			"LogbackQuoted",
+			"logback",
+			`%d{"dd MMM yyyy HH:mm:ss,SSS", Europe/Paris} %m`,
+			&timestampField{field: "timestamp", layout: "02 Jan 2006 15:04:05.000", layoutType: helper.GotimeKey, location: "Europe/Paris", commaFraction: true},
+		},
+		{
+			"Unix",
+			"log4j2",
+			"%d{UNIX} %m",
+			&timestampField{field: "timestamp", layout: "s", layoutType: helper.EpochKey},
+		},
+		{
+			"TimeOnly",
+			"log4j",
+			"%d{ABSOLUTE} %m",
+			nil,
+		},
+		{
+			"NoDate",
+			"log4j",
+			"%p %m",
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			generated, err := compileConversionPattern(tc.dialect, tc.pattern)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, generated.timestamp)
+		})
+	}
+}
+
+func TestConvertDateFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		input    string
+		expected time.Time
+	}{
+		{"yyyy-MM-dd HH:mm:ss,SSS", "2020-09-24 10:12:13,456", time.Date(2020, 9, 24, 10, 12, 13, 456000000, time.UTC)},
+		{"HH:mm:ss,SSSSSS dd/MM/yyyy", "10:12:13,456789 24/09/2020", time.Date(2020, 9, 24, 10, 12, 13, 456789000, time.UTC)},
+		{"yyyy-MM-dd'T'HH:mm:ss.SSSXXX", "2020-09-24T10:12:13.456+02:00", time.Date(2020, 9, 24, 8, 12, 13, 456000000, time.UTC)},
+		{"yyyyMMdd'T'HHmmss", "20200924T101213", time.Date(2020, 9, 24, 10, 12, 13, 0, time.UTC)},
+		{"dd MMM yyyy HH:mm:ss Z", "24 Sep 2020 10:12:13 -0500", time.Date(2020, 9, 24, 15, 12, 13, 0, time.UTC)},
+		{"EEE, d MMMM yy h:mm a", "Thu, 4 September 20 9:05 PM", time.Date(2020, 9, 4, 21, 5, 0, 0, time.UTC)},
+		{"M/d/yyyy H:mm:ss 'o''clock'", "9/4/2020 9:05:00 o'clock", time.Date(2020, 9, 4, 9, 5, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.format, func(t *testing.T) {
+			date, err := convertDateFormat(tc.format)
+			require.NoError(t, err)
+			require.True(t, date.hasDate)
+			require.Regexp(t, "^(?:"+date.regex+")$", tc.input)
+
+			input := tc.input
+			if date.commaFraction {
+				input = replaceFractionComma(input)
+			}
+			parsed, err := time.Parse(date.layout, input)
+			require.NoError(t, err)
+			require.True(t, tc.expected.Equal(parsed), "expected %s, got %s", tc.expected, parsed)
+		})
+	}
+}
+
+func TestConvertDateFormatFailure(t *testing.T) {
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{"yyyy-MM-dd 'at HH:mm", "has an unterminated quote"},
+		{"yyyy-ww", "the letter 'w' of date format 'yyyy-ww' is not supported"},
+		{"HH:mm:ssSSS", "has fractional seconds that do not follow the seconds"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.format, func(t *testing.T) {
+			_, err := convertDateFormat(tc.format)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestCompileConversionPatternFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		pattern  string
+		expected string
+	}{
+		{"Unsupported", "%p %blue(%m)", "the conversion '%blue' is not supported"},
+		{"Trailing", "%m %", "invalid conversion at position 3"},
+		{"Duplicate", "%m %message", "the field 'message' is in the layout more than once"},
+		{"InvalidDate", "%d{yyyy-QQ} %m", "the letter 'Q' of date format 'yyyy-QQ' is not supported"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := compileConversionPattern("logback", tc.pattern)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package layout
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("layout_parser", func() operator.Builder { return NewLayoutParserConfig("") })
+}
+
+const (
+	log4jDialect   = "log4j"
+	log4j2Dialect  = "log4j2"
+	logbackDialect = "logback"
+	pythonDialect  = "python"
+)
+
+// NewLayoutParserConfig creates a new layout parser config with default values
+func NewLayoutParserConfig(operatorID string) *LayoutParserConfig {
+	return &LayoutParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "layout_parser"),
+	}
+}
+
+// LayoutParserConfig is the configuration of a layout parser operator.
+type LayoutParserConfig struct {
+	helper.ParserConfig `yaml:",inline"`
+
+	Layout     string `json:"layout"                yaml:"layout"`
+	Dialect    string `json:"dialect"               yaml:"dialect"`
+	DateFormat string `json:"date_format,omitempty" yaml:"date_format,omitempty"`
+}
+
+// Build will build a layout parser operator.
+func (c LayoutParserConfig) Build(context operator.BuildContext) (operator.Operator, error) {
+	if c.Layout == "" {
+		return nil, fmt.Errorf("missing required field 'layout'")
+	}
+
+	if c.DateFormat != "" && c.Dialect != pythonDialect {
+		return nil, errors.NewError(
+			"`date_format` can only be used with the `python` dialect",
+			"specify the date format in the %d conversion of the layout",
+		)
+	}
+
+	var generated *generatedLayout
+	var err error
+	switch c.Dialect {
+	case log4jDialect, log4j2Dialect, logbackDialect:
+		generated, err = compileConversionPattern(c.Dialect, c.Layout)
+	case pythonDialect:
+		generated, err = compilePythonFormat(c.Layout, c.DateFormat)
+	case "":
+		return nil, fmt.Errorf("missing required field 'dialect'")
+	default:
+		return nil, errors.NewError(
+			fmt.Sprintf("invalid dialect '%s'", c.Dialect),
+			"specify a dialect of log4j, log4j2, logback or python",
+		)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "compile layout").WithDetails("layout", c.Layout)
+	}
+
+	// Unless timestamp and severity blocks are configured, they are parsed from the fields of the layout
+	commaFractionField := ""
+	if parseTo, ok := c.ParseTo.FieldInterface.(entry.RecordField); ok {
+		if c.TimeParser == nil && generated.timestamp != nil {
+			parseFrom := entry.Field{FieldInterface: parseTo.Child(generated.timestamp.field)}
+			c.TimeParser = &helper.TimeParser{
+				ParseFrom:  &parseFrom,
+				Layout:     generated.timestamp.layout,
+				LayoutType: generated.timestamp.layoutType,
+				Location:   generated.timestamp.location,
+			}
+			if generated.timestamp.commaFraction {
+				commaFractionField = generated.timestamp.field
+			}
+		}
+		if c.SeverityParserConfig == nil && generated.severity != "" {
+			parseFrom := entry.Field{FieldInterface: parseTo.Child(generated.severity)}
+			c.SeverityParserConfig = &helper.SeverityParserConfig{
+				ParseFrom: &parseFrom,
+				Preserve:  true,
+				Preset:    severityPresets[c.Dialect],
+			}
+		}
+	}
+
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := regexp.Compile(generated.regex)
+	if err != nil {
+		return nil, errors.NewError(
+			fmt.Sprintf("compiling generated regex: %s", err),
+			"ensure that the layout is valid for the dialect",
+			"regex", generated.regex,
+		)
+	}
+	parserOperator.Debugw("Generated regex from layout", "layout", c.Layout, "regex", generated.regex)
+
+	layoutParser := &LayoutParser{
+		ParserOperator:     parserOperator,
+		regexp:             r,
+		commaFractionField: commaFractionField,
+	}
+
+	return layoutParser, nil
+}
+
+// severityPresets are the severity presets of the levels of each dialect
+var severityPresets = map[string]string{
+	log4jDialect:   "log4j",
+	log4j2Dialect:  "log4j",
+	logbackDialect: "log4j",
+	pythonDialect:  "python",
+}
+
+// generatedLayout is the regex generated from a layout, and the fields that hold the timestamp and severity
+type generatedLayout struct {
+	regex     string
+	timestamp *timestampField
+	severity  string
+}
+
+// timestampField is a field of a layout that holds a timestamp. If the fractional seconds of the
+// timestamp follow a comma, the layout has a period instead, which the comma is replaced with.
+type timestampField struct {
+	field         string
+	layout        string
+	layoutType    string
+	location      string
+	commaFraction bool
+}
+
+// padding is how a field of a layout is padded to a minimum width
+type padding int
+
+const (
+	noPadding padding = iota
+	leadingPadding
+	trailingPadding
+)
+
+// anyText is the pattern of fields that can contain any text, like the message
+const anyText = `.*`
+
+// layoutBuilder builds the regex of a layout from its literal text and fields
+type layoutBuilder struct {
+	parts  []string
+	fields map[string]bool
+	layout generatedLayout
+
+	// greedy is the index of the last part if it is a field that matches any text
+	greedy int
+}
+
+func newLayoutBuilder() *layoutBuilder {
+	return &layoutBuilder{fields: map[string]bool{}, greedy: -1}
+}
+
+// addLiteral will add text that is matched literally
+func (b *layoutBuilder) addLiteral(text string) {
+	b.parts = append(b.parts, regexp.QuoteMeta(text))
+}
+
+// addPattern will add a pattern that is matched without extracting a field
+func (b *layoutBuilder) addPattern(pattern string) {
+	b.parts = append(b.parts, pattern)
+}
+
+// addField will add a pattern that is extracted as a field
+func (b *layoutBuilder) addField(name, pattern string, pad padding) error {
+	if b.fields[name] {
+		return fmt.Errorf("the field '%s' is in the layout more than once", name)
+	}
+	b.fields[name] = true
+
+	// A field that matches any text, like the message, stops at the fields that follow it
+	if b.greedy != -1 {
+		b.parts[b.greedy] = strings.TrimSuffix(b.parts[b.greedy], ")") + "?)"
+		b.greedy = -1
+	}
+
+	if pad == leadingPadding {
+		b.parts = append(b.parts, ` *`)
+	}
+	if pattern == anyText {
+		b.greedy = len(b.parts)
+	}
+	b.parts = append(b.parts, fmt.Sprintf("(?P<%s>%s)", name, pattern))
+	if pad == trailingPadding {
+		b.parts = append(b.parts, ` *`)
+	}
+	return nil
+}
+
+// build will return the generated layout, with a regex that matches the whole value
+func (b *layoutBuilder) build() *generatedLayout {
+	b.layout.regex = "(?s)^" + strings.Join(b.parts, "") + "$"
+	return &b.layout
+}
+
+// invalidName matches the characters that cannot be used in the name of a capture group
+var invalidName = regexp.MustCompile(`\W`)
+
+// fieldName will convert a key, like the key of an MDC value, to the name of a field
+func fieldName(key string) string {
+	name := invalidName.ReplaceAllString(key, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// fractionComma matches the comma before fractional seconds, which is the last comma between digits
+var fractionComma = regexp.MustCompile(`^(.*\d),(\d)`)
+
+// replaceFractionComma will replace the comma before the fractional seconds of a timestamp with a period,
+// as time.Parse only accepts a comma before fractional seconds since Go 1.17
+func replaceFractionComma(timestamp string) string {
+	return fractionComma.ReplaceAllString(timestamp, "${1}.${2}")
+}
+
+// LayoutParser is an operator that parses entries with a regex generated from a logging layout.
+type LayoutParser struct {
+	helper.ParserOperator
+	regexp             *regexp.Regexp
+	commaFractionField string
+}
+
+// Process will parse an entry with the layout.
+func (l *LayoutParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return l.ParserOperator.ProcessWith(ctx, entry, l.parse)
+}
+
+// parse will parse a value with the regex of the layout.
+func (l *LayoutParser) parse(value interface{}) (interface{}, error) {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed with a layout", value)
+	}
+
+	// Trailing line breaks, like those of the %n conversion, are not part of the last field
+	matches := l.regexp.FindStringSubmatch(strings.TrimRight(str, "\r\n"))
+	if matches == nil {
+		return nil, fmt.Errorf("value does not match the layout")
+	}
+
+	// Fields that are empty, like an exception that was not logged, are not included
+	parsedValues := map[string]interface{}{}
+	for i, name := range l.regexp.SubexpNames() {
+		if i == 0 || name == "" || matches[i] == "" {
+			continue
+		}
+		parsedValues[name] = matches[i]
+	}
+
+	if timestamp, ok := parsedValues[l.commaFractionField].(string); ok && l.commaFractionField != "" {
+		parsedValues[l.commaFractionField] = replaceFractionComma(timestamp)
+	}
+
+	return parsedValues, nil
+}
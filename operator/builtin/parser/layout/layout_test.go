@@ -0,0 +1,253 @@
+package layout
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParser(t *testing.T, modify func(*LayoutParserConfig)) *LayoutParser {
+	cfg := NewLayoutParserConfig("test")
+	modify(cfg)
+	op, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return op.(*LayoutParser)
+}
+
+// parseGenerated will parse a value with the regex of a generated layout
+func parseGenerated(t *testing.T, generated *generatedLayout, value string) interface{} {
+	parser := &LayoutParser{regexp: regexp.MustCompile(generated.regex)}
+	parsed, err := parser.parse(value)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestLayoutImplementations(t *testing.T) {
+	require.Implements(t, (*operator.Operator)(nil), new(LayoutParser))
+}
+
+func TestLayoutParser(t *testing.T) {
+	cases := []struct {
+		name              string
+		dialect           string
+		layout            string
+		dateFormat        string
+		input             string
+		expectedRecord    map[string]interface{}
+		expectedTimestamp time.Time
+		expectedSeverity  entry.Severity
+	}{
+		{
+			"Log4j",
+			"log4j",
+			"%d{ISO8601} [%t] %-5p %c - %m%n",
+			"",
+			"2020-09-24 10:12:13,456 [main] WARN  com.example.App - disk is almost full",
+			map[string]interface{}{
+				"thread":  "main",
+				"level":   "WARN",
+				"logger":  "com.example.App",
+				"message": "disk is almost full",
+			},
+			time.Date(2020, 9, 24, 10, 12, 13, 456000000, time.Local),
+			entry.Warning,
+		},
+		{
+			"Log4j2TimeZone",
+			"log4j2",
+			"%d{ISO8601}{UTC} %-5level %logger{36} - %msg%n%ex",
+			"",
+			"2020-09-24T10:12:13,456 ERROR com.example.App - request failed\njava.lang.IllegalStateException\n\tat com.example.App.main(App.java:10)\n",
+			map[string]interface{}{
+				"level":     "ERROR",
+				"logger":    "com.example.App",
+				"message":   "request failed",
+				"exception": "java.lang.IllegalStateException\n\tat com.example.App.main(App.java:10)",
+			},
+			time.Date(2020, 9, 24, 10, 12, 13, 456000000, time.UTC),
+			entry.Error,
+		},
+		{
+			"Logback",
+			"logback",
+			"%d{HH:mm:ss.SSS} [%thread] %-5level %logger{36} - %msg%n",
+			"",
+			"10:12:13.456 [http-nio-8080-exec-1] INFO  c.e.Controller - request served",
+			map[string]interface{}{
+				"timestamp": "10:12:13.456",
+				"thread":    "http-nio-8080-exec-1",
+				"level":     "INFO",
+				"logger":    "c.e.Controller",
+				"message":   "request served",
+			},
+			time.Time{},
+			entry.Info,
+		},
+		{
+			"Python",
+			"python",
+			"%(asctime)s %(levelname)s %(name)s: %(message)s",
+			"",
+			"2020-09-24 10:12:13,456 CRITICAL app.db: connection lost",
+			map[string]interface{}{
+				"levelname": "CRITICAL",
+				"name":      "app.db",
+				"message":   "connection lost",
+			},
+			time.Date(2020, 9, 24, 10, 12, 13, 456000000, time.Local),
+			entry.Critical,
+		},
+		{
+			"PythonDateFormat",
+			"python",
+			"[%(asctime)s] %(levelno)s %(message)s",
+			"%d/%b/%Y %H:%M:%S",
+			"[24/Sep/2020 10:12:13] 10 cache miss",
+			map[string]interface{}{
+				"levelno": "10",
+				"message": "cache miss",
+			},
+			time.Date(2020, 9, 24, 10, 12, 13, 0, time.Local),
+			entry.Debug,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, func(cfg *LayoutParserConfig) {
+				cfg.Dialect = tc.dialect
+				cfg.Layout = tc.layout
+				cfg.DateFormat = tc.dateFormat
+			})
+
+			var output *entry.Entry
+			mockOutput := &testutil.Operator{}
+			mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				output = args[1].(*entry.Entry)
+			}).Return(nil)
+			parser.OutputOperators = []operator.Operator{mockOutput}
+
+			e := entry.New()
+			e.Record = tc.input
+			err := parser.Process(context.Background(), e)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRecord, output.Record)
+			require.Equal(t, tc.expectedSeverity, output.Severity)
+			if !tc.expectedTimestamp.IsZero() {
+				require.True(t, tc.expectedTimestamp.Equal(output.Timestamp), "expected %s, got %s", tc.expectedTimestamp, output.Timestamp)
+			}
+		})
+	}
+}
+
+func TestLayoutParserOverride(t *testing.T) {
+	parser := newTestParser(t, func(cfg *LayoutParserConfig) {
+		cfg.Dialect = "log4j"
+		cfg.Layout = "%d{yyyy-MM-dd HH:mm:ss} %p %m"
+		parseFrom := entry.NewRecordField("level")
+		cfg.SeverityParserConfig = &helper.SeverityParserConfig{
+			ParseFrom: &parseFrom,
+			Preset:    "none",
+			Mapping:   map[interface{}]interface{}{"alert": "LOUD"},
+		}
+	})
+
+	var output *entry.Entry
+	mockOutput := &testutil.Operator{}
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		output = args[1].(*entry.Entry)
+	}).Return(nil)
+	parser.OutputOperators = []operator.Operator{mockOutput}
+
+	e := entry.New()
+	e.Record = "2020-09-24 10:12:13 LOUD something happened"
+	err := parser.Process(context.Background(), e)
+	require.NoError(t, err)
+	require.Equal(t, entry.Alert, output.Severity)
+	require.Equal(t, map[string]interface{}{"message": "something happened"}, output.Record)
+}
+
+func TestLayoutParserConfigBuildFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*LayoutParserConfig)
+		expected string
+	}{
+		{
+			"MissingLayout",
+			func(cfg *LayoutParserConfig) { cfg.Dialect = "log4j" },
+			"missing required field 'layout'",
+		},
+		{
+			"MissingDialect",
+			func(cfg *LayoutParserConfig) { cfg.Layout = "%m" },
+			"missing required field 'dialect'",
+		},
+		{
+			"InvalidDialect",
+			func(cfg *LayoutParserConfig) {
+				cfg.Layout = "%m"
+				cfg.Dialect = "java"
+			},
+			"invalid dialect 'java'",
+		},
+		{
+			"DateFormatWithoutPython",
+			func(cfg *LayoutParserConfig) {
+				cfg.Layout = "%d %m"
+				cfg.Dialect = "logback"
+				cfg.DateFormat = "%Y"
+			},
+			"`date_format` can only be used with the `python` dialect",
+		},
+		{
+			"UnsupportedConversion",
+			func(cfg *LayoutParserConfig) {
+				cfg.Layout = "%highlight{%p} %m"
+				cfg.Dialect = "log4j2"
+			},
+			"the conversion '%highlight' is not supported",
+		},
+		{
+			"InvalidTimeZone",
+			func(cfg *LayoutParserConfig) {
+				cfg.Layout = "%d{DEFAULT}{Not/A_Location} %m"
+				cfg.Dialect = "log4j2"
+			},
+			"invalid location 'Not/A_Location'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewLayoutParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestLayoutParserFailure(t *testing.T) {
+	parser := newTestParser(t, func(cfg *LayoutParserConfig) {
+		cfg.Dialect = "python"
+		cfg.Layout = "%(levelname)s:%(name)s:%(message)s"
+	})
+
+	_, err := parser.parse("a line without colons")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "value does not match the layout")
+
+	_, err = parser.parse(1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "type 'int' cannot be parsed with a layout")
+}
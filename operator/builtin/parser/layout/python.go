@@ -0,0 +1,183 @@
+package layout
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/observiq/stanza/operator/helper"
+)
+
+// pythonAttribute matches an attribute of a Python logging format, with its conversion flags, width and type.
+// For example, %(message)s, %(levelname)-8s and %(lineno)4d.
+var pythonAttribute = regexp.MustCompile(`^%\((\w+)\)([#0 +-]*)(\d+)?(?:\.\d+)?[hlL]?([diouxXeEfFgGcrsa])`)
+
+// pythonAttributes are the patterns of the attributes of Python log records
+var pythonAttributes = map[string]string{
+	"created":         `\d+(?:\.\d+)?`,
+	"filename":        `\S+?`,
+	"funcName":        `\S+?`,
+	"levelname":       `\S+?`,
+	"levelno":         `\d+`,
+	"lineno":          `\d+`,
+	"message":         anyText,
+	"module":          `\S+?`,
+	"msecs":           `\d+(?:\.\d+)?`,
+	"name":            `\S+?`,
+	"pathname":        `\S+?`,
+	"process":         `\d+`,
+	"processName":     `.*?`,
+	"relativeCreated": `\d+(?:\.\d+)?`,
+	"thread":          `\d+`,
+	"threadName":      `.*?`,
+	"taskName":        `.*?`,
+}
+
+// defaultAsctime is the regex of asctime when the formatter does not have a datefmt,
+// which separates the milliseconds from the seconds with a comma.
+const defaultAsctime = `\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2},\d{3}`
+
+// compilePythonFormat will generate the regex of the format of a Python logging Formatter
+func compilePythonFormat(format, dateFormat string) (*generatedLayout, error) {
+	b := newLayoutBuilder()
+	for i := 0; i < len(format); {
+		if format[i] != '%' {
+			next := strings.IndexByte(format[i:], '%')
+			if next == -1 {
+				next = len(format) - i
+			}
+			b.addLiteral(format[i : i+next])
+			i += next
+			continue
+		}
+
+		if strings.HasPrefix(format[i:], "%%") {
+			b.addLiteral("%")
+			i += 2
+			continue
+		}
+
+		match := pythonAttribute.FindStringSubmatch(format[i:])
+		if match == nil {
+			return nil, fmt.Errorf("invalid attribute at position %d", i)
+		}
+		i += len(match[0])
+
+		pad := noPadding
+		switch {
+		case match[3] != "" && strings.Contains(match[2], "-"):
+			pad = trailingPadding
+		case match[3] != "":
+			pad = leadingPadding
+		}
+
+		if err := addPythonAttribute(b, match[1], match[4], dateFormat, pad); err != nil {
+			return nil, err
+		}
+	}
+	return b.build(), nil
+}
+
+// addPythonAttribute will add an attribute of a format to the regex
+func addPythonAttribute(b *layoutBuilder, name, conversionType, dateFormat string, pad padding) error {
+	switch name {
+	case "asctime":
+		return addAsctime(b, dateFormat, pad)
+	case "created":
+		b.layout.timestamp = &timestampField{field: name, layoutType: helper.AutoKey}
+	case "levelname":
+		b.layout.severity = name
+	case "levelno":
+		if b.layout.severity == "" {
+			b.layout.severity = name
+		}
+	}
+
+	pattern, ok := pythonAttributes[name]
+	if !ok {
+		// Attributes added with extra are matched according to their type
+		switch conversionType {
+		case "d", "i", "u":
+			pattern = `-?\d+`
+		default:
+			pattern = `.*?`
+		}
+	}
+	return b.addField(name, pattern, pad)
+}
+
+// addAsctime will add the asctime attribute, which is formatted with the datefmt of the formatter.
+// A timestamp is parsed from asctime, unless the datefmt does not include the month and day.
+func addAsctime(b *layoutBuilder, dateFormat string, pad padding) error {
+	if dateFormat == "" {
+		b.layout.timestamp = &timestampField{field: "asctime", layout: "2006-01-02 15:04:05.000", layoutType: helper.GotimeKey, commaFraction: true}
+		return b.addField("asctime", defaultAsctime, pad)
+	}
+
+	regex, hasDate, err := convertStrftime(dateFormat)
+	if err != nil {
+		return err
+	}
+	if hasDate {
+		b.layout.timestamp = &timestampField{field: "asctime", layout: dateFormat, layoutType: helper.StrptimeKey}
+	}
+	return b.addField("asctime", regex, pad)
+}
+
+// strftimeDirectives are the regexes of the strftime directives that can be parsed as strptime layouts
+var strftimeDirectives = map[byte]string{
+	'Y': `\d{4}`,
+	'y': `\d{2}`,
+	'm': `\d{2}`,
+	'b': `[A-Za-z]{3}`,
+	'h': `[A-Za-z]{3}`,
+	'B': `[A-Za-z]+`,
+	'd': `\d{2}`,
+	'e': `[ \d]\d`,
+	'a': `[A-Za-z]{3}`,
+	'A': `[A-Za-z]+`,
+	'H': `\d{2}`,
+	'I': `\d{2}`,
+	'p': `[AP]M`,
+	'M': `\d{2}`,
+	'S': `\d{2}`,
+	'f': `\d{6}`,
+	'Z': `[A-Za-z]+`,
+	'z': `[+-]\d{4}`,
+	'F': `\d{4}-\d{2}-\d{2}`,
+	'T': `\d{2}:\d{2}:\d{2}`,
+	'%': `%`,
+}
+
+// convertStrftime will convert a strftime format to a regex, and report whether it includes the month and day
+func convertStrftime(format string) (string, bool, error) {
+	var regex strings.Builder
+	hasMonth, hasDay := false, false
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			regex.WriteString(regexp.QuoteMeta(format[i : i+1]))
+			continue
+		}
+
+		if i+1 == len(format) {
+			return "", false, fmt.Errorf("date format '%s' ends with '%%'", format)
+		}
+		i++
+		directive := format[i]
+		pattern, ok := strftimeDirectives[directive]
+		if !ok {
+			return "", false, fmt.Errorf("the directive '%%%c' of date format '%s' is not supported", directive, format)
+		}
+		regex.WriteString("(?:" + pattern + ")")
+
+		switch directive {
+		case 'm', 'b', 'h', 'B':
+			hasMonth = true
+		case 'd', 'e':
+			hasDay = true
+		case 'F':
+			hasMonth, hasDay = true, true
+		}
+	}
+	return regex.String(), hasMonth && hasDay, nil
+}
@@ -0,0 +1,139 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilePythonFormat(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   string
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			"BasicFormat",
+			"%(levelname)s:%(name)s:%(message)s",
+			"WARNING:root:retrying: attempt 2",
+			map[string]interface{}{"levelname": "WARNING", "name": "root", "message": "retrying: attempt 2"},
+		},
+		{
+			"Padding",
+			"%(levelname)-8s|%(lineno)4d|%(message)s",
+			"INFO    |  42|started",
+			map[string]interface{}{"levelname": "INFO", "lineno": "42", "message": "started"},
+		},
+		{
+			"Attributes",
+			"%(created)f %(process)d %(processName)s %(threadName)s %(module)s.%(funcName)s %(pathname)s %(message)s",
+			"1600942333.456789 123 MainProcess Thread-1 app.run /srv/app.py done",
+			map[string]interface{}{
+				"created":     "1600942333.456789",
+				"process":     "123",
+				"processName": "MainProcess",
+				"threadName":  "Thread-1",
+				"module":      "app",
+				"funcName":    "run",
+				"pathname":    "/srv/app.py",
+				"message":     "done",
+			},
+		},
+		{
+			"Extra",
+			"%(request_id)s %(status)d %(message)s 100%%",
+			"abc-123 200 served 100%",
+			map[string]interface{}{"request_id": "abc-123", "status": "200", "message": "served"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			generated, err := compilePythonFormat(tc.format, "")
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parseGenerated(t, generated, tc.input))
+		})
+	}
+}
+
+func TestCompilePythonFormatTimestamp(t *testing.T) {
+	cases := []struct {
+		name       string
+		format     string
+		dateFormat string
+		expected   *timestampField
+	}{
+		{
+			"DefaultAsctime",
+			"%(asctime)s %(message)s",
+			"",
+			&timestampField{field: "asctime", layout: "2006-01-02 15:04:05.000", layoutType: helper.GotimeKey, commaFraction: true},
+		},
+		{
+			"DateFormat",
+			"%(asctime)s %(message)s",
+			"%Y-%m-%dT%H:%M:%S%z",
+			&timestampField{field: "asctime", layout: "%Y-%m-%dT%H:%M:%S%z", layoutType: helper.StrptimeKey},
+		},
+		{
+			"TimeOnly",
+			"%(asctime)s %(message)s",
+			"%H:%M:%S",
+			nil,
+		},
+		{
+			"Created",
+			"%(created)f %(message)s",
+			"",
+			&timestampField{field: "created", layoutType: helper.AutoKey},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			generated, err := compilePythonFormat(tc.format, tc.dateFormat)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, generated.timestamp)
+		})
+	}
+}
+
+func TestCompilePythonFormatSeverity(t *testing.T) {
+	generated, err := compilePythonFormat("%(levelno)s %(levelname)s %(message)s", "")
+	require.NoError(t, err)
+	require.Equal(t, "levelname", generated.severity)
+
+	generated, err = compilePythonFormat("%(levelno)s %(message)s", "")
+	require.NoError(t, err)
+	require.Equal(t, "levelno", generated.severity)
+}
+
+func TestCompilePythonFormatFailure(t *testing.T) {
+	cases := []struct {
+		name       string
+		format     string
+		dateFormat string
+		expected   string
+	}{
+		{"MissingType", "done %(message)", "", "invalid attribute at position 5"},
+		{"BraceStyle", "{levelname} %(message)s", "", ""},
+		{"Duplicate", "%(message)s %(message)s", "", "the field 'message' is in the layout more than once"},
+		{"UnsupportedDirective", "%(asctime)s %(message)s", "%Y-%j", "the directive '%j' of date format '%Y-%j' is not supported"},
+		{"TrailingPercent", "%(asctime)s %(message)s", "%Y %", "date format '%Y %' ends with '%'"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := compilePythonFormat(tc.format, tc.dateFormat)
+			if tc.expected == "" {
+				// Text outside of attributes is matched literally
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
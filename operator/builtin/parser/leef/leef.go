@@ -0,0 +1,170 @@
+package leef
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("leef_parser", func() operator.Builder { return NewLEEFParserConfig("") })
+}
+
+const (
+	leefPrefix       = "LEEF:"
+	defaultDelimiter = "\t"
+	extensionsField  = "extensions"
+	prefixField      = "prefix"
+)
+
+// headerFields are the names of the fields of a LEEF header, in order
+var headerFields = []string{
+	"version",
+	"device_vendor",
+	"device_product",
+	"device_version",
+	"event_id",
+}
+
+// NewLEEFParserConfig creates a new LEEF parser config with default values
+func NewLEEFParserConfig(operatorID string) *LEEFParserConfig {
+	return &LEEFParserConfig{
+		ParserConfig: helper.NewParserConfig(operatorID, "leef_parser"),
+	}
+}
+
+// LEEFParserConfig is the configuration of a LEEF parser operator.
+type LEEFParserConfig struct {
+	helper.ParserConfig `yaml:",inline"`
+}
+
+// Build will build a LEEF parser operator.
+func (c LEEFParserConfig) Build(context operator.BuildContext) (operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	leefParser := &LEEFParser{
+		ParserOperator: parserOperator,
+	}
+
+	return leefParser, nil
+}
+
+// LEEFParser is an operator that parses IBM Log Event Extended Format messages.
+type LEEFParser struct {
+	helper.ParserOperator
+}
+
+// Process will parse an entry as a LEEF message.
+func (l *LEEFParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return l.ParserOperator.ProcessWith(ctx, entry, l.parse)
+}
+
+// parse will parse a value as a LEEF message.
+func (l *LEEFParser) parse(value interface{}) (interface{}, error) {
+	var message string
+	switch v := value.(type) {
+	case string:
+		message = v
+	case []byte:
+		message = string(v)
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed as LEEF", value)
+	}
+
+	// Messages are often sent with a syslog header, which is kept as the prefix
+	start := strings.Index(message, leefPrefix)
+	if start == -1 {
+		return nil, fmt.Errorf("value is not a LEEF message")
+	}
+
+	parsedValues := make(map[string]interface{}, len(headerFields)+2)
+	if prefix := strings.TrimSpace(message[:start]); prefix != "" {
+		parsedValues[prefixField] = prefix
+	}
+
+	parts := strings.SplitN(message[start+len(leefPrefix):], "|", len(headerFields)+1)
+	if len(parts) != len(headerFields)+1 {
+		return nil, errors.NewError(
+			fmt.Sprintf("LEEF header has %d of %d fields", len(parts)-1, len(headerFields)),
+			"ensure that the message has a complete LEEF header ending with a pipe",
+		)
+	}
+	for i, field := range headerFields {
+		parsedValues[field] = parts[i]
+	}
+	attributes := parts[len(headerFields)]
+
+	// Version 2.0 adds the delimiter of the attributes to the header
+	delimiter := defaultDelimiter
+	if strings.HasPrefix(parts[0], "2.") {
+		var err error
+		delimiter, attributes, err = splitDelimiter(attributes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parsedValues[extensionsField] = parseAttributes(attributes, delimiter)
+	return parsedValues, nil
+}
+
+// splitDelimiter will split the delimiter field of a LEEF 2.0 header from the attributes.
+// The delimiter is a single character, or its code in hexadecimal like x09 or 0x09.
+// A header without a delimiter field uses the default delimiter.
+func splitDelimiter(attributes string) (string, string, error) {
+	index := strings.Index(attributes, "|")
+	if index == -1 {
+		return defaultDelimiter, attributes, nil
+	}
+
+	field := attributes[:index]
+	rest := attributes[index+1:]
+
+	switch {
+	case field == "":
+		return defaultDelimiter, rest, nil
+	case strings.Contains(field, "="):
+		// Without a delimiter field, the pipe belongs to an attribute
+		return defaultDelimiter, attributes, nil
+	case utf8.RuneCountInString(field) == 1:
+		return field, rest, nil
+	case strings.HasPrefix(field, "0x") || strings.HasPrefix(field, "x"):
+		code, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(field, "0"), "x"), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return "", "", errors.NewError(
+				fmt.Sprintf("invalid LEEF delimiter '%s'", field),
+				"ensure that the delimiter is a single character or a hexadecimal character code like x09",
+			)
+		}
+		return string(rune(code)), rest, nil
+	default:
+		return "", "", errors.NewError(
+			fmt.Sprintf("invalid LEEF delimiter '%s'", field),
+			"ensure that the delimiter is a single character or a hexadecimal character code like x09",
+		)
+	}
+}
+
+// parseAttributes will parse the key value pairs of LEEF attributes.
+// Values end at the delimiter, and may contain equals signs.
+func parseAttributes(attributes, delimiter string) map[string]interface{} {
+	parsedAttributes := map[string]interface{}{}
+	for _, pair := range strings.Split(strings.TrimRight(attributes, "\r\n"), delimiter) {
+		index := strings.Index(pair, "=")
+		if index <= 0 {
+			continue
+		}
+		parsedAttributes[pair[:index]] = pair[index+1:]
+	}
+	return parsedAttributes
+}
@@ -0,0 +1,218 @@
+package leef
+
+import (
+	"context"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParser(t *testing.T) *LEEFParser {
+	cfg := NewLEEFParserConfig("test")
+	op, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return op.(*LEEFParser)
+}
+
+func TestLEEFImplementations(t *testing.T) {
+	require.Implements(t, (*operator.Operator)(nil), new(LEEFParser))
+}
+
+func TestLEEFParser(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			"Version1",
+			"LEEF:1.0|Microsoft|MSExchange|4.0 SP1|15345|src=192.0.2.0\tdst=172.50.123.1\tsev=5\tcat=anomaly\tmsg=this is a message",
+			map[string]interface{}{
+				"version":        "1.0",
+				"device_vendor":  "Microsoft",
+				"device_product": "MSExchange",
+				"device_version": "4.0 SP1",
+				"event_id":       "15345",
+				"extensions": map[string]interface{}{
+					"src": "192.0.2.0",
+					"dst": "172.50.123.1",
+					"sev": "5",
+					"cat": "anomaly",
+					"msg": "this is a message",
+				},
+			},
+		},
+		{
+			"Version2CharDelimiter",
+			"LEEF:2.0|Lancope|StealthWatch|1.0|41|^|src=10.0.1.8^dst=10.0.0.5^sev=5^query=a=b",
+			map[string]interface{}{
+				"version":        "2.0",
+				"device_vendor":  "Lancope",
+				"device_product": "StealthWatch",
+				"device_version": "1.0",
+				"event_id":       "41",
+				"extensions": map[string]interface{}{
+					"src":   "10.0.1.8",
+					"dst":   "10.0.0.5",
+					"sev":   "5",
+					"query": "a=b",
+				},
+			},
+		},
+		{
+			"Version2HexDelimiter",
+			"LEEF:2.0|Lancope|StealthWatch|1.0|41|0x5e|src=10.0.1.8^dst=10.0.0.5",
+			map[string]interface{}{
+				"version":        "2.0",
+				"device_vendor":  "Lancope",
+				"device_product": "StealthWatch",
+				"device_version": "1.0",
+				"event_id":       "41",
+				"extensions":     map[string]interface{}{"src": "10.0.1.8", "dst": "10.0.0.5"},
+			},
+		},
+		{
+			"Version2ShortHexDelimiter",
+			"LEEF:2.0|Lancope|StealthWatch|1.0|41|x09|src=10.0.1.8\tdst=10.0.0.5",
+			map[string]interface{}{
+				"version":        "2.0",
+				"device_vendor":  "Lancope",
+				"device_product": "StealthWatch",
+				"device_version": "1.0",
+				"event_id":       "41",
+				"extensions":     map[string]interface{}{"src": "10.0.1.8", "dst": "10.0.0.5"},
+			},
+		},
+		{
+			"Version2EmptyDelimiter",
+			"LEEF:2.0|Lancope|StealthWatch|1.0|41||src=10.0.1.8\tdst=10.0.0.5",
+			map[string]interface{}{
+				"version":        "2.0",
+				"device_vendor":  "Lancope",
+				"device_product": "StealthWatch",
+				"device_version": "1.0",
+				"event_id":       "41",
+				"extensions":     map[string]interface{}{"src": "10.0.1.8", "dst": "10.0.0.5"},
+			},
+		},
+		{
+			"Version2WithoutDelimiter",
+			"LEEF:2.0|Lancope|StealthWatch|1.0|41|xff=10.0.1.8\tdst=10.0.0.5\tpath=a|b",
+			map[string]interface{}{
+				"version":        "2.0",
+				"device_vendor":  "Lancope",
+				"device_product": "StealthWatch",
+				"device_version": "1.0",
+				"event_id":       "41",
+				"extensions":     map[string]interface{}{"xff": "10.0.1.8", "dst": "10.0.0.5", "path": "a|b"},
+			},
+		},
+		{
+			"SyslogPrefix",
+			[]byte("<13>Jan 18 11:07:53 192.168.1.1 LEEF:1.0|QRadar|QRM|1.0|NEW_PORT_DISCOVERD|src=172.5.6.67\tdst=172.50.123.1\n"),
+			map[string]interface{}{
+				"prefix":         "<13>Jan 18 11:07:53 192.168.1.1",
+				"version":        "1.0",
+				"device_vendor":  "QRadar",
+				"device_product": "QRM",
+				"device_version": "1.0",
+				"event_id":       "NEW_PORT_DISCOVERD",
+				"extensions":     map[string]interface{}{"src": "172.5.6.67", "dst": "172.50.123.1"},
+			},
+		},
+		{
+			"NoAttributes",
+			"LEEF:1.0|Vendor|Product|1.0|100|",
+			map[string]interface{}{
+				"version":        "1.0",
+				"device_vendor":  "Vendor",
+				"device_product": "Product",
+				"device_version": "1.0",
+				"event_id":       "100",
+				"extensions":     map[string]interface{}{},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t)
+			parsed, err := parser.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestLEEFParserProcess(t *testing.T) {
+	parser := newTestParser(t)
+
+	var output *entry.Entry
+	mockOutput := &testutil.Operator{}
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		output = args[1].(*entry.Entry)
+	}).Return(nil)
+	parser.OutputOperators = []operator.Operator{mockOutput}
+
+	e := entry.New()
+	e.Record = "LEEF:1.0|Vendor|Product|1.0|100|src=10.0.0.1"
+	err := parser.Process(context.Background(), e)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"version":        "1.0",
+		"device_vendor":  "Vendor",
+		"device_product": "Product",
+		"device_version": "1.0",
+		"event_id":       "100",
+		"extensions":     map[string]interface{}{"src": "10.0.0.1"},
+	}
+	require.Equal(t, expected, output.Record)
+}
+
+func TestLEEFParserFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    interface{}
+		expected string
+	}{
+		{
+			"NotLEEF",
+			"just a regular log line",
+			"value is not a LEEF message",
+		},
+		{
+			"IncompleteHeader",
+			"LEEF:1.0|Vendor|Product|1.0",
+			"LEEF header has 3 of 5 fields",
+		},
+		{
+			"InvalidHexDelimiter",
+			"LEEF:2.0|Vendor|Product|1.0|100|xZZ|src=10.0.0.1",
+			"invalid LEEF delimiter 'xZZ'",
+		},
+		{
+			"InvalidDelimiter",
+			"LEEF:2.0|Vendor|Product|1.0|100|tab|src=10.0.0.1",
+			"invalid LEEF delimiter 'tab'",
+		},
+		{
+			"InvalidType",
+			map[string]interface{}{},
+			"type 'map[string]interface {}' cannot be parsed as LEEF",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t)
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
@@ -26,7 +26,8 @@ func NewRegexParserConfig(operatorID string) *RegexParserConfig {
 type RegexParserConfig struct {
 	helper.ParserConfig `yaml:",inline"`
 
-	Regex string `json:"regex" yaml:"regex"`
+	Regex         string `json:"regex"          yaml:"regex"`
+	PreserveOrder bool   `json:"preserve_order" yaml:"preserve_order"`
 }
 
 // Build will build a regex parser operator.
@@ -61,6 +62,7 @@ func (c RegexParserConfig) Build(context operator.BuildContext) (operator.Operat
 	regexParser := &RegexParser{
 		ParserOperator: parserOperator,
 		regexp:         r,
+		preserveOrder:  c.PreserveOrder,
 	}
 
 	return regexParser, nil
@@ -69,7 +71,8 @@ func (c RegexParserConfig) Build(context operator.BuildContext) (operator.Operat
 // RegexParser is an operator that parses regex in an entry.
 type RegexParser struct {
 	helper.ParserOperator
-	regexp *regexp.Regexp
+	regexp        *regexp.Regexp
+	preserveOrder bool
 }
 
 // Process will parse an entry for regex.
@@ -100,6 +103,16 @@ func (r *RegexParser) parse(value interface{}) (interface{}, error) {
 		return nil, fmt.Errorf("type '%T' cannot be parsed as regex", value)
 	}
 
+	if r.preserveOrder {
+		parsedValues := entry.NewOrderedMap()
+		for i, subexp := range r.regexp.SubexpNames() {
+			if i != 0 && subexp != "" {
+				parsedValues.Set(subexp, matches[i])
+			}
+		}
+		return parsedValues, nil
+	}
+
 	parsedValues := map[string]interface{}{}
 	for i, subexp := range r.regexp.SubexpNames() {
 		if i == 0 {
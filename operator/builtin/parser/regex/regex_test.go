@@ -135,6 +135,21 @@ func TestParserRegex(t *testing.T) {
 				"a": "b",
 			},
 		},
+		{
+			"PreserveOrder",
+			func(p *RegexParser) {
+				p.regexp = regexp.MustCompile("(?P<z>[a-z]+) (?P<m>[a-z]+) (?P<a>[a-z]+)")
+				p.preserveOrder = true
+			},
+			"one two three",
+			func() *entry.OrderedMap {
+				m := entry.NewOrderedMap()
+				m.Set("z", "one")
+				m.Set("m", "two")
+				m.Set("a", "three")
+				return m
+			}(),
+		},
 	}
 
 	for _, tc := range cases {
@@ -55,8 +55,13 @@ type SeverityParserOperator struct {
 
 // Process will parse time from an entry.
 func (p *SeverityParserOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ProcessIf(ctx, entry, p.process)
+}
+
+// process will parse severity from an entry that matches the if expression.
+func (p *SeverityParserOperator) process(ctx context.Context, entry *entry.Entry) error {
 	if err := p.Parse(ctx, entry); err != nil {
-		return errors.Wrap(err, "parse severity")
+		return p.HandleEntryError(ctx, entry, errors.Wrap(err, "parse severity"))
 	}
 
 	p.Write(ctx, entry)
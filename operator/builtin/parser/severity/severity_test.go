@@ -281,6 +281,30 @@ func TestSeverityParser(t *testing.T) {
 			mapping:    nil,
 			expected:   entry.Default, // not error
 		},
+		{
+			name:     "range-json-number",
+			sample:   42.0,
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 40, "max": 49}},
+			expected: entry.Error,
+		},
+		{
+			name:     "regex",
+			sample:   "E0042",
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"regex": `^E\d+`}},
+			expected: entry.Error,
+		},
+		{
+			name:       "preset-bunyan",
+			sample:     30.0,
+			mappingSet: "bunyan",
+			expected:   entry.Info,
+		},
+		{
+			name:       "preset-invalid",
+			sample:     "error",
+			mappingSet: "bunyun",
+			buildErr:   true,
+		},
 	}
 
 	rootField := entry.NewRecordField()
@@ -339,6 +363,7 @@ func runSeverityParseTest(t *testing.T, cfg *SeverityParserConfig, ent *entry.En
 func parseSeverityTestConfig(parseFrom entry.Field, preset string, mapping map[interface{}]interface{}) *SeverityParserConfig {
 	cfg := NewSeverityParserConfig("test_operator_id")
 	cfg.OutputIDs = []string{"output1"}
+	cfg.OnError = helper.DropOnError
 	cfg.SeverityParserConfig = helper.SeverityParserConfig{
 		ParseFrom: &parseFrom,
 		Preset:    preset,
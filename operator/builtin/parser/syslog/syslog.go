@@ -3,12 +3,15 @@ package syslog
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strconv"
 	"time"
 
 	sl "github.com/observiq/go-syslog/v3"
 	"github.com/observiq/go-syslog/v3/rfc3164"
 	"github.com/observiq/go-syslog/v3/rfc5424"
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
 	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/operator/helper"
 )
@@ -17,6 +20,24 @@ func init() {
 	operator.Register("syslog_parser", func() operator.Builder { return NewSyslogParserConfig("") })
 }
 
+const (
+	autoProtocol    = "auto"
+	rfc3164Protocol = "rfc3164"
+	rfc5424Protocol = "rfc5424"
+)
+
+// facilityNames are the keywords of the syslog facilities, indexed by number
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// severityNames are the keywords of the syslog severities, indexed by number
+var severityNames = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
 // NewSyslogParserConfig creates a new syslog parser config with default values
 func NewSyslogParserConfig(operatorID string) *SyslogParserConfig {
 	return &SyslogParserConfig{
@@ -29,50 +50,104 @@ type SyslogParserConfig struct {
 	helper.ParserConfig `yaml:",inline"`
 
 	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Lenient  bool   `json:"lenient,omitempty"  yaml:"lenient,omitempty"`
 }
 
 // Build will build a JSON parser operator.
 func (c SyslogParserConfig) Build(context operator.BuildContext) (operator.Operator, error) {
-	if c.ParserConfig.TimeParser == nil {
-		parseFromField := entry.NewRecordField("timestamp")
-		c.ParserConfig.TimeParser = &helper.TimeParser{
-			ParseFrom:  &parseFromField,
-			LayoutType: helper.NativeKey,
+	// RFC3164 timestamps do not include a time zone, so they are parsed in the
+	// location of the timestamp block, or in UTC if it does not have one
+	location := time.UTC
+	if c.ParserConfig.TimeParser != nil && c.ParserConfig.TimeParser.Location != "" {
+		var err error
+		location, err = time.LoadLocation(c.ParserConfig.TimeParser.Location)
+		if err != nil {
+			return nil, errors.NewError(
+				fmt.Sprintf("invalid location '%s'", c.ParserConfig.TimeParser.Location),
+				"specify a location from the IANA Time Zone database, such as 'America/Chicago' or 'UTC'",
+			)
+		}
+	}
+
+	timeParser := helper.TimeParser{LayoutType: helper.NativeKey}
+	if c.ParserConfig.TimeParser != nil {
+		timeParser = *c.ParserConfig.TimeParser
+	}
+
+	// A timestamp block without a layout sets the timestamp parsed from the message,
+	// which already refers to an exact point in time once it is parsed in the location
+	if timeParser.Layout == "" && (timeParser.LayoutType == "" || timeParser.LayoutType == helper.NativeKey) {
+		if timeParser.ParseFrom == nil {
+			parseFromField := entry.NewRecordField("timestamp")
+			timeParser.ParseFrom = &parseFromField
 		}
+		timeParser.LayoutType = helper.NativeKey
+		timeParser.Location = ""
 	}
+	c.ParserConfig.TimeParser = &timeParser
 
 	parserOperator, err := c.ParserConfig.Build(context)
 	if err != nil {
 		return nil, err
 	}
 
-	if c.Protocol == "" {
+	switch c.Protocol {
+	case autoProtocol, rfc3164Protocol, rfc5424Protocol:
+	case "":
 		return nil, fmt.Errorf("missing field 'protocol'")
+	default:
+		return nil, errors.NewError(
+			fmt.Sprintf("invalid protocol '%s'", c.Protocol),
+			fmt.Sprintf("specify a protocol of %s, %s or %s", autoProtocol, rfc3164Protocol, rfc5424Protocol),
+		)
 	}
 
 	syslogParser := &SyslogParser{
 		ParserOperator: parserOperator,
 		protocol:       c.Protocol,
+		location:       location,
+		lenient:        c.Lenient,
 	}
 
 	return syslogParser, nil
 }
 
-func buildMachine(protocol string) (sl.Machine, error) {
+func buildMachine(protocol string, location *time.Location, lenient bool) (sl.Machine, error) {
 	switch protocol {
-	case "rfc3164":
-		return rfc3164.NewMachine(), nil
-	case "rfc5424":
+	case rfc3164Protocol:
+		// RFC3164 timestamps do not include a time zone, so they are parsed in the configured location
+		options := []sl.MachineOption{rfc3164.WithLocaleTimezone(location)}
+		if lenient {
+			options = append(options, rfc3164.WithRFC3339())
+		}
+		return rfc3164.NewMachine(options...), nil
+	case rfc5424Protocol:
+		if lenient {
+			return rfc5424.NewMachine(rfc5424.WithBestEffort()), nil
+		}
 		return rfc5424.NewMachine(), nil
 	default:
 		return nil, fmt.Errorf("invalid protocol %s", protocol)
 	}
 }
 
+// rfc5424Header matches the start of RFC5424 messages, which have a version after the priority
+var rfc5424Header = regexp.MustCompile(`^<\d{1,3}>\d{1,2} `)
+
+// detectProtocol will detect the protocol of a syslog message
+func detectProtocol(bytes []byte) string {
+	if rfc5424Header.Match(bytes) {
+		return rfc5424Protocol
+	}
+	return rfc3164Protocol
+}
+
 // SyslogParser is an operator that parses syslog.
 type SyslogParser struct {
 	helper.ParserOperator
 	protocol string
+	location *time.Location
+	lenient  bool
 }
 
 // Process will parse an entry field as syslog.
@@ -87,12 +162,28 @@ func (s *SyslogParser) parse(value interface{}) (interface{}, error) {
 		return nil, err
 	}
 
-	machine, err := buildMachine(s.protocol)
+	protocol := s.protocol
+	if protocol == autoProtocol {
+		protocol = detectProtocol(bytes)
+	}
+
+	machine, err := buildMachine(protocol, s.location, s.lenient)
 	if err != nil {
 		return nil, err
 	}
 
 	slog, err := machine.Parse(bytes)
+
+	// In best effort mode, the parts of an RFC5424 message before an error are kept
+	if s.lenient && err != nil && protocol == rfc5424Protocol && slog != nil && slog.Valid() {
+		err = nil
+	}
+
+	// Messages that deviate from RFC3164 are parsed with a more tolerant pattern
+	if s.lenient && (err != nil || slog == nil) && protocol == rfc3164Protocol {
+		return s.parseLenientRFC3164(bytes)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -107,6 +198,74 @@ func (s *SyslogParser) parse(value interface{}) (interface{}, error) {
 	}
 }
 
+// lenientRFC3164 matches messages that deviate from RFC3164 in common ways. The priority is
+// optional, the timestamp may follow a sequence number and be marked with '*' or '.', as on
+// Cisco devices, and may have fractional seconds or a trailing colon. The hostname and the
+// application name are optional, and an RFC3339 timestamp may be used instead.
+var lenientRFC3164 = regexp.MustCompile(
+	`(?s)^(?:<(\d{1,3})>)?(?:\d+: )?[*.]?` +
+		`([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}(?:\.\d+)?|\d{4}-\d{2}-\d{2}T\S+?):?\s+` +
+		`(?:([^\s:\[\]]+)\s+)?` +
+		`(?:([^\s:\[\]]+)(?:\[([^\]]*)\])?:\s*)?` +
+		`(.*)$`,
+)
+
+// defaultPriority is the priority of messages without one, which is user.notice as described by RFC3164
+const defaultPriority = 13
+
+// parseLenientRFC3164 will parse a message that deviates from RFC3164.
+func (s *SyslogParser) parseLenientRFC3164(bytes []byte) (map[string]interface{}, error) {
+	match := lenientRFC3164.FindSubmatch(bytes)
+	if match == nil {
+		return nil, fmt.Errorf("message is not close enough to rfc3164 to be parsed")
+	}
+
+	priority := defaultPriority
+	if len(match[1]) > 0 {
+		priority, _ = strconv.Atoi(string(match[1]))
+		if priority > 191 {
+			return nil, fmt.Errorf("invalid priority %d", priority)
+		}
+	}
+
+	var timestamp time.Time
+	var err error
+	if stamp := string(match[2]); stamp[0] >= '0' && stamp[0] <= '9' {
+		timestamp, err = time.Parse(time.RFC3339Nano, stamp)
+	} else {
+		timestamp, err = time.ParseInLocation(time.Stamp, stamp, s.location)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "parse timestamp")
+	}
+
+	value := map[string]interface{}{
+		"timestamp": timestamp,
+		"priority":  priority,
+		"facility":  priority / 8,
+		"severity":  priority % 8,
+		"message":   string(match[6]),
+	}
+	for key, index := range map[string]int{"hostname": 3, "appname": 4, "proc_id": 5} {
+		if len(match[index]) > 0 {
+			value[key] = string(match[index])
+		}
+	}
+
+	s.addNames(value)
+	return value, nil
+}
+
+// addNames will add the names of the facility and severity of a message
+func (s *SyslogParser) addNames(message map[string]interface{}) {
+	if facility, ok := message["facility"].(int); ok && facility < len(facilityNames) {
+		message["facility_name"] = facilityNames[facility]
+	}
+	if severity, ok := message["severity"].(int); ok && severity < len(severityNames) {
+		message["severity_name"] = severityNames[severity]
+	}
+}
+
 // parseRFC3164 will parse an RFC3164 syslog message.
 func (s *SyslogParser) parseRFC3164(syslogMessage *rfc3164.SyslogMessage) (map[string]interface{}, error) {
 	value := map[string]interface{}{
@@ -170,15 +329,30 @@ func (s *SyslogParser) toSafeMap(message map[string]interface{}) (map[string]int
 				delete(message, key)
 				continue
 			}
-			message[key] = *v
+			message[key] = toStructuredData(*v)
 		default:
 			return nil, fmt.Errorf("key %s has unknown field of type %T", key, v)
 		}
 	}
 
+	s.addNames(message)
 	return message, nil
 }
 
+// toStructuredData will convert the structured data of a message to a nested
+// map of SD-IDs to their parameters, so that they can be accessed with fields
+func toStructuredData(structuredData map[string]map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(structuredData))
+	for id, params := range structuredData {
+		values := make(map[string]interface{}, len(params))
+		for name, value := range params {
+			values[name] = value
+		}
+		result[id] = values
+	}
+	return result
+}
+
 func toBytes(value interface{}) ([]byte, error) {
 	switch v := value.(type) {
 	case string:
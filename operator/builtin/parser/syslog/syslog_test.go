@@ -7,6 +7,7 @@ import (
 
 	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
 	"github.com/observiq/stanza/testutil"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -36,12 +37,14 @@ func TestSyslogParser(t *testing.T) {
 			"<34>Jan 12 06:30:00 1.2.3.4 apache_server: test message",
 			time.Date(time.Now().Year(), 1, 12, 6, 30, 0, 0, time.UTC),
 			map[string]interface{}{
-				"appname":  "apache_server",
-				"facility": 4,
-				"hostname": "1.2.3.4",
-				"message":  "test message",
-				"priority": 34,
-				"severity": 2,
+				"appname":       "apache_server",
+				"facility":      4,
+				"hostname":      "1.2.3.4",
+				"message":       "test message",
+				"priority":      34,
+				"severity":      2,
+				"facility_name": "auth",
+				"severity_name": "crit",
 			},
 		},
 		{
@@ -54,12 +57,14 @@ func TestSyslogParser(t *testing.T) {
 			[]byte("<34>Jan 12 06:30:00 1.2.3.4 apache_server: test message"),
 			time.Date(time.Now().Year(), 1, 12, 6, 30, 0, 0, time.UTC),
 			map[string]interface{}{
-				"appname":  "apache_server",
-				"facility": 4,
-				"hostname": "1.2.3.4",
-				"message":  "test message",
-				"priority": 34,
-				"severity": 2,
+				"appname":       "apache_server",
+				"facility":      4,
+				"hostname":      "1.2.3.4",
+				"message":       "test message",
+				"priority":      34,
+				"severity":      2,
+				"facility_name": "auth",
+				"severity_name": "crit",
 			},
 		},
 		{
@@ -72,16 +77,18 @@ func TestSyslogParser(t *testing.T) {
 			`<86>1 2015-08-05T21:58:59.693Z 192.168.2.132 SecureAuth0 23108 ID52020 [SecureAuth@27389 UserHostAddress="192.168.2.132" Realm="SecureAuth0" UserID="Tester2" PEN="27389"] Found the user for retrieving user's profile`,
 			time.Date(2015, 8, 5, 21, 58, 59, 693000000, time.UTC),
 			map[string]interface{}{
-				"appname":  "SecureAuth0",
-				"facility": 10,
-				"hostname": "192.168.2.132",
-				"message":  "Found the user for retrieving user's profile",
-				"msg_id":   "ID52020",
-				"priority": 86,
-				"proc_id":  "23108",
-				"severity": 6,
-				"structured_data": map[string]map[string]string{
-					"SecureAuth@27389": {
+				"appname":       "SecureAuth0",
+				"facility":      10,
+				"facility_name": "authpriv",
+				"hostname":      "192.168.2.132",
+				"message":       "Found the user for retrieving user's profile",
+				"msg_id":        "ID52020",
+				"priority":      86,
+				"proc_id":       "23108",
+				"severity":      6,
+				"severity_name": "info",
+				"structured_data": map[string]interface{}{
+					"SecureAuth@27389": map[string]interface{}{
 						"PEN":             "27389",
 						"Realm":           "SecureAuth0",
 						"UserHostAddress": "192.168.2.132",
@@ -101,22 +108,128 @@ func TestSyslogParser(t *testing.T) {
 			`<86>1 2015-08-05T21:58:59.693Z 192.168.2.132 SecureAuth0 23108 ID52020 [verylongsdnamethatisgreaterthan32bytes@12345 UserHostAddress="192.168.2.132"] my message`,
 			time.Date(2015, 8, 5, 21, 58, 59, 693000000, time.UTC),
 			map[string]interface{}{
-				"appname":  "SecureAuth0",
-				"facility": 10,
-				"hostname": "192.168.2.132",
-				"message":  "my message",
-				"msg_id":   "ID52020",
-				"priority": 86,
-				"proc_id":  "23108",
-				"severity": 6,
-				"structured_data": map[string]map[string]string{
-					"verylongsdnamethatisgreaterthan32bytes@12345": {
+				"appname":       "SecureAuth0",
+				"facility":      10,
+				"facility_name": "authpriv",
+				"hostname":      "192.168.2.132",
+				"message":       "my message",
+				"msg_id":        "ID52020",
+				"priority":      86,
+				"proc_id":       "23108",
+				"severity":      6,
+				"severity_name": "info",
+				"structured_data": map[string]interface{}{
+					"verylongsdnamethatisgreaterthan32bytes@12345": map[string]interface{}{
 						"UserHostAddress": "192.168.2.132",
 					},
 				},
 				"version": 1,
 			},
 		},
+		{
+			"AutoRFC3164",
+			func() *SyslogParserConfig {
+				cfg := basicConfig()
+				cfg.Protocol = "auto"
+				return cfg
+			}(),
+			"<34>Jan 12 06:30:00 1.2.3.4 apache_server: test message",
+			time.Date(time.Now().Year(), 1, 12, 6, 30, 0, 0, time.UTC),
+			map[string]interface{}{
+				"appname":       "apache_server",
+				"facility":      4,
+				"hostname":      "1.2.3.4",
+				"message":       "test message",
+				"priority":      34,
+				"severity":      2,
+				"facility_name": "auth",
+				"severity_name": "crit",
+			},
+		},
+		{
+			"AutoRFC5424",
+			func() *SyslogParserConfig {
+				cfg := basicConfig()
+				cfg.Protocol = "auto"
+				return cfg
+			}(),
+			`<165>1 2015-08-05T21:58:59.693Z myhost myapp - - - test message`,
+			time.Date(2015, 8, 5, 21, 58, 59, 693000000, time.UTC),
+			map[string]interface{}{
+				"appname":       "myapp",
+				"facility":      20,
+				"facility_name": "local4",
+				"hostname":      "myhost",
+				"message":       "test message",
+				"priority":      165,
+				"severity":      5,
+				"severity_name": "notice",
+				"version":       1,
+			},
+		},
+		{
+			"LenientCisco",
+			func() *SyslogParserConfig {
+				cfg := basicConfig()
+				cfg.Protocol = "rfc3164"
+				cfg.Lenient = true
+				return cfg
+			}(),
+			"<189>123: *Mar  1 00:00:00.123: %SYS-5-CONFIG_I: Configured from console by vty0",
+			time.Date(time.Now().Year(), 3, 1, 0, 0, 0, 123000000, time.UTC),
+			map[string]interface{}{
+				"appname":       "%SYS-5-CONFIG_I",
+				"facility":      23,
+				"facility_name": "local7",
+				"message":       "Configured from console by vty0",
+				"priority":      189,
+				"severity":      5,
+				"severity_name": "notice",
+			},
+		},
+		{
+			"LenientMissingPriority",
+			func() *SyslogParserConfig {
+				cfg := basicConfig()
+				cfg.Protocol = "auto"
+				cfg.Lenient = true
+				return cfg
+			}(),
+			"Jan 12 06:30:00 myhost sshd[1234]: session opened",
+			time.Date(time.Now().Year(), 1, 12, 6, 30, 0, 0, time.UTC),
+			map[string]interface{}{
+				"appname":       "sshd",
+				"facility":      1,
+				"facility_name": "user",
+				"hostname":      "myhost",
+				"message":       "session opened",
+				"priority":      13,
+				"proc_id":       "1234",
+				"severity":      5,
+				"severity_name": "notice",
+			},
+		},
+		{
+			"LenientRFC3339",
+			func() *SyslogParserConfig {
+				cfg := basicConfig()
+				cfg.Protocol = "rfc3164"
+				cfg.Lenient = true
+				return cfg
+			}(),
+			"<30>2020-09-24T10:12:13.456Z myhost app: test message",
+			time.Date(2020, 9, 24, 10, 12, 13, 456000000, time.UTC),
+			map[string]interface{}{
+				"appname":       "app",
+				"facility":      3,
+				"facility_name": "daemon",
+				"hostname":      "myhost",
+				"message":       "test message",
+				"priority":      30,
+				"severity":      6,
+				"severity_name": "info",
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -144,9 +257,166 @@ func TestSyslogParser(t *testing.T) {
 			case e := <-entryChan:
 				require.Equal(t, e.Record, tc.expectedRecord)
 				require.Equal(t, tc.expectedTimestamp, e.Timestamp)
+				// The severity is only set when a severity block is configured
+				require.Equal(t, entry.Default, e.Severity)
 			case <-time.After(time.Second):
 				require.FailNow(t, "Timed out waiting for entry to be processed")
 			}
 		})
 	}
 }
+
+func TestSyslogParserLocation(t *testing.T) {
+	cases := []struct {
+		name       string
+		timeParser *helper.TimeParser
+	}{
+		{"Location", &helper.TimeParser{Location: "America/Chicago"}},
+		{"NativeLayoutType", &helper.TimeParser{LayoutType: helper.NativeKey, Location: "America/Chicago"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			testSyslogParserLocation(t, tc.timeParser)
+		})
+	}
+}
+
+func testSyslogParserLocation(t *testing.T, timeParser *helper.TimeParser) {
+	cfg := NewSyslogParserConfig("test_operator_id")
+	cfg.OutputIDs = []string{"output1"}
+	cfg.Protocol = "rfc3164"
+	cfg.TimeParser = timeParser
+
+	newOperator, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	syslogParser := newOperator.(*SyslogParser)
+
+	mockOutput := testutil.NewMockOperator("output1")
+	entryChan := make(chan *entry.Entry, 1)
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		entryChan <- args.Get(1).(*entry.Entry)
+	}).Return(nil)
+
+	err = syslogParser.SetOutputs([]operator.Operator{mockOutput})
+	require.NoError(t, err)
+
+	newEntry := entry.New()
+	newEntry.Record = "<34>Jan 12 06:30:00 1.2.3.4 apache_server: test message"
+	err = syslogParser.Process(context.Background(), newEntry)
+	require.NoError(t, err)
+
+	location, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	expected := time.Date(time.Now().In(location).Year(), 1, 12, 6, 30, 0, 0, location)
+
+	select {
+	case e := <-entryChan:
+		require.True(t, expected.Equal(e.Timestamp), "expected %s, got %s", expected, e.Timestamp)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry to be processed")
+	}
+}
+
+func TestSyslogParserInvalidLocation(t *testing.T) {
+	cfg := NewSyslogParserConfig("test_operator_id")
+	cfg.OutputIDs = []string{"output1"}
+	cfg.Protocol = "rfc3164"
+	cfg.TimeParser = &helper.TimeParser{Location: "Not/A_Location"}
+
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid location 'Not/A_Location'")
+}
+
+func TestSyslogParserSeverity(t *testing.T) {
+	cfg := NewSyslogParserConfig("test_operator_id")
+	cfg.OutputIDs = []string{"output1"}
+	cfg.Protocol = "rfc3164"
+	parseFrom := entry.NewRecordField("severity_name")
+	cfg.SeverityParserConfig = &helper.SeverityParserConfig{
+		ParseFrom: &parseFrom,
+		Preserve:  true,
+		Preset:    "syslog",
+	}
+
+	newOperator, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	syslogParser := newOperator.(*SyslogParser)
+
+	mockOutput := testutil.NewMockOperator("output1")
+	entryChan := make(chan *entry.Entry, 1)
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		entryChan <- args.Get(1).(*entry.Entry)
+	}).Return(nil)
+
+	err = syslogParser.SetOutputs([]operator.Operator{mockOutput})
+	require.NoError(t, err)
+
+	newEntry := entry.New()
+	newEntry.Record = "<34>Jan 12 06:30:00 1.2.3.4 apache_server: test message"
+	err = syslogParser.Process(context.Background(), newEntry)
+	require.NoError(t, err)
+
+	select {
+	case e := <-entryChan:
+		require.Equal(t, entry.Critical, e.Severity)
+		require.Equal(t, "crit", e.SeverityText)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry to be processed")
+	}
+}
+
+func TestSyslogParserFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		protocol string
+		lenient  bool
+		input    string
+		expected string
+	}{
+		{"StrictMissingPriority", "rfc3164", false, "Jan 12 06:30:00 myhost app: test message", "expecting a priority value"},
+		{"LenientInvalidPriority", "rfc3164", true, "<192>Jan 12 06:30:00 myhost app: test message", "invalid priority 192"},
+		{"LenientMissingTimestamp", "rfc3164", true, "<34>myhost app: test message", "not close enough to rfc3164"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewSyslogParserConfig("test_operator_id")
+			cfg.OutputIDs = []string{"output1"}
+			cfg.Protocol = tc.protocol
+			cfg.Lenient = tc.lenient
+
+			newOperator, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			syslogParser := newOperator.(*SyslogParser)
+
+			_, err = syslogParser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestSyslogParserBuildFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		protocol string
+		expected string
+	}{
+		{"MissingProtocol", "", "missing field 'protocol'"},
+		{"InvalidProtocol", "rfc9999", "invalid protocol 'rfc9999'"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewSyslogParserConfig("test_operator_id")
+			cfg.OutputIDs = []string{"output1"}
+			cfg.Protocol = tc.protocol
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
@@ -59,8 +59,13 @@ func (t *TimeParserOperator) CanOutput() bool {
 
 // Process will parse time from an entry.
 func (t *TimeParserOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return t.ProcessIf(ctx, entry, t.process)
+}
+
+// process will parse time from an entry that matches the if expression.
+func (t *TimeParserOperator) process(ctx context.Context, entry *entry.Entry) error {
 	if err := t.Parse(ctx, entry); err != nil {
-		return errors.Wrap(err, "parse timestamp")
+		return t.HandleEntryError(ctx, entry, errors.Wrap(err, "parse timestamp"))
 	}
 	t.Write(ctx, entry)
 	return nil
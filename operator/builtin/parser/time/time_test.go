@@ -377,6 +377,96 @@ func TestTimeErrors(t *testing.T) {
 	}
 }
 
+func TestTimeParserLocationAndFallbacks(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		sample   string
+		expected time.Time
+	}{
+		{
+			name:     "layout",
+			sample:   "2020-06-09 15:39:58",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, chicago),
+		},
+		{
+			name:     "fallback",
+			sample:   "06/09/2020 15:39:58",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, chicago),
+		},
+		{
+			name:     "fallback-with-zone",
+			sample:   "09/Jun/2020:15:39:58 +0000",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC),
+		},
+	}
+
+	someField := entry.NewRecordField("some_field")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := parseTimeTestConfig(helper.StrptimeKey, "%Y-%m-%d %H:%M:%S", someField)
+			cfg.FallbackLayouts = []string{"%m/%d/%Y %H:%M:%S", "%d/%b/%Y:%H:%M:%S %z"}
+			cfg.Location = "America/Chicago"
+			t.Run("parse", runTimeParseTest(t, cfg, makeTestEntry(someField, tc.sample), false, false, tc.expected))
+		})
+	}
+}
+
+func TestTimeParserAuto(t *testing.T) {
+	someField := entry.NewRecordField("some_field")
+	samples := []interface{}{
+		"2020-06-09T15:39:58Z",
+		"09/Jun/2020:15:39:58 +0000",
+		"Tue, 09 Jun 2020 15:39:58 +0000",
+		"1591717198",
+		int64(1591717198000),
+	}
+
+	expected := time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC)
+	for _, sample := range samples {
+		cfg := parseTimeTestConfig(helper.AutoKey, "", someField)
+		t.Run("parse", runTimeParseTest(t, cfg, makeTestEntry(someField, sample), false, false, expected))
+	}
+}
+
+func TestTimeParserBuildFailure(t *testing.T) {
+	someField := entry.NewRecordField("some_field")
+
+	invalidLocation := parseTimeTestConfig(helper.StrptimeKey, "%Y-%m-%d", someField)
+	invalidLocation.Location = "Not/A_Location"
+	t.Run("invalid-location", runTimeParseTest(t, invalidLocation, makeTestEntry(someField, ""), true, false, time.Now()))
+
+	epochLocation := parseTimeTestConfig(helper.EpochKey, "s", someField)
+	epochLocation.Location = "America/Chicago"
+	t.Run("epoch-location", runTimeParseTest(t, epochLocation, makeTestEntry(someField, ""), true, false, time.Now()))
+
+	epochFallback := parseTimeTestConfig(helper.EpochKey, "s", someField)
+	epochFallback.FallbackLayouts = []string{"ms"}
+	t.Run("epoch-fallback-layouts", runTimeParseTest(t, epochFallback, makeTestEntry(someField, ""), true, false, time.Now()))
+
+	duplicateLayout := parseTimeTestConfig(helper.StrptimeKey, "%Y-%m-%d", someField)
+	duplicateLayout.FallbackLayouts = []string{"%Y-%m-%d"}
+	t.Run("duplicate-layout", runTimeParseTest(t, duplicateLayout, makeTestEntry(someField, ""), true, false, time.Now()))
+}
+
+func TestTimeParserIf(t *testing.T) {
+	someField := entry.NewRecordField("some_field")
+	expected := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	skipCfg := parseTimeTestConfig("epoch", "s", someField)
+	skipCfg.IfExpr = `$record.some_field == 0`
+	skipEntry := makeTestEntry(someField, "not-a-number")
+	skipEntry.Timestamp = expected
+	t.Run("skip", runTimeParseTest(t, skipCfg, skipEntry, false, false, expected))
+
+	parseCfg := parseTimeTestConfig("epoch", "s", someField)
+	parseCfg.IfExpr = `$record.some_field != "not-a-number"`
+	parseEntry := makeTestEntry(someField, expected.Unix())
+	t.Run("parse", runTimeParseTest(t, parseCfg, parseEntry, false, false, expected))
+}
+
 func makeTestEntry(field entry.Field, value interface{}) *entry.Entry {
 	e := entry.New()
 	e.Set(field, value)
@@ -428,6 +518,7 @@ func runLossyTimeParseTest(t *testing.T, cfg *TimeParserConfig, ent *entry.Entry
 func parseTimeTestConfig(layoutType, layout string, parseFrom entry.Field) *TimeParserConfig {
 	cfg := NewTimeParserConfig("test_operator_id")
 	cfg.OutputIDs = []string{"output1"}
+	cfg.OnError = helper.DropOnError
 	cfg.TimeParser = helper.TimeParser{
 		LayoutType: layoutType,
 		Layout:     layout,
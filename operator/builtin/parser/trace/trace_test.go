@@ -0,0 +1,118 @@
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceParserOperator(t *testing.T) {
+	cases := []struct {
+		name        string
+		config      func(*TraceParserConfig)
+		record      map[string]interface{}
+		expected    *entry.Entry
+		expectedErr bool
+	}{
+		{
+			"Defaults",
+			func(cfg *TraceParserConfig) {},
+			map[string]interface{}{
+				"trace_id":    "480140f3d770a5ae32f0a22b6a812cff",
+				"span_id":     "92c3792d54ba94f3",
+				"trace_flags": "01",
+			},
+			&entry.Entry{
+				TraceID:    []byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+				SpanID:     []byte{0x92, 0xc3, 0x79, 0x2d, 0x54, 0xba, 0x94, 0xf3},
+				TraceFlags: []byte{0x01},
+				Record:     map[string]interface{}{},
+			},
+			false,
+		},
+		{
+			"CustomFields",
+			func(cfg *TraceParserConfig) {
+				traceID := entry.NewRecordField("trace", "id")
+				cfg.TraceID.ParseFrom = &traceID
+				spanID := entry.NewRecordField("trace", "span")
+				cfg.SpanID.ParseFrom = &spanID
+				cfg.Preserve = true
+			},
+			map[string]interface{}{
+				"trace": map[string]interface{}{
+					"id":   "480140f3d770a5ae32f0a22b6a812cff",
+					"span": "92c3792d54ba94f3",
+				},
+			},
+			&entry.Entry{
+				TraceID: []byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+				SpanID:  []byte{0x92, 0xc3, 0x79, 0x2d, 0x54, 0xba, 0x94, 0xf3},
+				Record: map[string]interface{}{
+					"trace": map[string]interface{}{
+						"id":   "480140f3d770a5ae32f0a22b6a812cff",
+						"span": "92c3792d54ba94f3",
+					},
+				},
+			},
+			false,
+		},
+		{
+			"InvalidTraceID",
+			func(cfg *TraceParserConfig) {
+				cfg.OnError = helper.DropOnError
+			},
+			map[string]interface{}{
+				"trace_id": "480140f3",
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewTraceParserConfig("test_operator_id")
+			cfg.OutputIDs = []string{"output1"}
+			tc.config(cfg)
+
+			op, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+
+			mockOutput := &testutil.Operator{}
+			resultChan := make(chan *entry.Entry, 1)
+			mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				resultChan <- args.Get(1).(*entry.Entry)
+			}).Return(nil)
+
+			traceParser := op.(*TraceParserOperator)
+			traceParser.OutputOperators = []operator.Operator{mockOutput}
+
+			e := entry.New()
+			e.Record = tc.record
+			err = traceParser.Process(context.Background(), e)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			select {
+			case result := <-resultChan:
+				require.Equal(t, tc.expected.TraceID, result.TraceID)
+				require.Equal(t, tc.expected.SpanID, result.SpanID)
+				require.Equal(t, tc.expected.TraceFlags, result.TraceFlags)
+				require.Equal(t, tc.expected.Record, result.Record)
+			case <-time.After(time.Second):
+				require.FailNow(t, "Timed out waiting for entry to be processed")
+			}
+		})
+	}
+}
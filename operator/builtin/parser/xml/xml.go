@@ -0,0 +1,248 @@
+package xml
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("xml_parser", func() operator.Builder { return NewXMLParserConfig("") })
+}
+
+const (
+	// attribute handling
+	mergeAttributes = "merge"
+	dropAttributes  = "drop"
+
+	defaultAttributePrefix = "@"
+	defaultTextKey         = "#text"
+	defaultMaxDepth        = 100
+)
+
+// NewXMLParserConfig creates a new XML parser config with default values
+func NewXMLParserConfig(operatorID string) *XMLParserConfig {
+	return &XMLParserConfig{
+		ParserConfig:    helper.NewParserConfig(operatorID, "xml_parser"),
+		Attributes:      mergeAttributes,
+		AttributePrefix: defaultAttributePrefix,
+		TextKey:         defaultTextKey,
+		MaxDepth:        defaultMaxDepth,
+	}
+}
+
+// XMLParserConfig is the configuration of an XML parser operator.
+type XMLParserConfig struct {
+	helper.ParserConfig `yaml:",inline"`
+
+	Attributes         string   `json:"attributes,omitempty"          yaml:"attributes,omitempty"`
+	AttributePrefix    string   `json:"attribute_prefix"              yaml:"attribute_prefix"`
+	TextKey            string   `json:"text_key,omitempty"            yaml:"text_key,omitempty"`
+	ForceArray         []string `json:"force_array,omitempty"         yaml:"force_array,omitempty"`
+	PreserveWhitespace bool     `json:"preserve_whitespace,omitempty" yaml:"preserve_whitespace,omitempty"`
+	MaxDepth           int      `json:"max_depth,omitempty"           yaml:"max_depth,omitempty"`
+}
+
+// Build will build an XML parser operator.
+func (c XMLParserConfig) Build(context operator.BuildContext) (operator.Operator, error) {
+	parserOperator, err := c.ParserConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Attributes {
+	case mergeAttributes, dropAttributes:
+	default:
+		return nil, errors.NewError(
+			fmt.Sprintf("invalid attributes '%s'", c.Attributes),
+			fmt.Sprintf("specify one of %s or %s", mergeAttributes, dropAttributes),
+		)
+	}
+
+	if c.TextKey == "" {
+		return nil, fmt.Errorf("missing required field 'text_key'")
+	}
+
+	if c.MaxDepth <= 0 {
+		return nil, errors.NewError(
+			"max_depth must be greater than 0",
+			"specify a max_depth greater than 0",
+		)
+	}
+
+	forceArray := make(map[string]bool, len(c.ForceArray))
+	for _, name := range c.ForceArray {
+		forceArray[name] = true
+	}
+
+	xmlParser := &XMLParser{
+		ParserOperator:     parserOperator,
+		dropAttributes:     c.Attributes == dropAttributes,
+		attributePrefix:    c.AttributePrefix,
+		textKey:            c.TextKey,
+		forceArray:         forceArray,
+		preserveWhitespace: c.PreserveWhitespace,
+		maxDepth:           c.MaxDepth,
+	}
+
+	return xmlParser, nil
+}
+
+// XMLParser is an operator that parses XML.
+type XMLParser struct {
+	helper.ParserOperator
+	dropAttributes     bool
+	attributePrefix    string
+	textKey            string
+	forceArray         map[string]bool
+	preserveWhitespace bool
+	maxDepth           int
+}
+
+// Process will parse an entry for XML.
+func (x *XMLParser) Process(ctx context.Context, entry *entry.Entry) error {
+	return x.ParserOperator.ProcessWith(ctx, entry, x.parse)
+}
+
+// element is an XML element that is being parsed
+type element struct {
+	name   string
+	values map[string]interface{}
+	text   strings.Builder
+}
+
+// parse will parse a value as XML.
+//
+// Document type definitions are rejected, and only the predefined XML entities
+// are expanded, so entities can not be used to expand a small document into a large one.
+func (x *XMLParser) parse(value interface{}) (interface{}, error) {
+	var decoder *xml.Decoder
+	switch v := value.(type) {
+	case string:
+		decoder = xml.NewDecoder(strings.NewReader(v))
+	case []byte:
+		decoder = xml.NewDecoder(bytes.NewReader(v))
+	default:
+		return nil, fmt.Errorf("type '%T' cannot be parsed as XML", value)
+	}
+	decoder.Strict = true
+
+	var parsedValue map[string]interface{}
+	stack := make([]*element, 0, 8)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.Directive:
+			return nil, errors.NewError(
+				"XML directives such as document type definitions are not supported",
+				"remove the directive from the XML document",
+			)
+		case xml.StartElement:
+			if parsedValue != nil {
+				return nil, fmt.Errorf("XML document has more than one root element")
+			}
+			if len(stack) == x.maxDepth {
+				return nil, errors.NewError(
+					fmt.Sprintf("XML document is nested deeper than %d elements", x.maxDepth),
+					"increase max_depth if the document is expected to be nested this deeply",
+				)
+			}
+			stack = append(stack, x.startElement(t))
+		case xml.CharData:
+			if len(stack) != 0 {
+				stack[len(stack)-1].text.Write(t)
+			} else if len(bytes.TrimSpace(t)) != 0 {
+				return nil, fmt.Errorf("XML document has text outside of the root element")
+			}
+		case xml.EndElement:
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				parsedValue = map[string]interface{}{current.name: x.endElement(current)}
+			} else {
+				x.addChild(stack[len(stack)-1], current.name, x.endElement(current))
+			}
+		}
+	}
+
+	if parsedValue == nil {
+		return nil, fmt.Errorf("value does not contain an XML element")
+	}
+	return parsedValue, nil
+}
+
+// startElement will create an element, including its attributes unless they are dropped.
+// Namespace declarations are not included as attributes.
+func (x *XMLParser) startElement(start xml.StartElement) *element {
+	e := &element{name: start.Name.Local}
+	if x.dropAttributes {
+		return e
+	}
+
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			continue
+		}
+		if e.values == nil {
+			e.values = map[string]interface{}{}
+		}
+		e.values[x.attributePrefix+attr.Name.Local] = attr.Value
+	}
+	return e
+}
+
+// endElement will return the parsed value of an element. An element with only text
+// is parsed as a string, and other elements are parsed as maps.
+func (x *XMLParser) endElement(e *element) interface{} {
+	text := e.text.String()
+	if !x.preserveWhitespace {
+		text = strings.TrimSpace(text)
+	}
+
+	if e.values == nil {
+		return text
+	}
+
+	// Whitespace between child elements is not text, even if whitespace is preserved
+	if strings.TrimSpace(text) != "" {
+		e.values[x.textKey] = text
+	}
+	return e.values
+}
+
+// addChild will add the value of a child element to its parent.
+// Repeated elements and elements in force_array are collected in arrays.
+func (x *XMLParser) addChild(parent *element, name string, value interface{}) {
+	if parent.values == nil {
+		parent.values = map[string]interface{}{}
+	}
+
+	existing, ok := parent.values[name]
+	switch {
+	case !ok && x.forceArray[name]:
+		parent.values[name] = []interface{}{value}
+	case !ok:
+		parent.values[name] = value
+	default:
+		if values, isArray := existing.([]interface{}); isArray {
+			parent.values[name] = append(values, value)
+		} else {
+			parent.values[name] = []interface{}{existing, value}
+		}
+	}
+}
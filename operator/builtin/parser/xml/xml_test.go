@@ -0,0 +1,326 @@
+package xml
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParser(t *testing.T, modify func(*XMLParserConfig)) *XMLParser {
+	cfg := NewXMLParserConfig("test")
+	modify(cfg)
+	op, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	return op.(*XMLParser)
+}
+
+func TestXMLImplementations(t *testing.T) {
+	require.Implements(t, (*operator.Operator)(nil), new(XMLParser))
+}
+
+func TestXMLParserConfigBuildFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*XMLParserConfig)
+		expected string
+	}{
+		{
+			"InvalidAttributes",
+			func(cfg *XMLParserConfig) { cfg.Attributes = "nested" },
+			"invalid attributes 'nested'",
+		},
+		{
+			"MissingTextKey",
+			func(cfg *XMLParserConfig) { cfg.TextKey = "" },
+			"missing required field 'text_key'",
+		},
+		{
+			"InvalidMaxDepth",
+			func(cfg *XMLParserConfig) { cfg.MaxDepth = 0 },
+			"max_depth must be greater than 0",
+		},
+		{
+			"InvalidOnError",
+			func(cfg *XMLParserConfig) { cfg.OnError = "invalid_on_error" },
+			"invalid `on_error` field",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewXMLParserConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestXMLParser(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*XMLParserConfig)
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			"Text",
+			func(cfg *XMLParserConfig) {},
+			`<message>started</message>`,
+			map[string]interface{}{"message": "started"},
+		},
+		{
+			"Bytes",
+			func(cfg *XMLParserConfig) {},
+			[]byte(`<message>started</message>`),
+			map[string]interface{}{"message": "started"},
+		},
+		{
+			"Empty",
+			func(cfg *XMLParserConfig) {},
+			`<message/>`,
+			map[string]interface{}{"message": ""},
+		},
+		{
+			"Nested",
+			func(cfg *XMLParserConfig) {},
+			`<?xml version="1.0" encoding="UTF-8"?>
+			<!-- request log -->
+			<request>
+			  <method>GET</method>
+			  <client><ip>10.0.0.1</ip></client>
+			</request>`,
+			map[string]interface{}{
+				"request": map[string]interface{}{
+					"method": "GET",
+					"client": map[string]interface{}{"ip": "10.0.0.1"},
+				},
+			},
+		},
+		{
+			"Attributes",
+			func(cfg *XMLParserConfig) {},
+			`<event id="42" level="warn"><message lang="en">disk full</message></event>`,
+			map[string]interface{}{
+				"event": map[string]interface{}{
+					"@id":    "42",
+					"@level": "warn",
+					"message": map[string]interface{}{
+						"@lang": "en",
+						"#text": "disk full",
+					},
+				},
+			},
+		},
+		{
+			"AttributePrefix",
+			func(cfg *XMLParserConfig) {
+				cfg.AttributePrefix = ""
+				cfg.TextKey = "value"
+			},
+			`<message lang="en">disk full</message>`,
+			map[string]interface{}{
+				"message": map[string]interface{}{"lang": "en", "value": "disk full"},
+			},
+		},
+		{
+			"DropAttributes",
+			func(cfg *XMLParserConfig) { cfg.Attributes = "drop" },
+			`<event id="42"><message lang="en">disk full</message></event>`,
+			map[string]interface{}{
+				"event": map[string]interface{}{"message": "disk full"},
+			},
+		},
+		{
+			"RepeatedElements",
+			func(cfg *XMLParserConfig) {},
+			`<order><item>a</item><id>1</id><item>b</item><item>c</item></order>`,
+			map[string]interface{}{
+				"order": map[string]interface{}{
+					"id":   "1",
+					"item": []interface{}{"a", "b", "c"},
+				},
+			},
+		},
+		{
+			"ForceArray",
+			func(cfg *XMLParserConfig) { cfg.ForceArray = []string{"item"} },
+			`<order><item>a</item><id>1</id></order>`,
+			map[string]interface{}{
+				"order": map[string]interface{}{
+					"id":   "1",
+					"item": []interface{}{"a"},
+				},
+			},
+		},
+		{
+			"MixedContent",
+			func(cfg *XMLParserConfig) {},
+			`<message>disk <b>full</b> on /var</message>`,
+			map[string]interface{}{
+				"message": map[string]interface{}{
+					"b":     "full",
+					"#text": "disk  on /var",
+				},
+			},
+		},
+		{
+			"PreserveWhitespace",
+			func(cfg *XMLParserConfig) { cfg.PreserveWhitespace = true },
+			"<event>\n  <message>  disk full </message>\n</event>",
+			map[string]interface{}{
+				"event": map[string]interface{}{"message": "  disk full "},
+			},
+		},
+		{
+			"EntitiesAndCDATA",
+			func(cfg *XMLParserConfig) {},
+			`<query><sql><![CDATA[SELECT * FROM t WHERE a < 1]]></sql><text>&lt;b&gt; &amp; &#34;c&#34;</text></query>`,
+			map[string]interface{}{
+				"query": map[string]interface{}{
+					"sql":  "SELECT * FROM t WHERE a < 1",
+					"text": `<b> & "c"`,
+				},
+			},
+		},
+		{
+			"Namespaces",
+			func(cfg *XMLParserConfig) {},
+			`<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope" xmlns="urn:app">` +
+				`<soap:Body><Fault code="500">server error</Fault></soap:Body></soap:Envelope>`,
+			map[string]interface{}{
+				"Envelope": map[string]interface{}{
+					"Body": map[string]interface{}{
+						"Fault": map[string]interface{}{
+							"@code": "500",
+							"#text": "server error",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, tc.modify)
+
+			var output *entry.Entry
+			mockOutput := &testutil.Operator{}
+			mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				output = args[1].(*entry.Entry)
+			}).Return(nil)
+			parser.OutputOperators = []operator.Operator{mockOutput}
+
+			e := entry.New()
+			e.Record = tc.input
+			err := parser.Process(context.Background(), e)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, output.Record)
+		})
+	}
+}
+
+func TestXMLParserFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*XMLParserConfig)
+		input    interface{}
+		expected string
+	}{
+		{
+			"EntityExpansion",
+			func(cfg *XMLParserConfig) {},
+			`<?xml version="1.0"?>
+			<!DOCTYPE lolz [
+			  <!ENTITY lol "lol">
+			  <!ENTITY lol2 "&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;">
+			  <!ENTITY lol3 "&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;">
+			]>
+			<lolz>&lol3;</lolz>`,
+			"XML directives such as document type definitions are not supported",
+		},
+		{
+			"ExternalEntity",
+			func(cfg *XMLParserConfig) {},
+			`<!DOCTYPE foo [<!ENTITY xxe SYSTEM "file:///etc/passwd">]><foo>&xxe;</foo>`,
+			"XML directives such as document type definitions are not supported",
+		},
+		{
+			"UndefinedEntity",
+			func(cfg *XMLParserConfig) {},
+			`<foo>&lol;</foo>`,
+			"invalid character entity &lol;",
+		},
+		{
+			"TooDeep",
+			func(cfg *XMLParserConfig) { cfg.MaxDepth = 3 },
+			`<a><b><c><d>deep</d></c></b></a>`,
+			"XML document is nested deeper than 3 elements",
+		},
+		{
+			"Unclosed",
+			func(cfg *XMLParserConfig) {},
+			`<a><b>text</b>`,
+			"unexpected EOF",
+		},
+		{
+			"Mismatched",
+			func(cfg *XMLParserConfig) {},
+			`<a>text</b>`,
+			"element <a> closed by </b>",
+		},
+		{
+			"MultipleRoots",
+			func(cfg *XMLParserConfig) {},
+			`<a>1</a><b>2</b>`,
+			"XML document has more than one root element",
+		},
+		{
+			"TextOutsideRoot",
+			func(cfg *XMLParserConfig) {},
+			`<a>1</a> trailing`,
+			"XML document has text outside of the root element",
+		},
+		{
+			"NoElement",
+			func(cfg *XMLParserConfig) {},
+			`  `,
+			"value does not contain an XML element",
+		},
+		{
+			"InvalidType",
+			func(cfg *XMLParserConfig) {},
+			[]int{},
+			"type '[]int' cannot be parsed as XML",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := newTestParser(t, tc.modify)
+			_, err := parser.parse(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestXMLParserDefaultMaxDepth(t *testing.T) {
+	parser := newTestParser(t, func(cfg *XMLParserConfig) {})
+
+	input := strings.Repeat("<a>", defaultMaxDepth) + strings.Repeat("</a>", defaultMaxDepth)
+	_, err := parser.parse(input)
+	require.NoError(t, err)
+
+	input = strings.Repeat("<a>", defaultMaxDepth+1) + strings.Repeat("</a>", defaultMaxDepth+1)
+	_, err = parser.parse(input)
+	require.Error(t, err)
+}
@@ -39,7 +39,7 @@ func (c FilterOperatorConfig) Build(context operator.BuildContext) (operator.Ope
 		return nil, err
 	}
 
-	compiledExpression, err := expr.Compile(c.Expression, expr.AsBool(), expr.AllowUndefinedVariables())
+	compiledExpression, err := helper.ExprCompile(c.Expression, expr.AsBool())
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile expression '%s': %w", c.Expression, err)
 	}
@@ -66,6 +66,11 @@ type FilterOperator struct {
 
 // Process will drop incoming entries that match the filter expression
 func (f *FilterOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return f.ProcessIf(ctx, entry, f.process)
+}
+
+// process will drop an entry that matches the if expression and the filter expression.
+func (f *FilterOperator) process(ctx context.Context, entry *entry.Entry) error {
 	env := helper.GetExprEnv(entry)
 	defer helper.PutExprEnv(env)
 
@@ -83,7 +88,9 @@ func (f *FilterOperator) Process(ctx context.Context, entry *entry.Entry) error
 
 	if !filtered || rand.Float64() > f.dropRatio {
 		f.Write(ctx, entry)
+		return nil
 	}
 
+	entry.Release()
 	return nil
 }
@@ -86,6 +86,27 @@ func TestFilterOperator(t *testing.T) {
 			`env("TEST_FILTER_PLUGIN_ENV") == "bar"`,
 			false,
 		},
+		{
+			"MatchSeverity",
+			&entry.Entry{
+				Severity: entry.Debug,
+				Record: map[string]interface{}{
+					"message": "test_message",
+				},
+			},
+			`$severity < 30`,
+			true,
+		},
+		{
+			"MatchFunction",
+			&entry.Entry{
+				Record: map[string]interface{}{
+					"message": "GET /healthz 200",
+				},
+			},
+			`regex_match($.message, "^GET /healthz")`,
+			true,
+		},
 	}
 
 	for _, tc := range cases {
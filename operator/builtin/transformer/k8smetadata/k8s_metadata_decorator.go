@@ -135,6 +135,11 @@ func (k *K8sMetadataDecorator) Start() error {
 
 // Process will process an entry received by the k8s_metadata_decorator operator
 func (k *K8sMetadataDecorator) Process(ctx context.Context, entry *entry.Entry) error {
+	return k.ProcessIf(ctx, entry, k.process)
+}
+
+// process will decorate an entry that matches the if expression with metadata.
+func (k *K8sMetadataDecorator) process(ctx context.Context, entry *entry.Entry) error {
 	var podName string
 	err := entry.Read(k.podNameField, &podName)
 	if err != nil {
@@ -246,35 +251,27 @@ func (k *K8sMetadataDecorator) refreshPodMetadata(ctx context.Context, namespace
 }
 
 func (k *K8sMetadataDecorator) decorateEntryWithNamespaceMetadata(nsMeta MetadataCacheEntry, entry *entry.Entry) {
-	if entry.Labels == nil {
-		entry.Labels = make(map[string]string)
-	}
-
 	for k, v := range nsMeta.Annotations {
-		entry.Labels["k8s-ns-annotation/"+k] = v
+		entry.AddLabel("k8s-ns-annotation/"+k, v)
 	}
 
 	for k, v := range nsMeta.Labels {
-		entry.Labels["k8s-ns/"+k] = v
+		entry.AddLabel("k8s-ns/"+k, v)
 	}
 
-	entry.Resource["k8s.namespace.uid"] = nsMeta.UID
-	entry.Resource["k8s.cluster.name"] = nsMeta.ClusterName
+	entry.AddResourceKey("k8s.namespace.uid", nsMeta.UID)
+	entry.AddResourceKey("k8s.cluster.name", nsMeta.ClusterName)
 }
 
 func (k *K8sMetadataDecorator) decorateEntryWithPodMetadata(podMeta MetadataCacheEntry, entry *entry.Entry) {
-	if entry.Labels == nil {
-		entry.Labels = make(map[string]string)
-	}
-
 	for k, v := range podMeta.Annotations {
-		entry.Labels["k8s-pod-annotation/"+k] = v
+		entry.AddLabel("k8s-pod-annotation/"+k, v)
 	}
 
 	for k, v := range podMeta.Labels {
-		entry.Labels["k8s-pod/"+k] = v
+		entry.AddLabel("k8s-pod/"+k, v)
 	}
 
-	entry.Resource["k8s.pod.uid"] = podMeta.UID
-	entry.Resource["k8s.cluster.name"] = podMeta.ClusterName
+	entry.AddResourceKey("k8s.pod.uid", podMeta.UID)
+	entry.AddResourceKey("k8s.cluster.name", podMeta.ClusterName)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
 	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/operator/helper"
 )
@@ -26,6 +27,14 @@ type NoopOperatorConfig struct {
 
 // Build will build a noop operator.
 func (c NoopOperatorConfig) Build(context operator.BuildContext) (operator.Operator, error) {
+	if c.IfExpr != "" {
+		return nil, errors.NewError(
+			"noop operator config has an `if` field.",
+			"remove the `if` field, as the noop operator passes every entry through untouched.",
+			"if", c.IfExpr,
+		)
+	}
+
 	transformerOperator, err := c.TransformerConfig.Build(context)
 	if err != nil {
 		return nil, err
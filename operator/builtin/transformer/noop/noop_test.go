@@ -64,6 +64,15 @@ func TestBuildIvalid(t *testing.T) {
 	require.Contains(t, err.Error(), "build context is missing a logger")
 }
 
+func TestBuildIf(t *testing.T) {
+	cfg := NewNoopOperatorConfig("test_id")
+	cfg.IfExpr = `$record.key == "value"`
+
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "noop operator config has an `if` field")
+}
+
 func TestProcess(t *testing.T) {
 	noop, err := NewTestOperator(t)
 	require.NoError(t, err)
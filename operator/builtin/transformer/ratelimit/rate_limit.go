@@ -72,6 +72,11 @@ type RateLimitOperator struct {
 
 // Process will wait until a rate is met before sending an entry to the output.
 func (p *RateLimitOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return p.ProcessIf(ctx, entry, p.process)
+}
+
+// process will wait until a rate is met before sending an entry that matches the if expression.
+func (p *RateLimitOperator) process(ctx context.Context, entry *entry.Entry) error {
 	select {
 	case <-p.isReady:
 		p.Write(ctx, entry)
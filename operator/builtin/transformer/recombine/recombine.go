@@ -0,0 +1,325 @@
+package recombine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("recombine", func() operator.Builder { return NewRecombineOperatorConfig("") })
+}
+
+const (
+	overwriteWithOldest = "oldest"
+	overwriteWithNewest = "newest"
+)
+
+// NewRecombineOperatorConfig creates a new recombine config with default values
+func NewRecombineOperatorConfig(operatorID string) *RecombineOperatorConfig {
+	return &RecombineOperatorConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "recombine"),
+		CombineField:      entry.NewRecordField(),
+		CombineWith:       "\n",
+		SourceIdentifier:  entry.NewLabelField("file_path"),
+		MaxBatchSize:      1000,
+		ForceFlushPeriod:  helper.Duration{Duration: 5 * time.Second},
+		OverwriteWith:     overwriteWithOldest,
+	}
+}
+
+// RecombineOperatorConfig is the configuration of a recombine operator
+type RecombineOperatorConfig struct {
+	helper.TransformerConfig `yaml:",inline"`
+
+	IsFirstEntry     string          `json:"is_first_entry,omitempty" yaml:"is_first_entry,omitempty"`
+	IsLastEntry      string          `json:"is_last_entry,omitempty"  yaml:"is_last_entry,omitempty"`
+	CombineField     entry.Field     `json:"combine_field"            yaml:"combine_field"`
+	CombineWith      string          `json:"combine_with"             yaml:"combine_with"`
+	SourceIdentifier entry.Field     `json:"source_identifier"        yaml:"source_identifier"`
+	MaxBatchSize     int             `json:"max_batch_size"           yaml:"max_batch_size"`
+	ForceFlushPeriod helper.Duration `json:"force_flush_period"       yaml:"force_flush_period"`
+	OverwriteWith    string          `json:"overwrite_with"           yaml:"overwrite_with"`
+}
+
+// Build will build a recombine operator from the supplied configuration
+func (c RecombineOperatorConfig) Build(context operator.BuildContext) (operator.Operator, error) {
+	transformer, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, errors.Wrap(err, "build transformer")
+	}
+
+	if (c.IsFirstEntry == "") == (c.IsLastEntry == "") {
+		return nil, errors.NewError(
+			"exactly one of `is_first_entry` or `is_last_entry` must be set",
+			"use `is_first_entry` to match the first entry of each group, or `is_last_entry` to match the last entry",
+		)
+	}
+
+	matchFirst := c.IsFirstEntry != ""
+	matchExpression := c.IsFirstEntry
+	if !matchFirst {
+		matchExpression = c.IsLastEntry
+	}
+
+	matchProgram, err := helper.ExprCompile(matchExpression, expr.AsBool())
+	if err != nil {
+		return nil, errors.NewError(
+			"operator config has an invalid match expression",
+			"ensure that `is_first_entry` or `is_last_entry` is an expression that returns a boolean",
+			"expression", matchExpression,
+			"error", err.Error(),
+		)
+	}
+
+	switch c.OverwriteWith {
+	case overwriteWithOldest, overwriteWithNewest:
+	default:
+		return nil, fmt.Errorf("invalid value '%s' for parameter 'overwrite_with'", c.OverwriteWith)
+	}
+
+	if c.MaxBatchSize <= 0 {
+		return nil, fmt.Errorf("max_batch_size must be greater than zero")
+	}
+
+	if c.ForceFlushPeriod.Raw() <= 0 {
+		return nil, fmt.Errorf("force_flush_period must be greater than zero")
+	}
+
+	recombine := &RecombineOperator{
+		TransformerOperator: transformer,
+		matchFirst:          matchFirst,
+		matchProgram:        matchProgram,
+		combineField:        c.CombineField,
+		combineWith:         c.CombineWith,
+		sourceIdentifier:    c.SourceIdentifier,
+		maxBatchSize:        c.MaxBatchSize,
+		forceFlushPeriod:    c.ForceFlushPeriod.Raw(),
+		overwriteWithOldest: c.OverwriteWith == overwriteWithOldest,
+		sources:             map[string]*sourceBatch{},
+	}
+
+	return recombine, nil
+}
+
+// RecombineOperator is an operator that combines consecutive entries into one
+type RecombineOperator struct {
+	helper.TransformerOperator
+
+	matchFirst          bool
+	matchProgram        *vm.Program
+	combineField        entry.Field
+	combineWith         string
+	sourceIdentifier    entry.Field
+	maxBatchSize        int
+	forceFlushPeriod    time.Duration
+	overwriteWithOldest bool
+
+	// sources holds the entries of each source that are waiting to be combined
+	sources map[string]*sourceBatch
+	mux     sync.Mutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// batch is a group of consecutive entries from a source
+type batch struct {
+	entries []*entry.Entry
+	updated time.Time
+}
+
+// sourceBatch is the batch of a source. Its lock is held while the batch is
+// updated and while completed batches are sent, so that the combined entries
+// of a source are sent in order by both process and flush.
+type sourceBatch struct {
+	sync.Mutex
+	batch *batch
+
+	// removed is true once the source is no longer in the sources of the operator
+	removed bool
+}
+
+// lockSource will return the locked batch of a source, adding it if the source has no batch
+func (r *RecombineOperator) lockSource(source string) *sourceBatch {
+	for {
+		r.mux.Lock()
+		s, ok := r.sources[source]
+		if !ok {
+			s = &sourceBatch{}
+			r.sources[source] = s
+		}
+		r.mux.Unlock()
+
+		s.Lock()
+		if !s.removed {
+			return s
+		}
+
+		// The source was removed while waiting for its lock, so it is added again
+		s.Unlock()
+	}
+}
+
+// unlockSource will unlock the batch of a source, removing the source if it has no entries waiting
+func (r *RecombineOperator) unlockSource(source string, s *sourceBatch) {
+	if s.batch == nil {
+		r.mux.Lock()
+		delete(r.sources, source)
+		r.mux.Unlock()
+		s.removed = true
+	}
+	s.Unlock()
+}
+
+// Start will start flushing batches that are not completed within the force flush period
+func (r *RecombineOperator) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.forceFlushPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.flush(ctx, time.Now().Add(-r.forceFlushPeriod))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop will stop the recombine operator and flush any incomplete batches
+func (r *RecombineOperator) Stop() error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	r.flush(context.Background(), time.Now())
+	return nil
+}
+
+// flush will combine and send the batches that were last updated before a time
+func (r *RecombineOperator) flush(ctx context.Context, before time.Time) {
+	r.mux.Lock()
+	sources := make(map[string]*sourceBatch, len(r.sources))
+	for source, s := range r.sources {
+		sources[source] = s
+	}
+	r.mux.Unlock()
+
+	for source, s := range sources {
+		s.Lock()
+		if s.removed {
+			s.Unlock()
+			continue
+		}
+		if s.batch != nil && s.batch.updated.Before(before) {
+			r.combine(ctx, s.batch)
+			s.batch = nil
+		}
+		r.unlockSource(source, s)
+	}
+}
+
+// Process will add an entry to the batch of its source, and send
+// the combined entries of the batch when it is complete
+func (r *RecombineOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return r.ProcessIf(ctx, entry, r.process)
+}
+
+// process will add an entry that matches the if expression to the batch of its source.
+func (r *RecombineOperator) process(ctx context.Context, entry *entry.Entry) error {
+	matches, err := r.matches(entry)
+	if err != nil {
+		return r.HandleEntryError(ctx, entry, err)
+	}
+
+	source := ""
+	if value, ok := entry.Get(r.sourceIdentifier); ok {
+		source = fmt.Sprintf("%v", value)
+	}
+
+	s := r.lockSource(source)
+	defer r.unlockSource(source, s)
+
+	// The first entry of a group completes the batch of the previous group
+	if matches && r.matchFirst && s.batch != nil {
+		r.combine(ctx, s.batch)
+		s.batch = nil
+	}
+	if s.batch == nil {
+		s.batch = &batch{}
+	}
+
+	s.batch.entries = append(s.batch.entries, entry)
+	s.batch.updated = time.Now()
+
+	// The last entry of a group, or a full batch, completes the batch
+	if (matches && !r.matchFirst) || len(s.batch.entries) >= r.maxBatchSize {
+		r.combine(ctx, s.batch)
+		s.batch = nil
+	}
+	return nil
+}
+
+// matches will return true if an entry matches the first or last entry expression
+func (r *RecombineOperator) matches(entry *entry.Entry) (bool, error) {
+	env := helper.GetExprEnv(entry)
+	defer helper.PutExprEnv(env)
+
+	matches, err := vm.Run(r.matchProgram, env)
+	if err != nil {
+		return false, errors.Wrap(err, "evaluate match expression")
+	}
+
+	// The expression is compiled with AsBool, so the result is a boolean
+	return matches.(bool), nil
+}
+
+// combine will combine the entries of a batch into one entry and send it
+func (r *RecombineOperator) combine(ctx context.Context, b *batch) {
+	base := b.entries[0]
+	if !r.overwriteWithOldest {
+		base = b.entries[len(b.entries)-1]
+	}
+
+	values := make([]string, 0, len(b.entries))
+	for _, e := range b.entries {
+		value, ok := e.Get(r.combineField)
+		if !ok {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			values = append(values, v)
+		case []byte:
+			values = append(values, string(v))
+		default:
+			values = append(values, fmt.Sprintf("%v", v))
+		}
+	}
+
+	if err := base.Set(r.combineField, strings.Join(values, r.combineWith)); err != nil {
+		_ = r.HandleEntryError(ctx, base, errors.Wrap(err, "set combine_field"))
+		return
+	}
+
+	r.Write(ctx, base)
+}
@@ -0,0 +1,382 @@
+package recombine
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOperator(t *testing.T, modify func(*RecombineOperatorConfig)) (*RecombineOperator, *[]*entry.Entry) {
+	cfg := NewRecombineOperatorConfig("test")
+	modify(cfg)
+	op, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	recombine := op.(*RecombineOperator)
+
+	outputs := []*entry.Entry{}
+	mockOutput := &testutil.Operator{}
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		outputs = append(outputs, args[1].(*entry.Entry))
+	}).Return(nil)
+	recombine.OutputOperators = []operator.Operator{mockOutput}
+
+	return recombine, &outputs
+}
+
+func newTestEntry(record interface{}, source string) *entry.Entry {
+	e := entry.New()
+	e.Record = record
+	if source != "" {
+		e.AddLabel("file_path", source)
+	}
+	return e
+}
+
+func TestRecombineImplementations(t *testing.T) {
+	require.Implements(t, (*operator.Operator)(nil), new(RecombineOperator))
+}
+
+func TestRecombine(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*RecombineOperatorConfig)
+		input    []*entry.Entry
+		expected []interface{}
+	}{
+		{
+			"IsFirstEntry",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsFirstEntry = `$record matches "^\\d{4}-"`
+			},
+			[]*entry.Entry{
+				newTestEntry("2020-09-24 ERROR request failed", "a.log"),
+				newTestEntry("java.lang.NullPointerException", "a.log"),
+				newTestEntry("    at com.example.App.main(App.java:10)", "a.log"),
+				newTestEntry("2020-09-24 INFO request served", "a.log"),
+				newTestEntry("2020-09-24 INFO request served", "a.log"),
+			},
+			[]interface{}{
+				"2020-09-24 ERROR request failed\njava.lang.NullPointerException\n    at com.example.App.main(App.java:10)",
+				"2020-09-24 INFO request served",
+			},
+		},
+		{
+			"IsLastEntry",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsLastEntry = `$record endsWith ";"`
+			},
+			[]*entry.Entry{
+				newTestEntry("select *", "a.log"),
+				newTestEntry("from users", "a.log"),
+				newTestEntry("where id = 1;", "a.log"),
+				newTestEntry("commit;", "a.log"),
+				newTestEntry("select 1", "a.log"),
+			},
+			[]interface{}{
+				"select *\nfrom users\nwhere id = 1;",
+				"commit;",
+			},
+		},
+		{
+			"CombineWith",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsLastEntry = `$record endsWith "."`
+				cfg.CombineWith = " "
+			},
+			[]*entry.Entry{
+				newTestEntry("a sentence", "a.log"),
+				newTestEntry("split over", "a.log"),
+				newTestEntry("lines.", "a.log"),
+			},
+			[]interface{}{"a sentence split over lines."},
+		},
+		{
+			"CombineField",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsFirstEntry = `$record.log startsWith "Traceback"`
+				cfg.CombineField = entry.NewRecordField("log")
+				cfg.CombineWith = ""
+			},
+			[]*entry.Entry{
+				newTestEntry(map[string]interface{}{"log": "Traceback (most recent call last):\n", "stream": "stderr"}, "a.log"),
+				newTestEntry(map[string]interface{}{"log": "  File \"app.py\", line 1\n", "stream": "stderr"}, "a.log"),
+				newTestEntry(map[string]interface{}{"log": "Traceback (most recent call last):\n", "stream": "stderr"}, "a.log"),
+			},
+			[]interface{}{
+				map[string]interface{}{"log": "Traceback (most recent call last):\n  File \"app.py\", line 1\n", "stream": "stderr"},
+			},
+		},
+		{
+			"SeparateSources",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsFirstEntry = `$record startsWith "start"`
+			},
+			[]*entry.Entry{
+				newTestEntry("start a", "a.log"),
+				newTestEntry("start b", "b.log"),
+				newTestEntry("more a", "a.log"),
+				newTestEntry("more b", "b.log"),
+				newTestEntry("start a", "a.log"),
+				newTestEntry("start b", "b.log"),
+			},
+			[]interface{}{
+				"start a\nmore a",
+				"start b\nmore b",
+			},
+		},
+		{
+			"MaxBatchSize",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsLastEntry = `$record == "end"`
+				cfg.MaxBatchSize = 2
+			},
+			[]*entry.Entry{
+				newTestEntry("one", "a.log"),
+				newTestEntry("two", "a.log"),
+				newTestEntry("three", "a.log"),
+				newTestEntry("end", "a.log"),
+			},
+			[]interface{}{
+				"one\ntwo",
+				"three\nend",
+			},
+		},
+		{
+			"BytesAndMissingFields",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsLastEntry = `$record.message == "end"`
+				cfg.CombineField = entry.NewRecordField("message")
+			},
+			[]*entry.Entry{
+				newTestEntry(map[string]interface{}{"message": []byte("start")}, "a.log"),
+				newTestEntry(map[string]interface{}{"other": "value"}, "a.log"),
+				newTestEntry(map[string]interface{}{"message": "end"}, "a.log"),
+			},
+			[]interface{}{
+				map[string]interface{}{"message": "start\nend"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recombine, outputs := newTestOperator(t, tc.modify)
+
+			for _, e := range tc.input {
+				err := recombine.Process(context.Background(), e)
+				require.NoError(t, err)
+			}
+
+			records := make([]interface{}, 0, len(*outputs))
+			for _, output := range *outputs {
+				records = append(records, output.Record)
+			}
+			require.Equal(t, tc.expected, records)
+		})
+	}
+}
+
+func TestRecombineOverwriteWith(t *testing.T) {
+	cases := []struct {
+		name          string
+		overwriteWith string
+		expected      string
+	}{
+		{"Oldest", "oldest", "first"},
+		{"Newest", "newest", "last"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recombine, outputs := newTestOperator(t, func(cfg *RecombineOperatorConfig) {
+				cfg.IsLastEntry = `$record.message == "end"`
+				cfg.CombineField = entry.NewRecordField("message")
+				cfg.OverwriteWith = tc.overwriteWith
+			})
+
+			first := newTestEntry(map[string]interface{}{"message": "start"}, "a.log")
+			first.AddLabel("position", "first")
+			last := newTestEntry(map[string]interface{}{"message": "end"}, "a.log")
+			last.AddLabel("position", "last")
+
+			require.NoError(t, recombine.Process(context.Background(), first))
+			require.NoError(t, recombine.Process(context.Background(), last))
+
+			require.Len(t, *outputs, 1)
+			require.Equal(t, tc.expected, (*outputs)[0].Labels["position"])
+			require.Equal(t, map[string]interface{}{"message": "start\nend"}, (*outputs)[0].Record)
+		})
+	}
+}
+
+func TestRecombineIfSkip(t *testing.T) {
+	recombine, outputs := newTestOperator(t, func(cfg *RecombineOperatorConfig) {
+		cfg.IsFirstEntry = `$record startsWith "start"`
+		cfg.IfExpr = `$labels.file_path != ""`
+	})
+
+	require.NoError(t, recombine.Process(context.Background(), newTestEntry("start", "a.log")))
+	require.NoError(t, recombine.Process(context.Background(), newTestEntry("no source", "")))
+
+	require.Len(t, *outputs, 1)
+	require.Equal(t, "no source", (*outputs)[0].Record)
+}
+
+func TestRecombineForceFlush(t *testing.T) {
+	recombine, _ := newTestOperator(t, func(cfg *RecombineOperatorConfig) {
+		cfg.IsFirstEntry = `$record startsWith "start"`
+		cfg.ForceFlushPeriod = helper.Duration{Duration: 10 * time.Millisecond}
+	})
+
+	// Flushed batches are sent from another goroutine
+	outputs := make(chan *entry.Entry, 1)
+	mockOutput := &testutil.Operator{}
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		outputs <- args[1].(*entry.Entry)
+	}).Return(nil)
+	recombine.OutputOperators = []operator.Operator{mockOutput}
+
+	require.NoError(t, recombine.Start())
+	defer recombine.Stop()
+
+	require.NoError(t, recombine.Process(context.Background(), newTestEntry("start", "a.log")))
+	require.NoError(t, recombine.Process(context.Background(), newTestEntry("more", "a.log")))
+
+	select {
+	case output := <-outputs:
+		require.Equal(t, "start\nmore", output.Record)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for the batch to be flushed")
+	}
+}
+
+func TestRecombineForceFlushOrder(t *testing.T) {
+	recombine, _ := newTestOperator(t, func(cfg *RecombineOperatorConfig) {
+		cfg.IsFirstEntry = `true`
+		cfg.ForceFlushPeriod = helper.Duration{Duration: time.Nanosecond}
+	})
+
+	// Flushed batches are sent from another goroutine, and must not be
+	// sent after a batch of the same source that was completed later
+	var mux sync.Mutex
+	outputs := []interface{}{}
+	mockOutput := &testutil.Operator{}
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mux.Lock()
+		outputs = append(outputs, args[1].(*entry.Entry).Record)
+		mux.Unlock()
+	}).Return(nil)
+	recombine.OutputOperators = []operator.Operator{mockOutput}
+
+	require.NoError(t, recombine.Start())
+
+	expected := make([]interface{}, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		record := strconv.Itoa(i)
+		expected = append(expected, record)
+		require.NoError(t, recombine.Process(context.Background(), newTestEntry(record, "a.log")))
+	}
+
+	require.NoError(t, recombine.Stop())
+	require.Equal(t, expected, outputs)
+}
+
+func TestRecombineStopFlush(t *testing.T) {
+	recombine, outputs := newTestOperator(t, func(cfg *RecombineOperatorConfig) {
+		cfg.IsFirstEntry = `$record startsWith "start"`
+	})
+
+	require.NoError(t, recombine.Start())
+	require.NoError(t, recombine.Process(context.Background(), newTestEntry("start", "a.log")))
+	require.Len(t, *outputs, 0)
+
+	require.NoError(t, recombine.Stop())
+	require.Len(t, *outputs, 1)
+	require.Equal(t, "start", (*outputs)[0].Record)
+}
+
+func TestRecombineMatchError(t *testing.T) {
+	recombine, outputs := newTestOperator(t, func(cfg *RecombineOperatorConfig) {
+		cfg.IsFirstEntry = `$record.message startsWith "start"`
+	})
+
+	// The entry that fails to match is sent on according to on_error
+	err := recombine.Process(context.Background(), newTestEntry("not a map", "a.log"))
+	require.NoError(t, err)
+	require.Len(t, *outputs, 1)
+}
+
+func TestRecombineConfigBuildFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(*RecombineOperatorConfig)
+		expected string
+	}{
+		{
+			"MissingExpression",
+			func(*RecombineOperatorConfig) {},
+			"exactly one of `is_first_entry` or `is_last_entry` must be set",
+		},
+		{
+			"BothExpressions",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsFirstEntry = "true"
+				cfg.IsLastEntry = "true"
+			},
+			"exactly one of `is_first_entry` or `is_last_entry` must be set",
+		},
+		{
+			"InvalidExpression",
+			func(cfg *RecombineOperatorConfig) { cfg.IsFirstEntry = "$record ==" },
+			"invalid match expression",
+		},
+		{
+			"NonBooleanExpression",
+			func(cfg *RecombineOperatorConfig) { cfg.IsLastEntry = `"string"` },
+			"invalid match expression",
+		},
+		{
+			"InvalidOverwriteWith",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsFirstEntry = "true"
+				cfg.OverwriteWith = "middle"
+			},
+			"invalid value 'middle' for parameter 'overwrite_with'",
+		},
+		{
+			"InvalidMaxBatchSize",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsFirstEntry = "true"
+				cfg.MaxBatchSize = 0
+			},
+			"max_batch_size must be greater than zero",
+		},
+		{
+			"InvalidForceFlushPeriod",
+			func(cfg *RecombineOperatorConfig) {
+				cfg.IsFirstEntry = "true"
+				cfg.ForceFlushPeriod = helper.Duration{}
+			},
+			"force_flush_period must be greater than zero",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRecombineOperatorConfig("test")
+			tc.modify(cfg)
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
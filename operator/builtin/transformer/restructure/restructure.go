@@ -4,8 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/antonmedv/expr"
 	"github.com/antonmedv/expr/vm"
 	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/errors"
@@ -175,6 +179,10 @@ func (o *Op) getOpApplier(opType string, rawMessage rawMessage) (OpApplier, erro
 		var flatten OpFlatten
 		err := rawMessage.Unmarshal(&flatten)
 		return &flatten, err
+	case "convert":
+		var convert OpConvert
+		err := rawMessage.Unmarshal(&convert)
+		return &convert, err
 	default:
 		return nil, fmt.Errorf("unknown op type '%s'", opType)
 	}
@@ -215,6 +223,10 @@ func (op *OpAdd) Apply(e *entry.Entry) error {
 			return err
 		}
 	case op.program != nil:
+		// Reading the record gives the entry its own copy of a shared record, so that
+		// maps and arrays the expression returns from it are not shared with other entries
+		_, _ = e.Get(entry.NewRecordField())
+
 		env := helper.GetExprEnv(e)
 		defer helper.PutExprEnv(env)
 
@@ -281,7 +293,7 @@ func (op *OpAdd) unmarshalFromOpAddRaw(addRaw opAddRaw) error {
 		op.Field = *addRaw.Field
 		op.Value = addRaw.Value
 	case addRaw.ValueExpr != nil:
-		compiled, err := expr.Compile(*addRaw.ValueExpr, expr.AllowUndefinedVariables())
+		compiled, err := helper.ExprCompile(*addRaw.ValueExpr)
 		if err != nil {
 			return fmt.Errorf("decode OpAdd: failed to compile expression '%s': %w", *addRaw.ValueExpr, err)
 		}
@@ -346,6 +358,10 @@ type OpRetain struct {
 func (op *OpRetain) Apply(e *entry.Entry) error {
 	newEntry := entry.New()
 	newEntry.Timestamp = e.Timestamp
+	if _, ok := e.Record.(*entry.OrderedMap); ok {
+		// Keep the retained fields ordered, in the order they are listed
+		newEntry.Record = entry.NewOrderedMap()
+	}
 	for _, field := range op.Fields {
 		val, ok := e.Get(field)
 		if !ok {
@@ -428,8 +444,23 @@ func (op *OpFlatten) Apply(e *entry.Entry) error {
 		return fmt.Errorf("apply flatten: field %s does not exist on record", op.Field)
 	}
 
-	valMap, ok := val.(map[string]interface{})
-	if !ok {
+	switch valMap := val.(type) {
+	case map[string]interface{}:
+		for k, v := range valMap {
+			err := e.Set(parent.Child(k), v)
+			if err != nil {
+				return err
+			}
+		}
+	case *entry.OrderedMap:
+		for _, k := range valMap.Keys() {
+			v, _ := valMap.Get(k)
+			err := e.Set(parent.Child(k), v)
+			if err != nil {
+				return err
+			}
+		}
+	default:
 		// The field we were asked to flatten was not a map, so put it back
 		err := e.Set(op.Field, val)
 		if err != nil {
@@ -437,13 +468,6 @@ func (op *OpFlatten) Apply(e *entry.Entry) error {
 		}
 		return fmt.Errorf("apply flatten: field %s is not a map", op.Field)
 	}
-
-	for k, v := range valMap {
-		err := e.Set(parent.Child(k), v)
-		if err != nil {
-			return err
-		}
-	}
 	return nil
 }
 
@@ -471,3 +495,246 @@ func (op OpFlatten) MarshalJSON() ([]byte, error) {
 func (op OpFlatten) MarshalYAML() (interface{}, error) {
 	return op.Field.String(), nil
 }
+
+/**********
+  Convert
+**********/
+
+// Types that fields can be converted to
+const (
+	convertInt       = "int"
+	convertFloat     = "float"
+	convertBool      = "bool"
+	convertDuration  = "duration"
+	convertByteSize  = "byte_size"
+	convertTimestamp = "timestamp"
+)
+
+// durationUnits are the units that durations can be converted to
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// OpConvert is an operation for converting the type of a field
+type OpConvert struct {
+	Field      entry.Field `json:"field"                 yaml:"field"`
+	ValueType  string      `json:"type"                  yaml:"type"`
+	Unit       string      `json:"unit,omitempty"        yaml:"unit,omitempty"`
+	Layout     string      `json:"layout,omitempty"      yaml:"layout,omitempty"`
+	LayoutType string      `json:"layout_type,omitempty" yaml:"layout_type,omitempty"`
+	timeParser *helper.TimeParser
+}
+
+// Apply will perform the convert operation on an entry.
+// Fields that do not exist are ignored, and empty strings are converted to null.
+func (op *OpConvert) Apply(e *entry.Entry) error {
+	value, ok := e.Get(op.Field)
+	if !ok {
+		return nil
+	}
+
+	if s, ok := value.(string); ok && strings.TrimSpace(s) == "" {
+		return e.Set(op.Field, nil)
+	}
+
+	converted, err := op.convert(e, value)
+	if err != nil {
+		return fmt.Errorf("apply convert: %s", err)
+	}
+
+	return e.Set(op.Field, converted)
+}
+
+// convert will convert the value of the field to the type of the operation
+func (op *OpConvert) convert(e *entry.Entry, value interface{}) (interface{}, error) {
+	switch op.ValueType {
+	case convertInt:
+		var i int
+		err := e.Read(op.Field, &i)
+		return i, err
+	case convertFloat:
+		var f float64
+		err := e.Read(op.Field, &f)
+		return f, err
+	case convertBool:
+		var b bool
+		err := e.Read(op.Field, &b)
+		return b, err
+	case convertDuration:
+		var d time.Duration
+		if err := e.Read(op.Field, &d); err != nil {
+			return nil, err
+		}
+		return float64(d) / float64(durationUnits[op.Unit]), nil
+	case convertByteSize:
+		size, err := toByteSize(value)
+		if err != nil {
+			return nil, fmt.Errorf("field '%s' of type '%T' can not be converted to a byte size: %s", op.Field, value, err)
+		}
+		return size, nil
+	case convertTimestamp:
+		if op.timeParser != nil {
+			t, err := op.timeParser.ParseValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("field '%s' of type '%T' can not be converted to a time: %s", op.Field, value, err)
+			}
+			return t, nil
+		}
+		var t time.Time
+		err := e.Read(op.Field, &t)
+		return t, err
+	default:
+		// Should never reach here if we went through the unmarshalling code
+		return nil, fmt.Errorf("unknown type '%s'", op.ValueType)
+	}
+}
+
+// Type will return the type of operation
+func (op *OpConvert) Type() string {
+	return "convert"
+}
+
+type opConvertRaw struct {
+	Field      *entry.Field `json:"field"       yaml:"field"`
+	Type       string       `json:"type"        yaml:"type"`
+	Unit       string       `json:"unit"        yaml:"unit"`
+	Layout     string       `json:"layout"      yaml:"layout"`
+	LayoutType string       `json:"layout_type" yaml:"layout_type"`
+}
+
+// UnmarshalJSON will unmarshal JSON into a convert operation
+func (op *OpConvert) UnmarshalJSON(raw []byte) error {
+	var convertRaw opConvertRaw
+	err := json.Unmarshal(raw, &convertRaw)
+	if err != nil {
+		return fmt.Errorf("decode OpConvert: %s", err)
+	}
+
+	return op.unmarshalFromOpConvertRaw(convertRaw)
+}
+
+// UnmarshalYAML will unmarshal YAML into a convert operation
+func (op *OpConvert) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var convertRaw opConvertRaw
+	err := unmarshal(&convertRaw)
+	if err != nil {
+		return fmt.Errorf("decode OpConvert: %s", err)
+	}
+
+	return op.unmarshalFromOpConvertRaw(convertRaw)
+}
+
+func (op *OpConvert) unmarshalFromOpConvertRaw(convertRaw opConvertRaw) error {
+	if convertRaw.Field == nil {
+		return fmt.Errorf("decode OpConvert: missing required field 'field'")
+	}
+
+	switch convertRaw.Type {
+	case convertInt, convertFloat, convertBool, convertByteSize:
+	case convertDuration:
+		if convertRaw.Unit == "" {
+			convertRaw.Unit = "s"
+		}
+		if _, ok := durationUnits[convertRaw.Unit]; !ok {
+			return fmt.Errorf("decode OpConvert: invalid duration unit '%s'", convertRaw.Unit)
+		}
+	case convertTimestamp:
+		if convertRaw.Layout != "" {
+			timeParser := helper.NewTimeParser()
+			timeParser.ParseFrom = convertRaw.Field
+			timeParser.Layout = convertRaw.Layout
+			if convertRaw.LayoutType != "" {
+				timeParser.LayoutType = convertRaw.LayoutType
+			}
+			if err := timeParser.Validate(operator.BuildContext{}); err != nil {
+				return fmt.Errorf("decode OpConvert: %s", err)
+			}
+			op.timeParser = &timeParser
+		}
+	case "":
+		return fmt.Errorf("decode OpConvert: missing required field 'type'")
+	default:
+		return fmt.Errorf("decode OpConvert: invalid type '%s'", convertRaw.Type)
+	}
+
+	if convertRaw.Unit != "" && convertRaw.Type != convertDuration {
+		return fmt.Errorf("decode OpConvert: 'unit' can only be used with the duration type")
+	}
+	if (convertRaw.Layout != "" || convertRaw.LayoutType != "") && convertRaw.Type != convertTimestamp {
+		return fmt.Errorf("decode OpConvert: 'layout' can only be used with the timestamp type")
+	}
+
+	op.Field = *convertRaw.Field
+	op.ValueType = convertRaw.Type
+	op.Unit = convertRaw.Unit
+	op.Layout = convertRaw.Layout
+	op.LayoutType = convertRaw.LayoutType
+	return nil
+}
+
+// byteSizeUnits are the multipliers of byte size units. Units like KB are powers
+// of 1000, and units like KiB are powers of 1024.
+var byteSizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"m":   1e6,
+	"mb":  1e6,
+	"g":   1e9,
+	"gb":  1e9,
+	"t":   1e12,
+	"tb":  1e12,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// byteSize matches a number followed by an optional unit, like 1.2MB or 512 KiB
+var byteSize = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([A-Za-z]*)$`)
+
+// toByteSize will convert a value to a number of bytes
+func toByteSize(value interface{}) (int64, error) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float64:
+		return int64(math.Round(v)), nil
+	default:
+		return 0, fmt.Errorf("type %T cannot be a byte size", value)
+	}
+
+	match := byteSize.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return 0, fmt.Errorf("'%s' is not a valid byte size", s)
+	}
+
+	multiplier, ok := byteSizeUnits[strings.ToLower(match[2])]
+	if !ok {
+		return 0, fmt.Errorf("'%s' is not a valid byte size unit", match[2])
+	}
+
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a valid byte size", s)
+	}
+
+	return int64(math.Round(number * multiplier)), nil
+}
@@ -7,7 +7,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/antonmedv/expr"
 	"github.com/antonmedv/expr/vm"
 	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/operator"
@@ -52,6 +51,20 @@ func TestRestructureOperator(t *testing.T) {
 		return e
 	}
 
+	newOrderedTestEntry := func() *entry.Entry {
+		e := entry.New()
+		e.Timestamp = time.Unix(1586632809, 0)
+		nested := entry.NewOrderedMap()
+		nested.Set("nestedkey2", "nestedval2")
+		nested.Set("nestedkey1", "nestedval1")
+		record := entry.NewOrderedMap()
+		record.Set("key", "val")
+		record.Set("nested", nested)
+		record.Set("last", "val")
+		e.Record = record
+		return e
+	}
+
 	cases := []struct {
 		name   string
 		ops    []Op
@@ -87,7 +100,7 @@ func TestRestructureOperator(t *testing.T) {
 					&OpAdd{
 						Field: entry.NewRecordField("new"),
 						program: func() *vm.Program {
-							vm, err := expr.Compile(`$.key + "_suffix"`)
+							vm, err := helper.ExprCompile(`$.key + "_suffix"`)
 							require.NoError(t, err)
 							return vm
 						}(),
@@ -108,7 +121,7 @@ func TestRestructureOperator(t *testing.T) {
 					&OpAdd{
 						Field: entry.NewRecordField("new"),
 						program: func() *vm.Program {
-							vm, err := expr.Compile(`env("TEST_RESTRUCTURE_PLUGIN_ENV")`)
+							vm, err := helper.ExprCompile(`env("TEST_RESTRUCTURE_PLUGIN_ENV")`)
 							require.NoError(t, err)
 							return vm
 						}(),
@@ -197,6 +210,51 @@ func TestRestructureOperator(t *testing.T) {
 				return e
 			}(),
 		},
+		{
+			name: "FlattenOrdered",
+			ops: []Op{
+				{
+					&OpFlatten{
+						Field: entry.RecordField{
+							Keys: []string{"nested"},
+						},
+					},
+				},
+			},
+			input: newOrderedTestEntry(),
+			output: func() *entry.Entry {
+				e := newOrderedTestEntry()
+				record := entry.NewOrderedMap()
+				record.Set("key", "val")
+				record.Set("last", "val")
+				record.Set("nestedkey2", "nestedval2")
+				record.Set("nestedkey1", "nestedval1")
+				e.Record = record
+				return e
+			}(),
+		},
+		{
+			name: "RetainOrdered",
+			ops: []Op{
+				{
+					&OpRetain{
+						Fields: []entry.Field{
+							entry.NewRecordField("last"),
+							entry.NewRecordField("key"),
+						},
+					},
+				},
+			},
+			input: newOrderedTestEntry(),
+			output: func() *entry.Entry {
+				e := newOrderedTestEntry()
+				record := entry.NewOrderedMap()
+				record.Set("last", "val")
+				record.Set("key", "val")
+				e.Record = record
+				return e
+			}(),
+		},
 	}
 
 	for _, tc := range cases {
@@ -236,7 +294,7 @@ func TestRestructureSerializeRoundtrip(t *testing.T) {
 					return &s
 				}(),
 				program: func() *vm.Program {
-					vm, err := expr.Compile(`$.key + "_suffix"`)
+					vm, err := helper.ExprCompile(`$.key + "_suffix"`)
 					require.NoError(t, err)
 					return vm
 				}(),
@@ -265,6 +323,14 @@ func TestRestructureSerializeRoundtrip(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: "Convert",
+			op: Op{&OpConvert{
+				Field:     entry.NewRecordField("duration"),
+				ValueType: "duration",
+				Unit:      "ms",
+			}},
+		},
 	}
 
 	for _, tc := range cases {
@@ -309,6 +375,9 @@ ops:
   - move:
       from: "message1"
       to: "message2"
+  - convert:
+      field: "status"
+      type: "int"
 `
 
 	configJSON := `
@@ -339,6 +408,11 @@ ops:
       "from": "message1",
       "to": "message2"
     }
+  },{
+    "convert": {
+      "field": "status",
+      "type": "int"
+    }
   }]
 }`
 
@@ -366,7 +440,7 @@ ops:
 						return &s
 					}(),
 					program: func() *vm.Program {
-						vm, err := expr.Compile(`$.message + "_suffix"`)
+						vm, err := helper.ExprCompile(`$.message + "_suffix"`)
 						require.NoError(t, err)
 						return vm
 					}(),
@@ -388,6 +462,10 @@ ops:
 					From: entry.NewRecordField("message1"),
 					To:   entry.NewRecordField("message2"),
 				}},
+				{&OpConvert{
+					Field:     entry.NewRecordField("status"),
+					ValueType: "int",
+				}},
 			},
 		},
 	})
@@ -428,6 +506,10 @@ func TestOpType(t *testing.T) {
 			&OpFlatten{},
 			"flatten",
 		},
+		{
+			&OpConvert{},
+			"convert",
+		},
 	}
 
 	for _, tc := range cases {
@@ -444,3 +526,151 @@ func TestOpType(t *testing.T) {
 		require.Contains(t, err.Error(), "unknown op type")
 	})
 }
+
+func TestOpConvert(t *testing.T) {
+	cases := []struct {
+		name     string
+		op       string
+		value    interface{}
+		expected interface{}
+	}{
+		{"Int", "type: int", "200", 200},
+		{"IntFromFloat", "type: int", 12.0, 12},
+		{"Float", "type: float", "1.5", 1.5},
+		{"Bool", "type: bool", "true", true},
+		{"EmptyString", "type: int", " ", nil},
+		{"DurationSeconds", "type: duration", "1500ms", 1.5},
+		{"DurationUnit", "type: duration\nunit: ms", "1m2.5s", 62500.0},
+		{"DurationNumber", "type: duration\nunit: ms", 2, 2000.0},
+		{"ByteSize", "type: byte_size", "1.2MB", int64(1200000)},
+		{"ByteSizeBinary", "type: byte_size", "512 KiB", int64(524288)},
+		{"ByteSizeShortUnit", "type: byte_size", "2g", int64(2000000000)},
+		{"ByteSizeBytes", "type: byte_size", "2326", int64(2326)},
+		{"ByteSizeNumber", "type: byte_size", 1024, int64(1024)},
+		{
+			"Timestamp",
+			"type: timestamp",
+			"2020-09-24T13:05:09.5Z",
+			time.Date(2020, 9, 24, 13, 5, 9, 500000000, time.UTC),
+		},
+		{
+			"TimestampLayout",
+			"type: timestamp\nlayout: '%Y-%m-%d %H:%M:%S %z'",
+			"2020-09-24 13:05:09 +0000",
+			time.Date(2020, 9, 24, 13, 5, 9, 0, time.FixedZone("", 0)),
+		},
+		{
+			"TimestampEpoch",
+			"type: timestamp\nlayout: s\nlayout_type: epoch",
+			"1600952709",
+			time.Unix(1600952709, 0),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var op OpConvert
+			err := yaml.UnmarshalStrict([]byte("field: value\n"+tc.op), &op)
+			require.NoError(t, err)
+
+			e := entry.New()
+			e.Record = map[string]interface{}{"value": tc.value, "other": "value"}
+			err = op.Apply(e)
+			require.NoError(t, err)
+
+			expected := map[string]interface{}{"value": tc.expected, "other": "value"}
+			if expectedTime, ok := tc.expected.(time.Time); ok {
+				actualTime, ok := e.Record.(map[string]interface{})["value"].(time.Time)
+				require.True(t, ok)
+				require.True(t, expectedTime.Equal(actualTime))
+				return
+			}
+			require.Equal(t, expected, e.Record)
+		})
+	}
+}
+
+func TestOpConvertMissingField(t *testing.T) {
+	op := &OpConvert{Field: entry.NewRecordField("missing"), ValueType: "int"}
+	e := entry.New()
+	e.Record = map[string]interface{}{"key": "val"}
+	err := op.Apply(e)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"key": "val"}, e.Record)
+}
+
+func TestOpConvertFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		op       string
+		value    interface{}
+		expected string
+	}{
+		{"Int", "type: int", "two hundred", "field 'value' of type 'string' can not be converted to an int"},
+		{"Float", "type: float", "1.5.1", "can not be converted to a float"},
+		{"Bool", "type: bool", "maybe", "can not be converted to a bool"},
+		{"Duration", "type: duration", "12", "can not be converted to a duration"},
+		{"ByteSize", "type: byte_size", "1.2 parsecs", "can not be converted to a byte size: 'parsecs' is not a valid byte size unit"},
+		{"ByteSizeFormat", "type: byte_size", "MB", "'MB' is not a valid byte size"},
+		{"ByteSizeType", "type: byte_size", true, "type bool cannot be a byte size"},
+		{"Timestamp", "type: timestamp", "yesterday", "can not be converted to a time"},
+		{"TimestampLayout", "type: timestamp\nlayout: '%Y-%m-%d'", "24/09/2020", "can not be converted to a time"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var op OpConvert
+			err := yaml.UnmarshalStrict([]byte("field: value\n"+tc.op), &op)
+			require.NoError(t, err)
+
+			e := entry.New()
+			e.Record = map[string]interface{}{"value": tc.value}
+			err = op.Apply(e)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+			require.Equal(t, map[string]interface{}{"value": tc.value}, e.Record)
+		})
+	}
+}
+
+func TestOpConvertOnError(t *testing.T) {
+	operator, mockOutput := NewFakeRestructureOperator()
+	operator.OnError = helper.SendOnError
+	operator.ops = []Op{{&OpConvert{Field: entry.NewRecordField("status"), ValueType: "int"}}}
+
+	var output *entry.Entry
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		output = args[1].(*entry.Entry)
+	}).Return(nil)
+
+	e := entry.New()
+	e.Record = map[string]interface{}{"status": "ok"}
+	err := operator.Process(context.Background(), e)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"status": "ok"}, output.Record)
+}
+
+func TestOpConvertUnmarshalFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      string
+		expected string
+	}{
+		{"MissingField", "type: int", "missing required field 'field'"},
+		{"MissingType", "field: value", "missing required field 'type'"},
+		{"InvalidType", "field: value\ntype: string", "invalid type 'string'"},
+		{"InvalidUnit", "field: value\ntype: duration\nunit: days", "invalid duration unit 'days'"},
+		{"UnitWithoutDuration", "field: value\ntype: int\nunit: ms", "'unit' can only be used with the duration type"},
+		{"LayoutWithoutTimestamp", "field: value\ntype: int\nlayout: '%Y'", "'layout' can only be used with the timestamp type"},
+		{"InvalidLayoutType", "field: value\ntype: timestamp\nlayout: '%Y'\nlayout_type: other", "unsupported layout_type other"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var op OpConvert
+			err := yaml.UnmarshalStrict([]byte(tc.raw), &op)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
@@ -45,7 +45,7 @@ func (c RouterOperatorConfig) Build(context operator.BuildContext) (operator.Ope
 
 	routes := make([]*RouterOperatorRoute, 0, len(c.Routes))
 	for _, routeConfig := range c.Routes {
-		compiled, err := expr.Compile(routeConfig.Expression, expr.AsBool(), expr.AllowUndefinedVariables())
+		compiled, err := helper.ExprCompile(routeConfig.Expression, expr.AsBool())
 		if err != nil {
 			return nil, fmt.Errorf("failed to compile expression '%s': %w", routeConfig.Expression, err)
 		}
@@ -0,0 +1,263 @@
+package helper
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/ast"
+	"github.com/antonmedv/expr/vm"
+	strptime "github.com/observiq/ctimefmt"
+	"github.com/observiq/stanza/entry"
+)
+
+// ExprCompile will compile an expression that can be evaluated with the environment
+// returned by GetExprEnv. The types of the available functions are checked at compile time.
+func ExprCompile(input string, options ...expr.Option) (*vm.Program, error) {
+	options = append([]expr.Option{expr.Env(exprFunctions), expr.AllowUndefinedVariables(), expr.Patch(orderedMapPatch{})}, options...)
+	return expr.Compile(input, options...)
+}
+
+// plainFunction is the name of the function that lets expressions read ordered maps
+const plainFunction = "$plain"
+
+// orderedMapPatch rewrites the values that expressions read keys from, so that ordered maps
+// in a record are read through their values instead of converting the record for every evaluation.
+type orderedMapPatch struct{}
+
+func (orderedMapPatch) Enter(*ast.Node) {}
+
+func (orderedMapPatch) Exit(node *ast.Node) {
+	switch n := (*node).(type) {
+	case *ast.PropertyNode:
+		n.Node = plainNode(n.Node)
+	case *ast.IndexNode:
+		n.Node = plainNode(n.Node)
+	case *ast.BinaryNode:
+		// Arrays are left as they are, so that constant arrays are still optimized
+		if _, ok := n.Right.(*ast.ArrayNode); !ok && (n.Operator == "in" || n.Operator == "not in") {
+			n.Right = plainNode(n.Right)
+		}
+	}
+}
+
+// plainNode will wrap a node in a call of the plain function
+func plainNode(node ast.Node) ast.Node {
+	plain := &ast.FunctionNode{Name: plainFunction, Arguments: []ast.Node{node}}
+	plain.SetLocation(node.Location())
+	return plain
+}
+
+// exprPlain will return the values of an ordered map, which the expression language
+// can read keys from. Other values are returned unchanged.
+func exprPlain(params ...interface{}) interface{} {
+	if orderedMap, ok := params[0].(*entry.OrderedMap); ok {
+		return orderedMap.Values()
+	}
+	return params[0]
+}
+
+// exprFunctions are the functions available to every expression.
+//
+// The expression language ignores any error returned by a function, so these
+// functions panic on invalid input instead. The panic is recovered when the
+// expression is run, and returned as the error of the evaluation.
+var exprFunctions = map[string]interface{}{
+	"env":           os.Getenv,
+	"lower":         exprLower,
+	"upper":         exprUpper,
+	"trim":          exprTrim,
+	"split":         exprSplit,
+	"join":          exprJoin,
+	"regex_match":   exprRegexMatch,
+	"regex_replace": exprRegexReplace,
+	"regex_extract": exprRegexExtract,
+	"json_encode":   exprJSONEncode,
+	"json_decode":   exprJSONDecode,
+	"sha256":        exprSHA256,
+	"md5":           exprMD5,
+	"format_time":   exprFormatTime,
+	"parse_time":    exprParseTime,
+	"cidr_match":    exprCIDRMatch,
+	plainFunction:   exprPlain,
+}
+
+// exprString will return the string value of a function argument
+func exprString(function string, value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		panic(fmt.Errorf("%s: expected a string but got %T", function, value))
+	}
+}
+
+func exprLower(value interface{}) string {
+	return strings.ToLower(exprString("lower", value))
+}
+
+func exprUpper(value interface{}) string {
+	return strings.ToUpper(exprString("upper", value))
+}
+
+func exprTrim(value interface{}) string {
+	return strings.TrimSpace(exprString("trim", value))
+}
+
+func exprSplit(value interface{}, separator interface{}) []interface{} {
+	parts := strings.Split(exprString("split", value), exprString("split", separator))
+	result := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		result = append(result, part)
+	}
+	return result
+}
+
+func exprJoin(values interface{}, separator interface{}) string {
+	sep := exprString("join", separator)
+	switch v := values.(type) {
+	case []string:
+		return strings.Join(v, sep)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, value := range v {
+			if s, ok := value.(string); ok {
+				parts = append(parts, s)
+			} else {
+				parts = append(parts, fmt.Sprint(value))
+			}
+		}
+		return strings.Join(parts, sep)
+	default:
+		panic(fmt.Errorf("join: expected an array but got %T", values))
+	}
+}
+
+// maxCachedRegexes limits the number of patterns kept in the regex cache, in
+// case patterns are built from the contents of entries
+const maxCachedRegexes = 256
+
+var regexCache = struct {
+	sync.RWMutex
+	regexes map[string]*regexp.Regexp
+}{regexes: map[string]*regexp.Regexp{}}
+
+// exprRegex will return the compiled form of a pattern
+func exprRegex(function string, pattern interface{}) *regexp.Regexp {
+	p := exprString(function, pattern)
+
+	regexCache.RLock()
+	compiled, ok := regexCache.regexes[p]
+	regexCache.RUnlock()
+	if ok {
+		return compiled
+	}
+
+	compiled, err := regexp.Compile(p)
+	if err != nil {
+		panic(fmt.Errorf("%s: %s", function, err))
+	}
+
+	regexCache.Lock()
+	if len(regexCache.regexes) < maxCachedRegexes {
+		regexCache.regexes[p] = compiled
+	}
+	regexCache.Unlock()
+	return compiled
+}
+
+func exprRegexMatch(value interface{}, pattern interface{}) bool {
+	return exprRegex("regex_match", pattern).MatchString(exprString("regex_match", value))
+}
+
+func exprRegexReplace(value interface{}, pattern interface{}, replacement interface{}) string {
+	regex := exprRegex("regex_replace", pattern)
+	return regex.ReplaceAllString(exprString("regex_replace", value), exprString("regex_replace", replacement))
+}
+
+// exprRegexExtract returns the first capture group of the first match, or the
+// whole match if the pattern has no capture groups. It returns an empty string
+// if the pattern does not match.
+func exprRegexExtract(value interface{}, pattern interface{}) string {
+	regex := exprRegex("regex_extract", pattern)
+	matches := regex.FindStringSubmatch(exprString("regex_extract", value))
+	switch {
+	case matches == nil:
+		return ""
+	case len(matches) == 1:
+		return matches[0]
+	default:
+		return matches[1]
+	}
+}
+
+func exprJSONEncode(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Errorf("json_encode: %s", err))
+	}
+	return string(encoded)
+}
+
+func exprJSONDecode(value interface{}) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(exprString("json_decode", value)), &decoded); err != nil {
+		panic(fmt.Errorf("json_decode: %s", err))
+	}
+	return decoded
+}
+
+func exprSHA256(value interface{}) string {
+	sum := sha256.Sum256([]byte(exprString("sha256", value)))
+	return hex.EncodeToString(sum[:])
+}
+
+func exprMD5(value interface{}) string {
+	sum := md5.Sum([]byte(exprString("md5", value)))
+	return hex.EncodeToString(sum[:])
+}
+
+func exprFormatTime(value interface{}, layout interface{}) string {
+	t, ok := value.(time.Time)
+	if !ok {
+		panic(fmt.Errorf("format_time: expected a time but got %T", value))
+	}
+
+	formatted, err := strptime.Format(exprString("format_time", layout), t)
+	if err != nil {
+		panic(fmt.Errorf("format_time: %s", err))
+	}
+	return formatted
+}
+
+func exprParseTime(value interface{}, layout interface{}) time.Time {
+	t, err := strptime.Parse(exprString("parse_time", layout), exprString("parse_time", value))
+	if err != nil {
+		panic(fmt.Errorf("parse_time: %s", err))
+	}
+	return t
+}
+
+func exprCIDRMatch(value interface{}, cidr interface{}) bool {
+	_, network, err := net.ParseCIDR(exprString("cidr_match", cidr))
+	if err != nil {
+		panic(fmt.Errorf("cidr_match: %s", err))
+	}
+
+	ip := net.ParseIP(exprString("cidr_match", value))
+	if ip == nil {
+		return false
+	}
+	return network.Contains(ip)
+}
@@ -0,0 +1,174 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/observiq/stanza/entry"
+	"github.com/stretchr/testify/require"
+)
+
+func runExpr(t *testing.T, e *entry.Entry, expression string) (interface{}, error) {
+	program, err := expr.Compile(expression, expr.AllowUndefinedVariables())
+	require.NoError(t, err)
+
+	env := GetExprEnv(e)
+	defer PutExprEnv(env)
+	return vm.Run(program, env)
+}
+
+func TestExprFunctions(t *testing.T) {
+	e := entry.New()
+	e.Timestamp = time.Date(2020, time.September, 24, 13, 5, 9, 0, time.UTC)
+	e.Severity = entry.Error
+	e.Record = map[string]interface{}{
+		"message": "  User Bob logged in from 10.1.2.3  ",
+		"ip":      "10.1.2.3",
+		"path":    "/var/log/app.log",
+		"tags":    []interface{}{"a", "b", 3},
+		"json":    `{"key":"value","list":[1,2]}`,
+		"time":    "2020-09-24 13:05:09",
+	}
+
+	cases := []struct {
+		name       string
+		expression string
+		expected   interface{}
+	}{
+		{"Lower", `lower($record.path + "/ABC")`, "/var/log/app.log/abc"},
+		{"Upper", `upper($record.ip + "abc")`, "10.1.2.3ABC"},
+		{"Trim", `trim($record.message)`, "User Bob logged in from 10.1.2.3"},
+		{"Split", `split($record.path, "/")`, []interface{}{"", "var", "log", "app.log"}},
+		{"SplitIn", `"log" in split($record.path, "/")`, true},
+		{"Join", `join($record.tags, "-")`, "a-b-3"},
+		{"JoinSplit", `join(split($record.ip, "."), ":")`, "10:1:2:3"},
+		{"RegexMatch", `regex_match($record.message, "logged (in|out)")`, true},
+		{"RegexNoMatch", `regex_match($record.message, "^logged")`, false},
+		{"RegexReplace", `regex_replace($record.ip, "\\d+$", "0")`, "10.1.2.0"},
+		{"RegexReplaceGroup", `regex_replace($record.path, "^/var/log/(.*)$", "${1}")`, "app.log"},
+		{"RegexExtractGroup", `regex_extract($record.message, "User (\\w+)")`, "Bob"},
+		{"RegexExtractMatch", `regex_extract($record.message, "\\d+\\.\\d+")`, "10.1"},
+		{"RegexExtractNoMatch", `regex_extract($record.message, "Alice")`, ""},
+		{"JSONEncode", `json_encode($record.tags)`, `["a","b",3]`},
+		{"JSONDecode", `json_decode($record.json).key`, "value"},
+		{"JSONDecodeArray", `len(json_decode($record.json).list)`, 2},
+		{"SHA256", `sha256("test")`, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+		{"MD5", `md5("test")`, "098f6bcd4621d373cade4e832627b4f6"},
+		{"FormatTime", `format_time($timestamp, "%Y/%m/%d %H:%M")`, "2020/09/24 13:05"},
+		{"ParseTime", `format_time(parse_time($record.time, "%Y-%m-%d %H:%M:%S"), "%d.%m.%y")`, "24.09.20"},
+		{"CIDRMatch", `cidr_match($record.ip, "10.0.0.0/8")`, true},
+		{"CIDRNoMatch", `cidr_match($record.ip, "192.168.0.0/16")`, false},
+		{"CIDRMatchIPv6", `cidr_match("2001:db8::1", "2001:db8::/32")`, true},
+		{"CIDRMatchInvalidIP", `cidr_match("not an ip", "10.0.0.0/8")`, false},
+		{"Severity", `$severity >= 60`, true},
+		{"SeverityEqual", `$severity == 60`, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := runExpr(t, e, tc.expression)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestExprFunctionsFailure(t *testing.T) {
+	e := entry.New()
+	e.Record = map[string]interface{}{
+		"number": 10,
+		"string": "value",
+	}
+
+	cases := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{"NotString", `lower($record.number)`, "lower: expected a string but got int"},
+		{"Missing", `upper($record.missing)`, "upper: expected a string but got <nil>"},
+		{"JoinNotArray", `join($record.string, ",")`, "join: expected an array but got string"},
+		{"InvalidRegex", `regex_match($record.string, "(")`, "regex_match: error parsing regexp"},
+		{"InvalidJSON", `json_decode($record.string)`, "json_decode: invalid character"},
+		{"FormatNotTime", `format_time($record.string, "%Y")`, "format_time: expected a time but got string"},
+		{"ParseTimeMismatch", `parse_time($record.string, "%Y")`, "parse_time:"},
+		{"InvalidCIDR", `cidr_match($record.string, "10.0.0.0")`, "cidr_match: invalid CIDR address"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := runExpr(t, e, tc.expression)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestExprCompileChecksFunctions(t *testing.T) {
+	_, err := ExprCompile(`lower($record.message, "extra")`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "too many arguments")
+
+	_, err = ExprCompile(`regex_match($record.message, "pattern")`, expr.AsBool())
+	require.NoError(t, err)
+}
+
+func TestExprCompileOrderedMaps(t *testing.T) {
+	item := entry.NewOrderedMap()
+	item.Set("name", "first")
+	nested := entry.NewOrderedMap()
+	nested.Set("key", "value")
+	nested.Set("items", []interface{}{item})
+	record := entry.NewOrderedMap()
+	record.Set("nested", nested)
+	record.Set("plain", map[string]interface{}{"ordered": nested})
+
+	e := entry.New()
+	e.Record = record
+
+	cases := []struct {
+		name       string
+		expression string
+		expected   interface{}
+	}{
+		{"Property", `$record.nested.key`, "value"},
+		{"Index", `$record["nested"]["key"]`, "value"},
+		{"ArrayIndex", `$record.nested.items[0].name`, "first"},
+		{"Closure", `all($record.nested.items, {.name == "first"})`, true},
+		{"PlainMap", `$record.plain.ordered.key`, "value"},
+		{"Missing", `$record.nested.missing`, nil},
+		{"In", `"key" in $record.nested`, true},
+		{"NotIn", `"missing" not in $`, true},
+		{"InArray", `$record.nested.key in ["value", "other"]`, true},
+		{"Value", `$record.nested`, nested},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			program, err := ExprCompile(tc.expression)
+			require.NoError(t, err)
+
+			env := GetExprEnv(e)
+			defer PutExprEnv(env)
+
+			result, err := vm.Run(program, env)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestGetExprEnvOrderedRecord(t *testing.T) {
+	record := entry.NewOrderedMap()
+	record.Set("key", "value")
+
+	e := entry.New()
+	e.Record = record
+
+	// The record is used as it is, without converting its ordered maps
+	env := GetExprEnv(e)
+	defer PutExprEnv(env)
+	require.True(t, env["$record"] == record)
+}
@@ -1,12 +1,16 @@
 package helper
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
-	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/file"
 	"github.com/antonmedv/expr/vm"
 	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/errors"
@@ -16,8 +20,9 @@ import (
 type ExprStringConfig string
 
 const (
-	exprStartToken = "EXPR("
-	exprEndToken   = ")"
+	exprStartToken  = "EXPR("
+	exprEndToken    = ")"
+	exprEscapeToken = `\`
 )
 
 // Build creates an ExprStr string from the specified config
@@ -27,6 +32,11 @@ func (e ExprStringConfig) Build() (*ExprString, error) {
 
 	subStrings := make([]string, 0, 4)
 	subExprStrings := make([]string, 0, 4)
+	subExprOffsets := make([]int, 0, 4)
+
+	// literal holds the string literal that precedes the next expression,
+	// since escaped start tokens split it into multiple parts
+	var literal strings.Builder
 
 	for {
 		rangeEnd := len(s)
@@ -36,12 +46,21 @@ func (e ExprStringConfig) Build() (*ExprString, error) {
 		if indexStart == -1 {
 			// Start token does not exist in the remainder of the string,
 			// so treat the rest as a string literal
-			subStrings = append(subStrings, s[rangeStart:])
+			literal.WriteString(s[rangeStart:])
+			subStrings = append(subStrings, literal.String())
 			break
 		} else {
 			indexStart = rangeStart + indexStart
 		}
 
+		// An escaped start token is part of the string literal
+		if strings.HasSuffix(s[rangeStart:indexStart], exprEscapeToken) {
+			literal.WriteString(s[rangeStart : indexStart-len(exprEscapeToken)])
+			literal.WriteString(exprStartToken)
+			rangeStart = indexStart + len(exprStartToken)
+			continue
+		}
+
 		// Restrict our end token search range to the next instance of the start token
 		nextIndexStart := strings.Index(s[indexStart+len(exprStartToken):], exprStartToken)
 		if nextIndexStart == -1 {
@@ -56,15 +75,19 @@ func (e ExprStringConfig) Build() (*ExprString, error) {
 			// End token does not exist before the next start token
 			// or end of expression string, so treat the remainder of the string
 			// as a string literal
-			subStrings = append(subStrings, s[rangeStart:])
+			literal.WriteString(strings.ReplaceAll(s[rangeStart:], exprEscapeToken+exprStartToken, exprStartToken))
+			subStrings = append(subStrings, literal.String())
 			break
 		} else {
 			indexEnd = indexStart + indexEnd
 		}
 
 		// Unscope the indexes and add the partitioned strings
-		subStrings = append(subStrings, s[rangeStart:indexStart])
+		literal.WriteString(s[rangeStart:indexStart])
+		subStrings = append(subStrings, literal.String())
+		literal.Reset()
 		subExprStrings = append(subExprStrings, s[indexStart+len(exprStartToken):indexEnd])
+		subExprOffsets = append(subExprOffsets, indexStart+len(exprStartToken))
 
 		// Reset the starting range and finish if it reaches the end of the string
 		rangeStart = indexEnd + len(exprEndToken)
@@ -74,10 +97,10 @@ func (e ExprStringConfig) Build() (*ExprString, error) {
 	}
 
 	subExprs := make([]*vm.Program, 0, len(subExprStrings))
-	for _, subExprString := range subExprStrings {
-		program, err := expr.Compile(subExprString, expr.AllowUndefinedVariables())
+	for i, subExprString := range subExprStrings {
+		program, err := ExprCompile(subExprString)
 		if err != nil {
-			return nil, errors.Wrap(err, "compile embedded expression")
+			return nil, compileError(s, subExprString, subExprOffsets[i], err)
 		}
 		subExprs = append(subExprs, program)
 	}
@@ -88,6 +111,34 @@ func (e ExprStringConfig) Build() (*ExprString, error) {
 	}, nil
 }
 
+// compileError will create an error for an embedded expression that failed to compile.
+// The error details include the column of the error within the full expression string.
+func compileError(s string, subExprString string, offset int, err error) error {
+	fileErr, ok := err.(*file.Error)
+	if !ok {
+		return errors.Wrap(err, "compile embedded expression").WithDetails("expression", subExprString)
+	}
+
+	// The location of the error is relative to the embedded expression
+	line := strings.Count(s[:offset], "\n") + fileErr.Line
+	column := fileErr.Column + 1
+	if fileErr.Line == 1 {
+		lineStart := strings.LastIndex(s[:offset], "\n") + 1
+		column += utf8.RuneCountInString(s[lineStart:offset])
+	}
+
+	details := []string{"expression", subExprString, "column", strconv.Itoa(column)}
+	if strings.Contains(s, "\n") {
+		details = append(details, "line", strconv.Itoa(line))
+	}
+
+	return errors.NewError(
+		fmt.Sprintf("compile embedded expression: %s", fileErr.Message),
+		"check the syntax of the expression at the reported column",
+		details...,
+	)
+}
+
 // An ExprString is made up of a list of string literals
 // interleaved with expressions. len(SubStrings) == len(SubExprs) + 1
 type ExprString struct {
@@ -104,9 +155,9 @@ func (e *ExprString) Render(env map[string]interface{}) (string, error) {
 		if err != nil {
 			return "", errors.Wrap(err, "render embedded expression")
 		}
-		outString, ok := out.(string)
-		if !ok {
-			return "", fmt.Errorf("embedded expression returned non-string %v", out)
+		outString, err := renderValue(out)
+		if err != nil {
+			return "", errors.Wrap(err, "render embedded expression")
 		}
 		b.WriteString(outString)
 	}
@@ -115,11 +166,56 @@ func (e *ExprString) Render(env map[string]interface{}) (string, error) {
 	return b.String(), nil
 }
 
+// renderValue will format the result of an embedded expression as a string.
+// Maps and arrays are formatted as JSON, and nil as an empty string.
+func renderValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case map[string]interface{}, map[string]string, []interface{}, []string, *entry.OrderedMap:
+		marshalled, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(marshalled), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
+
 var envPool = sync.Pool{
 	New: func() interface{} {
-		return map[string]interface{}{
-			"env": os.Getenv,
+		env := make(map[string]interface{}, len(exprFunctions)+12)
+		for name, function := range exprFunctions {
+			env[name] = function
 		}
+		return env
 	},
 }
 
@@ -131,6 +227,12 @@ func GetExprEnv(e *entry.Entry) map[string]interface{} {
 	env["$labels"] = e.Labels
 	env["$resource"] = e.Resource
 	env["$timestamp"] = e.Timestamp
+	env["$severity"] = int(e.Severity)
+	env["$observed_timestamp"] = e.ObservedTimestamp
+	env["$severity_text"] = e.SeverityText
+	env["$trace_id"] = hex.EncodeToString(e.TraceID)
+	env["$span_id"] = hex.EncodeToString(e.SpanID)
+	env["$trace_flags"] = hex.EncodeToString(e.TraceFlags)
 
 	return env
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,6 +22,19 @@ func TestExprString(t *testing.T) {
 		e.Resource = map[string]string{
 			"id": "value",
 		}
+		e.TraceID = []byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff}
+		e.SpanID = []byte{0x92, 0xc3, 0x79, 0x2d, 0x54, 0xba, 0x94, 0xf3}
+		e.TraceFlags = []byte{0x01}
+		e.SeverityText = "WARN"
+		e.Record = map[string]interface{}{
+			"test":    "value",
+			"port":    8080,
+			"ratio":   0.25,
+			"float":   float64(3),
+			"enabled": true,
+			"map":     map[string]interface{}{"key": "value"},
+			"list":    []interface{}{"a", 1},
+		}
 		return e
 	}
 
@@ -84,6 +98,54 @@ func TestExprString(t *testing.T) {
 			"EXPR( $resource.id )",
 			"value",
 		},
+		{
+			"EXPR( $trace_id )-EXPR( $span_id )-EXPR( $trace_flags )",
+			"480140f3d770a5ae32f0a22b6a812cff-92c3792d54ba94f3-01",
+		},
+		{
+			"EXPR( $severity_text )",
+			"WARN",
+		},
+		{
+			"EXPR( lower($severity_text) )-EXPR( upper($.test) )",
+			"warn-VALUE",
+		},
+		{
+			"port: EXPR($.port)",
+			"port: 8080",
+		},
+		{
+			"EXPR($.ratio) EXPR($.float) EXPR($.port + 1)",
+			"0.25 3 8081",
+		},
+		{
+			"EXPR($.enabled) EXPR(!$.enabled)",
+			"true false",
+		},
+		{
+			"[EXPR($.missing)]",
+			"[]",
+		},
+		{
+			"EXPR($.map) EXPR($.list)",
+			`{"key":"value"} ["a",1]`,
+		},
+		{
+			`\EXPR( 'test' )`,
+			"EXPR( 'test' )",
+		},
+		{
+			`prefix-\EXPR(-EXPR( $.test )-\EXPR(`,
+			"prefix-EXPR(-value-EXPR(",
+		},
+		{
+			`\EXPR(EXPR( $.test )`,
+			"EXPR(value",
+		},
+		{
+			`EXPR( \EXPR(`,
+			"EXPR( EXPR(",
+		},
 	}
 
 	for i, tc := range cases {
@@ -100,3 +162,79 @@ func TestExprString(t *testing.T) {
 		})
 	}
 }
+
+func TestExprStringOrderedRecord(t *testing.T) {
+	nested := entry.NewOrderedMap()
+	nested.Set("key", "value")
+	record := entry.NewOrderedMap()
+	record.Set("nested", nested)
+
+	e := entry.New()
+	e.Record = record
+
+	exprString, err := ExprStringConfig("EXPR($record.nested.key)").Build()
+	require.NoError(t, err)
+
+	env := GetExprEnv(e)
+	defer PutExprEnv(env)
+
+	result, err := exprString.Render(env)
+	require.NoError(t, err)
+	require.Equal(t, "value", result)
+}
+
+func TestExprStringRenderOrderedMap(t *testing.T) {
+	record := entry.NewOrderedMap()
+	record.Set("b", 1)
+	record.Set("a", 2)
+
+	e := entry.New()
+	e.Record = map[string]interface{}{"ordered": record}
+
+	exprString, err := ExprStringConfig("EXPR($record.ordered)").Build()
+	require.NoError(t, err)
+
+	env := GetExprEnv(e)
+	defer PutExprEnv(env)
+
+	// Ordered maps keep their order when they are rendered
+	result, err := exprString.Render(env)
+	require.NoError(t, err)
+	require.Equal(t, `{"b":1,"a":2}`, result)
+}
+
+func TestExprStringCompileError(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   ExprStringConfig
+		expected errors.ErrorDetails
+	}{
+		{
+			"SingleLine",
+			"prefix EXPR( $.test +  )",
+			errors.ErrorDetails{"expression": " $.test +  ", "column": "23"},
+		},
+		{
+			"SecondExpression",
+			"EXPR($.test) EXPR($.test $.other)",
+			errors.ErrorDetails{"expression": "$.test $.other", "column": "26"},
+		},
+		{
+			"MultiLine",
+			"first line\nsecond EXPR($.test ==)",
+			errors.ErrorDetails{"expression": "$.test ==", "column": "21", "line": "2"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.config.Build()
+			require.Error(t, err)
+
+			agentErr, ok := err.(errors.AgentError)
+			require.True(t, ok)
+			require.Contains(t, agentErr.Description, "compile embedded expression")
+			require.Equal(t, tc.expected, agentErr.Details)
+		})
+	}
+}
@@ -146,4 +146,6 @@ func TestInputOperatorNewEntry(t *testing.T) {
 	resourceValue, exists := entry.Resource["resource-key"]
 	require.True(t, exists)
 	require.Equal(t, "resource", resourceValue)
+
+	require.False(t, entry.ObservedTimestamp.IsZero())
 }
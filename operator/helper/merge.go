@@ -0,0 +1,142 @@
+package helper
+
+import (
+	"fmt"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+)
+
+const (
+	// MergeKeepExisting keeps the existing value of a key that is also parsed
+	MergeKeepExisting = "keep"
+	// MergeOverwrite replaces the existing value of a key that is also parsed
+	MergeOverwrite = "overwrite"
+	// MergePrefix keeps the existing value of a key that is also parsed, and adds the parsed value with a prefixed key
+	MergePrefix = "prefix"
+)
+
+// DefaultMergePrefix is the prefix added to conflicting keys if no prefix is configured
+const DefaultMergePrefix = "parsed_"
+
+// MergeConfig configures how a parser merges parsed values into an existing value at parse_to
+type MergeConfig struct {
+	Conflict string `json:"conflict,omitempty" yaml:"conflict,omitempty"`
+	Prefix   string `json:"prefix,omitempty"   yaml:"prefix,omitempty"`
+}
+
+// Build will build a merger from the config
+func (c MergeConfig) Build() (Merger, error) {
+	merger := Merger{
+		Conflict: c.Conflict,
+		Prefix:   c.Prefix,
+	}
+
+	switch merger.Conflict {
+	case "":
+		merger.Conflict = MergeOverwrite
+	case MergeKeepExisting, MergeOverwrite, MergePrefix:
+	default:
+		return Merger{}, errors.NewError(
+			fmt.Sprintf("invalid merge conflict policy '%s'", c.Conflict),
+			"specify a valid merge conflict policy",
+			"conflict", c.Conflict,
+			"valid_policies", fmt.Sprintf("%s, %s, %s", MergeKeepExisting, MergeOverwrite, MergePrefix),
+		)
+	}
+
+	if merger.Conflict == MergePrefix && merger.Prefix == "" {
+		merger.Prefix = DefaultMergePrefix
+	}
+
+	if merger.Conflict != MergePrefix && c.Prefix != "" {
+		return Merger{}, errors.NewError(
+			"merge prefix is only used with the prefix conflict policy",
+			"remove the prefix or set conflict to prefix",
+			"conflict", merger.Conflict,
+		)
+	}
+
+	return merger, nil
+}
+
+// Merger deep merges parsed maps into existing maps
+type Merger struct {
+	Conflict string
+	Prefix   string
+}
+
+// Merge will deep merge the parsed value into the existing value and return the result.
+// Maps that exist in both values are merged recursively, and any other key that exists
+// in both is resolved with the conflict policy. If either value is not a map, the existing
+// value is kept with the keep policy, and replaced by the parsed value otherwise, as there
+// is no key to prefix. The existing value is modified in place.
+func (m *Merger) Merge(existing, parsed interface{}) interface{} {
+	if !isMergeable(existing) || !isMergeable(parsed) {
+		if existing != nil && m.Conflict == MergeKeepExisting {
+			return existing
+		}
+		return parsed
+	}
+
+	rangeMergeable(parsed, func(key string, value interface{}) {
+		current, ok := getMergeable(existing, key)
+		switch {
+		case !ok:
+			setMergeable(existing, key, value)
+		case isMergeable(current) && isMergeable(value):
+			setMergeable(existing, key, m.Merge(current, value))
+		case m.Conflict == MergeOverwrite:
+			setMergeable(existing, key, value)
+		case m.Conflict == MergePrefix:
+			setMergeable(existing, m.Prefix+key, value)
+		}
+	})
+
+	return existing
+}
+
+// isMergeable returns true if the value is a map that can be merged
+func isMergeable(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, *entry.OrderedMap:
+		return true
+	default:
+		return false
+	}
+}
+
+func rangeMergeable(m interface{}, f func(key string, value interface{})) {
+	switch typed := m.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			f(key, value)
+		}
+	case *entry.OrderedMap:
+		for _, key := range typed.Keys() {
+			value, _ := typed.Get(key)
+			f(key, value)
+		}
+	}
+}
+
+func getMergeable(m interface{}, key string) (interface{}, bool) {
+	switch typed := m.(type) {
+	case map[string]interface{}:
+		value, ok := typed[key]
+		return value, ok
+	case *entry.OrderedMap:
+		return typed.Get(key)
+	default:
+		return nil, false
+	}
+}
+
+func setMergeable(m interface{}, key string, value interface{}) {
+	switch typed := m.(type) {
+	case map[string]interface{}:
+		typed[key] = value
+	case *entry.OrderedMap:
+		typed.Set(key, value)
+	}
+}
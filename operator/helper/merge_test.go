@@ -0,0 +1,195 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConfigBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		config    MergeConfig
+		expected  Merger
+		expectErr bool
+	}{
+		{"Default", MergeConfig{}, Merger{Conflict: MergeOverwrite}, false},
+		{"Keep", MergeConfig{Conflict: "keep"}, Merger{Conflict: MergeKeepExisting}, false},
+		{"PrefixDefault", MergeConfig{Conflict: "prefix"}, Merger{Conflict: MergePrefix, Prefix: DefaultMergePrefix}, false},
+		{"PrefixCustom", MergeConfig{Conflict: "prefix", Prefix: "new_"}, Merger{Conflict: MergePrefix, Prefix: "new_"}, false},
+		{"InvalidConflict", MergeConfig{Conflict: "replace"}, Merger{}, true},
+		{"PrefixWithoutPolicy", MergeConfig{Prefix: "new_"}, Merger{}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			merger, err := tc.config.Build()
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, merger)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	existing := func() map[string]interface{} {
+		return map[string]interface{}{
+			"key":   "existing",
+			"other": "existing",
+			"nested": map[string]interface{}{
+				"key":   "existing",
+				"other": "existing",
+			},
+		}
+	}
+
+	parsed := func() map[string]interface{} {
+		return map[string]interface{}{
+			"key": "parsed",
+			"new": "parsed",
+			"nested": map[string]interface{}{
+				"key": "parsed",
+			},
+		}
+	}
+
+	cases := []struct {
+		name     string
+		conflict string
+		existing interface{}
+		parsed   interface{}
+		expected interface{}
+	}{
+		{
+			"Overwrite",
+			MergeOverwrite,
+			existing(),
+			parsed(),
+			map[string]interface{}{
+				"key":   "parsed",
+				"other": "existing",
+				"new":   "parsed",
+				"nested": map[string]interface{}{
+					"key":   "parsed",
+					"other": "existing",
+				},
+			},
+		},
+		{
+			"Keep",
+			MergeKeepExisting,
+			existing(),
+			parsed(),
+			map[string]interface{}{
+				"key":   "existing",
+				"other": "existing",
+				"new":   "parsed",
+				"nested": map[string]interface{}{
+					"key":   "existing",
+					"other": "existing",
+				},
+			},
+		},
+		{
+			"Prefix",
+			MergePrefix,
+			existing(),
+			parsed(),
+			map[string]interface{}{
+				"key":        "existing",
+				"parsed_key": "parsed",
+				"other":      "existing",
+				"new":        "parsed",
+				"nested": map[string]interface{}{
+					"key":        "existing",
+					"parsed_key": "parsed",
+					"other":      "existing",
+				},
+			},
+		},
+		{
+			"MapOverValue",
+			MergeKeepExisting,
+			map[string]interface{}{"nested": "existing"},
+			parsed(),
+			map[string]interface{}{
+				"key":    "parsed",
+				"new":    "parsed",
+				"nested": "existing",
+			},
+		},
+		{
+			"ExistingNotMapKeep",
+			MergeKeepExisting,
+			"existing",
+			parsed(),
+			"existing",
+		},
+		{
+			"ExistingNotMapOverwrite",
+			MergeOverwrite,
+			"existing",
+			parsed(),
+			parsed(),
+		},
+		{
+			"ExistingNotMapPrefix",
+			MergePrefix,
+			"existing",
+			parsed(),
+			parsed(),
+		},
+		{
+			"ExistingNilKeep",
+			MergeKeepExisting,
+			nil,
+			parsed(),
+			parsed(),
+		},
+		{
+			"ParsedNotMapKeep",
+			MergeKeepExisting,
+			existing(),
+			"parsed",
+			existing(),
+		},
+		{
+			"ParsedNotMapOverwrite",
+			MergeOverwrite,
+			existing(),
+			"parsed",
+			"parsed",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			merger := Merger{Conflict: tc.conflict, Prefix: DefaultMergePrefix}
+			require.Equal(t, tc.expected, merger.Merge(tc.existing, tc.parsed))
+		})
+	}
+}
+
+func TestMergeOrderedMap(t *testing.T) {
+	existing := entry.NewOrderedMap()
+	existing.Set("b", "existing")
+	existing.Set("a", "existing")
+
+	parsed := entry.NewOrderedMap()
+	parsed.Set("d", "parsed")
+	parsed.Set("a", "parsed")
+	parsed.Set("c", "parsed")
+
+	merger := Merger{Conflict: MergeOverwrite}
+	merged, ok := merger.Merge(existing, parsed).(*entry.OrderedMap)
+	require.True(t, ok)
+
+	// Existing keys keep their position, and new keys are added in parsed order
+	require.Equal(t, []string{"b", "a", "d", "c"}, merged.Keys())
+	value, _ := merged.Get("a")
+	require.Equal(t, "parsed", value)
+}
@@ -0,0 +1,53 @@
+package helper
+
+import (
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+)
+
+// DefaultParseErrorLabel is the label that describes a parse error if no label is configured
+const DefaultParseErrorLabel = "parse_error"
+
+// ParseErrorConfig configures how a parser annotates entries that it failed to parse
+type ParseErrorConfig struct {
+	Label    string      `json:"label,omitempty"    yaml:"label,omitempty"`
+	Severity interface{} `json:"severity,omitempty" yaml:"severity,omitempty"`
+}
+
+// Build will build a parse error annotator from the config
+func (c ParseErrorConfig) Build() (ParseErrorAnnotator, error) {
+	annotator := ParseErrorAnnotator{
+		Label: c.Label,
+	}
+
+	if annotator.Label == "" {
+		annotator.Label = DefaultParseErrorLabel
+	}
+
+	if c.Severity != nil {
+		severity, err := validateSeverity(c.Severity)
+		if err != nil {
+			return ParseErrorAnnotator{}, errors.Wrap(err, "parse_error severity")
+		}
+		annotator.Severity = &severity
+	}
+
+	return annotator, nil
+}
+
+// ParseErrorAnnotator annotates entries that a parser failed to parse
+type ParseErrorAnnotator struct {
+	Label    string
+	Severity *entry.Severity
+}
+
+// Annotate will describe a parse error on the labels of an entry, and override its severity if configured.
+// The label contains the error message, and is accompanied by a label with the ID of the operator.
+func (a *ParseErrorAnnotator) Annotate(entry *entry.Entry, operatorID string, err error) {
+	entry.AddLabel(a.Label, err.Error())
+	entry.AddLabel(a.Label+"_operator", operatorID)
+
+	if a.Severity != nil {
+		entry.Severity = *a.Severity
+	}
+}
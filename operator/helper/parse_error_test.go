@@ -0,0 +1,71 @@
+package helper
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrorConfigBuild(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   ParseErrorConfig
+		expected ParseErrorAnnotator
+	}{
+		{
+			"Default",
+			ParseErrorConfig{},
+			ParseErrorAnnotator{Label: DefaultParseErrorLabel},
+		},
+		{
+			"CustomLabel",
+			ParseErrorConfig{Label: "failure"},
+			ParseErrorAnnotator{Label: "failure"},
+		},
+		{
+			"SeverityAlias",
+			ParseErrorConfig{Severity: "warning"},
+			ParseErrorAnnotator{Label: DefaultParseErrorLabel, Severity: severityPtr(entry.Warning)},
+		},
+		{
+			"SeverityInt",
+			ParseErrorConfig{Severity: 55},
+			ParseErrorAnnotator{Label: DefaultParseErrorLabel, Severity: severityPtr(entry.Severity(55))},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			annotator, err := tc.config.Build()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, annotator)
+		})
+	}
+}
+
+func TestParseErrorConfigBuildInvalidSeverity(t *testing.T) {
+	_, err := ParseErrorConfig{Severity: "invalid"}.Build()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "parse_error severity")
+}
+
+func TestParseErrorAnnotate(t *testing.T) {
+	annotator := ParseErrorAnnotator{Label: "parse_error", Severity: severityPtr(entry.Error)}
+	e := entry.New()
+	e.Severity = entry.Info
+
+	annotator.Annotate(e, "$.my_parser", fmt.Errorf("invalid input"))
+
+	expectedLabels := map[string]string{
+		"parse_error":          "invalid input",
+		"parse_error_operator": "$.my_parser",
+	}
+	require.Equal(t, expectedLabels, e.Labels)
+	require.Equal(t, entry.Error, e.Severity)
+}
+
+func severityPtr(severity entry.Severity) *entry.Severity {
+	return &severity
+}
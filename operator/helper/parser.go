@@ -27,6 +27,9 @@ type ParserConfig struct {
 	Preserve             bool                  `json:"preserve"   yaml:"preserve"`
 	TimeParser           *TimeParser           `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
 	SeverityParserConfig *SeverityParserConfig `json:"severity,omitempty" yaml:"severity,omitempty"`
+	TraceParser          *TraceParser          `json:"trace,omitempty"     yaml:"trace,omitempty"`
+	ParseErrorConfig     *ParseErrorConfig     `json:"parse_error,omitempty" yaml:"parse_error,omitempty"`
+	MergeConfig          *MergeConfig          `json:"merge,omitempty"       yaml:"merge,omitempty"`
 }
 
 // Build will build a parser operator.
@@ -66,21 +69,55 @@ func (c ParserConfig) Build(context operator.BuildContext) (ParserOperator, erro
 		parserOperator.SeverityParser = &severityParser
 	}
 
+	if c.TraceParser != nil {
+		if err := c.TraceParser.Validate(context); err != nil {
+			return ParserOperator{}, err
+		}
+		parserOperator.TraceParser = c.TraceParser
+	}
+
+	if c.ParseErrorConfig != nil {
+		annotator, err := c.ParseErrorConfig.Build()
+		if err != nil {
+			return ParserOperator{}, err
+		}
+		parserOperator.ParseErrorAnnotator = &annotator
+	}
+
+	if c.MergeConfig != nil {
+		merger, err := c.MergeConfig.Build()
+		if err != nil {
+			return ParserOperator{}, err
+		}
+		parserOperator.Merger = &merger
+	}
+
 	return parserOperator, nil
 }
 
 // ParserOperator provides a basic implementation of a parser operator.
 type ParserOperator struct {
 	TransformerOperator
-	ParseFrom      entry.Field
-	ParseTo        entry.Field
-	Preserve       bool
-	TimeParser     *TimeParser
-	SeverityParser *SeverityParser
+	ParseFrom           entry.Field
+	ParseTo             entry.Field
+	Preserve            bool
+	TimeParser          *TimeParser
+	SeverityParser      *SeverityParser
+	TraceParser         *TraceParser
+	ParseErrorAnnotator *ParseErrorAnnotator
+	Merger              *Merger
 }
 
 // ProcessWith will process an entry with a parser function.
-func (p *ParserOperator) ProcessWith(ctx context.Context, entry *entry.Entry, parse ParseFunction) error {
+func (p *ParserOperator) ProcessWith(ctx context.Context, e *entry.Entry, parse ParseFunction) error {
+	return p.ProcessIf(ctx, e, func(ctx context.Context, e *entry.Entry) error {
+		return p.ParseWith(ctx, e, parse)
+	})
+}
+
+// ParseWith will parse an entry with a parser function and send it to the output,
+// whether or not it matches the if expression.
+func (p *ParserOperator) ParseWith(ctx context.Context, entry *entry.Entry, parse ParseFunction) error {
 	value, ok := entry.Get(p.ParseFrom)
 	if !ok {
 		err := errors.NewError(
@@ -100,6 +137,14 @@ func (p *ParserOperator) ProcessWith(ctx context.Context, entry *entry.Entry, pa
 		entry.Delete(p.ParseFrom)
 	}
 
+	// The existing value is removed from the entry before it is merged,
+	// so that setting the merged value replaces it
+	if p.Merger != nil {
+		if existing, ok := entry.Delete(p.ParseTo); ok {
+			newValue = p.Merger.Merge(existing, newValue)
+		}
+	}
+
 	if err := entry.Set(p.ParseTo, newValue); err != nil {
 		return p.HandleEntryError(ctx, entry, errors.Wrap(err, "set parse_to"))
 	}
@@ -114,17 +159,34 @@ func (p *ParserOperator) ProcessWith(ctx context.Context, entry *entry.Entry, pa
 		severityParseErr = p.SeverityParser.Parse(ctx, entry)
 	}
 
-	// Handle time or severity parsing errors after attempting to parse both
+	var traceParseErr error
+	if p.TraceParser != nil {
+		traceParseErr = p.TraceParser.Parse(ctx, entry)
+	}
+
+	// Handle time, severity or trace parsing errors after attempting to parse all of them
 	if timeParseErr != nil {
 		return p.HandleEntryError(ctx, entry, errors.Wrap(timeParseErr, "time parser"))
 	}
 	if severityParseErr != nil {
 		return p.HandleEntryError(ctx, entry, errors.Wrap(severityParseErr, "severity parser"))
 	}
+	if traceParseErr != nil {
+		return p.HandleEntryError(ctx, entry, errors.Wrap(traceParseErr, "trace parser"))
+	}
 
 	p.Write(ctx, entry)
 	return nil
 }
 
+// HandleEntryError will annotate an entry that failed to parse if it will be sent on,
+// and handle the error using the on_error strategy.
+func (p *ParserOperator) HandleEntryError(ctx context.Context, entry *entry.Entry, err error) error {
+	if p.ParseErrorAnnotator != nil && p.OnError == SendOnError {
+		p.ParseErrorAnnotator.Annotate(entry, p.ID(), err)
+	}
+	return p.TransformerOperator.HandleEntryError(ctx, entry, err)
+}
+
 // ParseFunction is function that parses a raw value.
 type ParseFunction = func(interface{}) (interface{}, error)
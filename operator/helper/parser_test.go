@@ -36,6 +36,21 @@ func TestParserConfigInvalidTimeParser(t *testing.T) {
 	require.Contains(t, err.Error(), "missing required configuration parameter `layout`")
 }
 
+func TestParserConfigInvalidTimeParserLocation(t *testing.T) {
+	cfg := NewParserConfig("test-id", "test-type")
+	f := entry.NewRecordField("timestamp")
+	cfg.TimeParser = &TimeParser{
+		ParseFrom:  &f,
+		Layout:     "%Y-%m-%d",
+		LayoutType: "strptime",
+		Location:   "Not/A_Location",
+	}
+
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid location 'Not/A_Location'")
+}
+
 func TestParserConfigBuildValid(t *testing.T) {
 	cfg := NewParserConfig("test-id", "test-type")
 	f := entry.NewRecordField("timestamp")
@@ -247,6 +262,35 @@ func TestParserValidTimeInvalidSeverityParse(t *testing.T) {
 	require.Equal(t, expected, testEntry.Timestamp)
 }
 
+func TestParserInvalidTraceParse(t *testing.T) {
+	buildContext := testutil.NewBuildContext(t)
+	traceParser := NewTraceParser()
+	parser := ParserOperator{
+		TransformerOperator: TransformerOperator{
+			WriterOperator: WriterOperator{
+				BasicOperator: BasicOperator{
+					OperatorID:    "test-id",
+					OperatorType:  "test-type",
+					SugaredLogger: buildContext.Logger,
+				},
+			},
+			OnError: DropOnError,
+		},
+		ParseFrom:   entry.NewRecordField(),
+		ParseTo:     entry.NewRecordField(),
+		TraceParser: &traceParser,
+	}
+	parse := func(i interface{}) (interface{}, error) {
+		return i, nil
+	}
+	ctx := context.Background()
+	testEntry := entry.New()
+	testEntry.Record = map[string]interface{}{"trace_id": "invalid"}
+	err := parser.ProcessWith(ctx, testEntry, parse)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "trace parser: trace_id")
+}
+
 func TestParserOutput(t *testing.T) {
 	output := &testutil.Operator{}
 	output.On("ID").Return("test-output")
@@ -356,3 +400,152 @@ func TestParserWithoutPreserve(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, "test-value", actualValue)
 }
+
+func TestParserIfSkip(t *testing.T) {
+	output := &testutil.Operator{}
+	output.On("ID").Return("test-output")
+	output.On("Process", mock.Anything, mock.Anything).Return(nil)
+
+	cfg := NewParserConfig("test-id", "test-type")
+	cfg.ParseFrom = entry.NewRecordField("parse_from")
+	cfg.ParseTo = entry.NewRecordField("parse_to")
+	cfg.IfExpr = `$record.parse_from != "skip"`
+	parser, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser.OutputOperators = []operator.Operator{output}
+
+	parse := func(i interface{}) (interface{}, error) {
+		return "parsed", nil
+	}
+	ctx := context.Background()
+
+	skippedEntry := entry.New()
+	err = skippedEntry.Set(parser.ParseFrom, "skip")
+	require.NoError(t, err)
+	err = parser.ProcessWith(ctx, skippedEntry, parse)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"parse_from": "skip"}, skippedEntry.Record)
+
+	parsedEntry := entry.New()
+	err = parsedEntry.Set(parser.ParseFrom, "value")
+	require.NoError(t, err)
+	err = parser.ProcessWith(ctx, parsedEntry, parse)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"parse_to": "parsed"}, parsedEntry.Record)
+
+	// ParseWith parses entries that do not match the if expression
+	forcedEntry := entry.New()
+	err = forcedEntry.Set(parser.ParseFrom, "skip")
+	require.NoError(t, err)
+	err = parser.ParseWith(ctx, forcedEntry, parse)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"parse_to": "parsed"}, forcedEntry.Record)
+
+	output.AssertNumberOfCalls(t, "Process", 3)
+}
+
+func TestParserParseErrorAnnotation(t *testing.T) {
+	cases := []struct {
+		name      string
+		onError   string
+		annotated bool
+	}{
+		{"Send", SendOnError, true},
+		{"Drop", DropOnError, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := &testutil.Operator{}
+			output.On("ID").Return("test-output")
+			output.On("Process", mock.Anything, mock.Anything).Return(nil)
+
+			cfg := NewParserConfig("test-id", "test-type")
+			cfg.OnError = tc.onError
+			cfg.ParseErrorConfig = &ParseErrorConfig{Severity: "error"}
+			parser, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			parser.OutputOperators = []operator.Operator{output}
+
+			parse := func(i interface{}) (interface{}, error) {
+				return nil, fmt.Errorf("parse failure")
+			}
+
+			testEntry := entry.New()
+			testEntry.Record = "unparsed"
+			_ = parser.ProcessWith(context.Background(), testEntry, parse)
+
+			if !tc.annotated {
+				require.Nil(t, testEntry.Labels)
+				require.Equal(t, entry.Default, testEntry.Severity)
+				return
+			}
+
+			expectedLabels := map[string]string{
+				"parse_error":          "parse failure",
+				"parse_error_operator": "test-id",
+			}
+			require.Equal(t, expectedLabels, testEntry.Labels)
+			require.Equal(t, entry.Error, testEntry.Severity)
+			require.Equal(t, "unparsed", testEntry.Record)
+			output.AssertCalled(t, "Process", mock.Anything, testEntry)
+		})
+	}
+}
+
+func TestParserMerge(t *testing.T) {
+	output := &testutil.Operator{}
+	output.On("ID").Return("test-output")
+	output.On("Process", mock.Anything, mock.Anything).Return(nil)
+
+	cfg := NewParserConfig("test-id", "test-type")
+	cfg.ParseFrom = entry.NewRecordField("message")
+	cfg.MergeConfig = &MergeConfig{Conflict: MergePrefix}
+	parser, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	parser.OutputOperators = []operator.Operator{output}
+
+	parse := func(i interface{}) (interface{}, error) {
+		return map[string]interface{}{
+			"host": "parsed",
+			"http": map[string]interface{}{
+				"status": 200,
+			},
+		}, nil
+	}
+
+	original := entry.New()
+	original.Record = map[string]interface{}{
+		"message": "unparsed",
+		"host":    "existing",
+		"http": map[string]interface{}{
+			"method": "GET",
+		},
+	}
+	testEntry := original.Copy()
+
+	err = parser.ProcessWith(context.Background(), testEntry, parse)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"host":        "existing",
+		"parsed_host": "parsed",
+		"http": map[string]interface{}{
+			"method": "GET",
+			"status": 200,
+		},
+	}
+	require.Equal(t, expected, testEntry.Record)
+
+	// The original entry is not modified by the merge into its copy
+	require.Equal(t, map[string]interface{}{"method": "GET"}, original.Record.(map[string]interface{})["http"])
+	require.Equal(t, "unparsed", original.Record.(map[string]interface{})["message"])
+}
+
+func TestParserConfigInvalidMerge(t *testing.T) {
+	cfg := NewParserConfig("test-id", "test-type")
+	cfg.MergeConfig = &MergeConfig{Conflict: "invalid"}
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid merge conflict policy")
+}
@@ -3,6 +3,7 @@ package helper
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -15,6 +16,9 @@ type SeverityParser struct {
 	ParseFrom entry.Field
 	Preserve  bool
 	Mapping   severityMap
+
+	ranges   []severityRange
+	patterns []severityPattern
 }
 
 // Parse will parse severity from a field and attach it to the entry
@@ -28,7 +32,7 @@ func (p *SeverityParser) Parse(ctx context.Context, ent *entry.Entry) error {
 		)
 	}
 
-	severity, err := p.Mapping.find(value)
+	severity, sevText, err := p.find(value)
 	if err != nil {
 		return errors.Wrap(err, "parse")
 	}
@@ -36,6 +40,7 @@ func (p *SeverityParser) Parse(ctx context.Context, ent *entry.Entry) error {
 		severity = entry.Default
 	}
 	ent.Severity = severity
+	ent.SeverityText = sevText
 
 	if !p.Preserve {
 		ent.Delete(p.ParseFrom)
@@ -46,24 +51,66 @@ func (p *SeverityParser) Parse(ctx context.Context, ent *entry.Entry) error {
 
 type severityMap map[string]entry.Severity
 
-func (m severityMap) find(value interface{}) (entry.Severity, error) {
-	switch v := value.(type) {
-	case int:
-		if severity, ok := m[strconv.Itoa(v)]; ok {
-			return severity, nil
+// severityRange maps the integers from min to max, inclusive, to a severity
+type severityRange struct {
+	min      int64
+	max      int64
+	severity entry.Severity
+}
+
+// severityPattern maps the values that match a regex to a severity
+type severityPattern struct {
+	regexp   *regexp.Regexp
+	severity entry.Severity
+}
+
+// find will return the severity mapped to a value, along with the original
+// text of the value. Exact values are matched first, then ranges, then regexes.
+func (p *SeverityParser) find(value interface{}) (entry.Severity, string, error) {
+	severity, text, err := p.Mapping.find(value)
+	if err != nil || severity != entry.Nil {
+		return severity, text, err
+	}
+
+	if len(p.ranges) > 0 {
+		if i, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64); err == nil {
+			for _, r := range p.ranges {
+				if i >= r.min && i <= r.max {
+					return r.severity, text, nil
+				}
+			}
 		}
-		return entry.Nil, nil
-	case string:
-		if severity, ok := m[strings.ToLower(v)]; ok {
-			return severity, nil
+	}
+
+	for _, pattern := range p.patterns {
+		if pattern.regexp.MatchString(text) {
+			return pattern.severity, text, nil
 		}
-		return entry.Nil, nil
+	}
+
+	return entry.Nil, text, nil
+}
+
+// find will return the severity mapped to a value, along with the
+// original text of the value
+func (m severityMap) find(value interface{}) (entry.Severity, string, error) {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
 	case []byte:
-		if severity, ok := m[strings.ToLower(string(v))]; ok {
-			return severity, nil
-		}
-		return entry.Nil, nil
+		text = string(v)
 	default:
-		return entry.Nil, fmt.Errorf("type %T cannot be a severity", v)
+		// Numbers are matched as text, and floats are only matched when they are whole numbers
+		i, ok := wholeNumber(v)
+		if !ok {
+			return entry.Nil, "", fmt.Errorf("type %T cannot be a severity", v)
+		}
+		text = strconv.FormatInt(i, 10)
+	}
+
+	if severity, ok := m[strings.ToLower(text)]; ok {
+		return severity, text, nil
 	}
+	return entry.Nil, text, nil
 }
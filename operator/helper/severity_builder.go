@@ -2,10 +2,14 @@ package helper
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
 	"github.com/observiq/stanza/operator"
 )
 
@@ -40,6 +44,71 @@ func getBuiltinMapping(name string) severityMap {
 	}
 }
 
+// builtinPresets are the presets for logging systems, which extend the default preset
+var builtinPresets = map[string]map[interface{}]interface{}{
+	// The syslog severities of RFC5424, by number and keyword
+	"syslog": {
+		"emergency": []interface{}{0, "emerg", "panic"},
+		"alert":     1,
+		"critical":  2,
+		"error":     3,
+		"warning":   4,
+		"notice":    5,
+		"info":      []interface{}{6, "informational"},
+		"debug":     7,
+	},
+	// The PRIORITY field of journald, which uses the syslog severity numbers
+	"journald": {
+		"emergency": 0,
+		"alert":     1,
+		"critical":  2,
+		"error":     3,
+		"warning":   4,
+		"notice":    5,
+		"info":      6,
+		"debug":     7,
+	},
+	// Python logging levels, including custom levels between the standard levels
+	"python": {
+		"debug":    map[interface{}]interface{}{"min": 10, "max": 19},
+		"info":     map[interface{}]interface{}{"min": 20, "max": 29},
+		"warning":  map[interface{}]interface{}{"min": 30, "max": 39},
+		"error":    map[interface{}]interface{}{"min": 40, "max": 49},
+		"critical": []interface{}{map[interface{}]interface{}{"min": 50, "max": 59}, "fatal"},
+	},
+	// log4j levels, and the log4j2 integer levels where lower values are more severe
+	"log4j": {
+		"critical": []interface{}{map[interface{}]interface{}{"min": 1, "max": 100}, "fatal"},
+		"error":    map[interface{}]interface{}{"min": 101, "max": 200},
+		"warning":  map[interface{}]interface{}{"min": 201, "max": 300},
+		"info":     map[interface{}]interface{}{"min": 301, "max": 400},
+		"debug":    map[interface{}]interface{}{"min": 401, "max": 500},
+		"trace":    map[interface{}]interface{}{"min": 501, "max": 600},
+	},
+	// Bunyan and pino levels, including custom levels between the standard levels
+	"bunyan": bunyanPreset,
+	"pino":   bunyanPreset,
+}
+
+var bunyanPreset = map[interface{}]interface{}{
+	"trace":    map[interface{}]interface{}{"min": 10, "max": 19},
+	"debug":    map[interface{}]interface{}{"min": 20, "max": 29},
+	"info":     map[interface{}]interface{}{"min": 30, "max": 39},
+	"warning":  map[interface{}]interface{}{"min": 40, "max": 49},
+	"error":    map[interface{}]interface{}{"min": 50, "max": 59},
+	"critical": []interface{}{map[interface{}]interface{}{"min": 60, "max": 69}, "fatal"},
+}
+
+// isValidPreset returns true if a preset is a built in preset
+func isValidPreset(name string) bool {
+	switch name {
+	case "", "default", "none", "aliases":
+		return true
+	}
+	_, ok := builtinPresets[name]
+	return ok
+}
+
 func (s severityMap) add(severity entry.Severity, parseableValues ...string) {
 	for _, str := range parseableValues {
 		s[str] = severity
@@ -75,47 +144,149 @@ type SeverityParserConfig struct {
 
 // Build builds a SeverityParser from a SeverityParserConfig
 func (c *SeverityParserConfig) Build(context operator.BuildContext) (SeverityParser, error) {
+	if !isValidPreset(c.Preset) {
+		return SeverityParser{}, fmt.Errorf("invalid severity preset '%s'", c.Preset)
+	}
+
 	operatorMapping := getBuiltinMapping(c.Preset)
 
-	for severity, unknown := range c.Mapping {
+	// Presets for logging systems are defined with the same syntax as the mapping
+	presetMatchers, err := addMapping(operatorMapping, builtinPresets[c.Preset])
+	if err != nil {
+		return SeverityParser{}, errors.Wrap(err, "build preset")
+	}
+
+	// The ranges and regexes of the mapping are tried before those of the preset
+	matchers, err := addMapping(operatorMapping, c.Mapping)
+	if err != nil {
+		return SeverityParser{}, err
+	}
+
+	if c.ParseFrom == nil {
+		return SeverityParser{}, fmt.Errorf("missing required field 'parse_from'")
+	}
+
+	p := SeverityParser{
+		ParseFrom: *c.ParseFrom,
+		Preserve:  c.Preserve,
+		Mapping:   operatorMapping,
+		ranges:    append(matchers.ranges, presetMatchers.ranges...),
+		patterns:  append(matchers.patterns, presetMatchers.patterns...),
+	}
+
+	return p, nil
+}
+
+// severityMatchers are the ranges and regexes of a severity mapping
+type severityMatchers struct {
+	ranges   []severityRange
+	patterns []severityPattern
+}
+
+// addMapping will add the values of a mapping to a severity map, and
+// return the ranges and regexes of the mapping
+func addMapping(operatorMapping severityMap, mapping map[interface{}]interface{}) (severityMatchers, error) {
+	matchers := severityMatchers{}
+	for severity, unknown := range mapping {
 		sev, err := validateSeverity(severity)
 		if err != nil {
-			return SeverityParser{}, err
+			return matchers, err
 		}
 
 		switch u := unknown.(type) {
 		case []interface{}: // check before interface{}
 			for _, value := range u {
-				v, err := parseableValues(value)
-				if err != nil {
-					return SeverityParser{}, err
+				if err := matchers.addValue(operatorMapping, sev, value); err != nil {
+					return matchers, err
 				}
-				operatorMapping.add(sev, v...)
 			}
 		case interface{}:
-			v, err := parseableValues(u)
-			if err != nil {
-				return SeverityParser{}, err
+			if err := matchers.addValue(operatorMapping, sev, u); err != nil {
+				return matchers, err
 			}
-			operatorMapping.add(sev, v...)
 		}
 	}
 
-	if c.ParseFrom == nil {
-		return SeverityParser{}, fmt.Errorf("missing required field 'parse_from'")
+	// The mapping has no order, so the ranges and regexes are sorted to match consistently
+	sort.Slice(matchers.ranges, func(i, j int) bool {
+		return matchers.ranges[i].min < matchers.ranges[j].min
+	})
+	for i := 1; i < len(matchers.ranges); i++ {
+		previous, current := matchers.ranges[i-1], matchers.ranges[i]
+		if current.min <= previous.max && current.severity != previous.severity {
+			return matchers, fmt.Errorf(
+				"severity range %d to %d overlaps with severity range %d to %d",
+				current.min, current.max, previous.min, previous.max,
+			)
+		}
 	}
 
-	p := SeverityParser{
-		ParseFrom: *c.ParseFrom,
-		Preserve:  c.Preserve,
-		Mapping:   operatorMapping,
+	// When a value matches more than one regex, the highest severity is used
+	sort.Slice(matchers.patterns, func(i, j int) bool {
+		if matchers.patterns[i].severity != matchers.patterns[j].severity {
+			return matchers.patterns[i].severity > matchers.patterns[j].severity
+		}
+		return matchers.patterns[i].regexp.String() < matchers.patterns[j].regexp.String()
+	})
+
+	return matchers, nil
+}
+
+// addValue will add a value of a mapping, which is either a
+// value, a range or a regex, to the matchers or severity map
+func (m *severityMatchers) addValue(operatorMapping severityMap, severity entry.Severity, value interface{}) error {
+	object, ok := mappingObject(value)
+	if !ok {
+		v, err := parseableValues(value)
+		if err != nil {
+			return err
+		}
+		operatorMapping.add(severity, v...)
+		return nil
 	}
 
-	return p, nil
+	if pattern, ok := object["regex"]; ok {
+		str, ok := pattern.(string)
+		if !ok {
+			return fmt.Errorf("severity regex must be a string, but found %v of type %T", pattern, pattern)
+		}
+		compiled, err := regexp.Compile(str)
+		if err != nil {
+			return errors.Wrap(err, "compile severity regex")
+		}
+		m.patterns = append(m.patterns, severityPattern{regexp: compiled, severity: severity})
+		return nil
+	}
+
+	min, max, ok := isRange(object)
+	if !ok {
+		return fmt.Errorf("severity mapping %v must be a range with integer 'min' and 'max' values, or a 'regex'", value)
+	}
+	if min > max {
+		min, max = max, min
+	}
+	m.ranges = append(m.ranges, severityRange{min: min, max: max, severity: severity})
+	return nil
+}
+
+// mappingObject returns the keys of a value that is a map
+func mappingObject(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			object[fmt.Sprintf("%v", key)] = val
+		}
+		return object, true
+	default:
+		return nil, false
+	}
 }
 
 func validateSeverity(severity interface{}) (entry.Severity, error) {
-	if sev, err := getBuiltinMapping("aliases").find(severity); err != nil {
+	if sev, _, err := getBuiltinMapping("aliases").find(severity); err != nil {
 		return entry.Nil, err
 	} else if sev != entry.Nil {
 		return sev, nil
@@ -142,20 +313,15 @@ func validateSeverity(severity interface{}) (entry.Severity, error) {
 	return entry.Severity(intSev), nil
 }
 
-func isRange(value interface{}) (int, int, bool) {
-	rawMap, ok := value.(map[interface{}]interface{})
-	if !ok {
-		return 0, 0, false
-	}
-
-	min, minOK := rawMap["min"]
-	max, maxOK := rawMap["max"]
-	if !minOK || !maxOK {
+func isRange(object map[string]interface{}) (int64, int64, bool) {
+	min, minOK := object["min"]
+	max, maxOK := object["max"]
+	if !minOK || !maxOK || len(object) != 2 {
 		return 0, 0, false
 	}
 
-	minInt, minOK := min.(int)
-	maxInt, maxOK := max.(int)
+	minInt, minOK := wholeNumber(min)
+	maxInt, maxOK := wholeNumber(max)
 	if !minOK || !maxOK {
 		return 0, 0, false
 	}
@@ -163,6 +329,29 @@ func isRange(value interface{}) (int, int, bool) {
 	return minInt, maxInt, true
 }
 
+// wholeNumber returns the value of an integer, or of a float without a fractional part
+func wholeNumber(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
 func expandRange(min, max int) []string {
 	if min > max {
 		min, max = max, min
@@ -177,8 +366,6 @@ func expandRange(min, max int) []string {
 
 func parseableValues(value interface{}) ([]string, error) {
 	switch v := value.(type) {
-	case int:
-		return []string{strconv.Itoa(v)}, nil // store as string because we will compare as string
 	case string:
 		switch v {
 		case HTTP2xx:
@@ -195,9 +382,8 @@ func parseableValues(value interface{}) ([]string, error) {
 	case []byte:
 		return []string{strings.ToLower(string(v))}, nil
 	default:
-		min, max, ok := isRange(v)
-		if ok {
-			return expandRange(min, max), nil
+		if i, ok := wholeNumber(v); ok {
+			return []string{strconv.FormatInt(i, 10)}, nil // store as string because we will compare as string
 		}
 		return nil, fmt.Errorf("type %T cannot be parsed as a severity", v)
 	}
@@ -277,6 +277,190 @@ func TestSeverityParser(t *testing.T) {
 			mapping:    nil,
 			expected:   entry.Default, // not error
 		},
+		{
+			name:     "in-range-float",
+			sample:   123.0,
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 120, "max": 125}},
+			expected: entry.Error,
+		},
+		{
+			name:     "in-range-string",
+			sample:   "123",
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 120, "max": 125}},
+			expected: entry.Error,
+		},
+		{
+			name:     "in-range-json",
+			sample:   123,
+			mapping:  map[interface{}]interface{}{"error": map[string]interface{}{"min": 120.0, "max": 125.0}},
+			expected: entry.Error,
+		},
+		{
+			name:     "in-range-large",
+			sample:   int64(5000000000),
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 0, "max": 9000000000}},
+			expected: entry.Error,
+		},
+		{
+			name:   "in-second-range",
+			sample: 15,
+			mapping: map[interface{}]interface{}{
+				"info":  map[interface{}]interface{}{"min": 0, "max": 9},
+				"error": map[interface{}]interface{}{"min": 10, "max": 19},
+			},
+			expected: entry.Error,
+		},
+		{
+			name:   "range-overlap",
+			sample: 15,
+			mapping: map[interface{}]interface{}{
+				"info":  map[interface{}]interface{}{"min": 0, "max": 10},
+				"error": map[interface{}]interface{}{"min": 10, "max": 19},
+			},
+			buildErr: true,
+		},
+		{
+			name:     "range-missing-max",
+			sample:   15,
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 10}},
+			buildErr: true,
+		},
+		{
+			name:     "range-float-bound",
+			sample:   15,
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 10.5, "max": 19}},
+			buildErr: true,
+		},
+		{
+			name:     "exact-before-range",
+			sample:   15,
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 10, "max": 19}, "info": 15},
+			expected: entry.Info,
+		},
+		{
+			name:     "regex-hit",
+			sample:   "E1234",
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"regex": `^E\d+`}},
+			expected: entry.Error,
+		},
+		{
+			name:     "regex-miss",
+			sample:   "e1234",
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"regex": `^E\d+`}},
+			expected: entry.Default,
+		},
+		{
+			name:     "regex-int",
+			sample:   1234,
+			mapping:  map[interface{}]interface{}{"error": []interface{}{"oops", map[string]interface{}{"regex": `^12`}}},
+			expected: entry.Error,
+		},
+		{
+			name:   "regex-highest-severity",
+			sample: "WARN: disk failure",
+			mapping: map[interface{}]interface{}{
+				"warning":  map[interface{}]interface{}{"regex": `^WARN`},
+				"critical": map[interface{}]interface{}{"regex": `failure`},
+			},
+			expected: entry.Critical,
+		},
+		{
+			name:     "regex-invalid",
+			sample:   "E1234",
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"regex": `^E\d+(`}},
+			buildErr: true,
+		},
+		{
+			name:     "regex-not-string",
+			sample:   "E1234",
+			mapping:  map[interface{}]interface{}{"error": map[interface{}]interface{}{"regex": 12}},
+			buildErr: true,
+		},
+		{
+			name:       "preset-invalid",
+			sample:     "error",
+			mappingSet: "unknown",
+			buildErr:   true,
+		},
+		{
+			name:       "preset-syslog-number",
+			sample:     3,
+			mappingSet: "syslog",
+			expected:   entry.Error,
+		},
+		{
+			name:       "preset-syslog-keyword",
+			sample:     "EMERG",
+			mappingSet: "syslog",
+			expected:   entry.Emergency,
+		},
+		{
+			name:       "preset-syslog-default-alias",
+			sample:     "warn",
+			mappingSet: "syslog",
+			expected:   entry.Warning,
+		},
+		{
+			name:       "preset-journald",
+			sample:     "6",
+			mappingSet: "journald",
+			expected:   entry.Info,
+		},
+		{
+			name:       "preset-python",
+			sample:     30.0,
+			mappingSet: "python",
+			expected:   entry.Warning,
+		},
+		{
+			name:       "preset-python-custom-level",
+			sample:     25,
+			mappingSet: "python",
+			expected:   entry.Info,
+		},
+		{
+			name:       "preset-python-fatal",
+			sample:     "FATAL",
+			mappingSet: "python",
+			expected:   entry.Critical,
+		},
+		{
+			name:       "preset-log4j-name",
+			sample:     "TRACE",
+			mappingSet: "log4j",
+			expected:   entry.Trace,
+		},
+		{
+			name:       "preset-log4j-int-level",
+			sample:     200,
+			mappingSet: "log4j",
+			expected:   entry.Error,
+		},
+		{
+			name:       "preset-log4j-custom-int-level",
+			sample:     350,
+			mappingSet: "log4j",
+			expected:   entry.Info,
+		},
+		{
+			name:       "preset-bunyan",
+			sample:     50.0,
+			mappingSet: "bunyan",
+			expected:   entry.Error,
+		},
+		{
+			name:       "preset-pino",
+			sample:     60,
+			mappingSet: "pino",
+			expected:   entry.Critical,
+		},
+		{
+			name:       "preset-range-overridden-by-mapping",
+			sample:     35,
+			mappingSet: "python",
+			mapping:    map[interface{}]interface{}{"error": map[interface{}]interface{}{"min": 35, "max": 35}},
+			expected:   entry.Error,
+		},
 	}
 
 	rootField := entry.NewRecordField()
@@ -321,3 +505,38 @@ func (tc severityTestCase) run(t *testing.T, parseFrom entry.Field) func(*testin
 
 	}
 }
+
+func TestSeverityParserText(t *testing.T) {
+	cases := []struct {
+		name         string
+		sample       interface{}
+		expected     entry.Severity
+		expectedText string
+	}{
+		{"String", "WARN", entry.Warning, "WARN"},
+		{"Bytes", []byte("Error"), entry.Error, "Error"},
+		{"Int", 404, entry.Default, "404"},
+		{"Unknown", "blah", entry.Default, "blah"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parseFrom := entry.NewRecordField("severity")
+			cfg := &SeverityParserConfig{
+				ParseFrom: &parseFrom,
+				Preserve:  true,
+			}
+
+			severityParser, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+
+			ent := entry.New()
+			ent.Set(parseFrom, tc.sample)
+			err = severityParser.Parse(context.Background(), ent)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expected, ent.Severity)
+			require.Equal(t, tc.expectedText, ent.SeverityText)
+		})
+	}
+}
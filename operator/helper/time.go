@@ -26,6 +26,9 @@ const EpochKey = "epoch"
 // NativeKey is literally "native" and refers to Golang's native time.Time
 const NativeKey = "native" // provided for operator development
 
+// AutoKey is literally "auto" and detects the layout from common timestamp formats
+const AutoKey = "auto"
+
 // NewTimeParser creates a new time parser with default values
 func NewTimeParser() TimeParser {
 	return TimeParser{
@@ -35,15 +38,20 @@ func NewTimeParser() TimeParser {
 
 // TimeParser is a helper that parses time onto an entry.
 type TimeParser struct {
-	ParseFrom  *entry.Field `json:"parse_from,omitempty"  yaml:"parse_from,omitempty"`
-	Layout     string       `json:"layout,omitempty"      yaml:"layout,omitempty"`
-	LayoutType string       `json:"layout_type,omitempty" yaml:"layout_type,omitempty"`
-	Preserve   bool         `json:"preserve"              yaml:"preserve"`
+	ParseFrom       *entry.Field `json:"parse_from,omitempty"       yaml:"parse_from,omitempty"`
+	Layout          string       `json:"layout,omitempty"           yaml:"layout,omitempty"`
+	FallbackLayouts []string     `json:"fallback_layouts,omitempty" yaml:"fallback_layouts,omitempty"`
+	LayoutType      string       `json:"layout_type,omitempty"      yaml:"layout_type,omitempty"`
+	Location        string       `json:"location,omitempty"         yaml:"location,omitempty"`
+	Preserve        bool         `json:"preserve"                   yaml:"preserve"`
+
+	location *time.Location
+	auto     *autoDetector
 }
 
 // IsZero returns true if the TimeParser is not a valid config
 func (t *TimeParser) IsZero() bool {
-	return t.Layout == ""
+	return t.Layout == "" && t.LayoutType != AutoKey
 }
 
 // Validate validates a TimeParser, and reconfigures it if necessary
@@ -52,7 +60,7 @@ func (t *TimeParser) Validate(context operator.BuildContext) error {
 		return fmt.Errorf("missing required parameter 'parse_from'")
 	}
 
-	if t.Layout == "" && t.LayoutType != "native" {
+	if t.Layout == "" && t.LayoutType != NativeKey && t.LayoutType != AutoKey {
 		return errors.NewError("missing required configuration parameter `layout`", "")
 	}
 
@@ -61,33 +69,117 @@ func (t *TimeParser) Validate(context operator.BuildContext) error {
 	}
 
 	switch t.LayoutType {
-	case NativeKey, GotimeKey: // ok
-	case StrptimeKey:
-		var err error
-		t.Layout, err = strptime.ToNative(t.Layout)
-		if err != nil {
-			return errors.Wrap(err, "parse strptime layout")
+	case NativeKey:
+		if len(t.FallbackLayouts) > 0 {
+			return errors.NewError("`fallback_layouts` cannot be used with the `native` layout_type", "")
+		}
+	case GotimeKey, StrptimeKey:
+		if err := t.validateLayouts(); err != nil {
+			return err
 		}
-		t.LayoutType = GotimeKey
+	case AutoKey:
+		if t.Layout != "" || len(t.FallbackLayouts) > 0 {
+			return errors.NewError(
+				"`layout` and `fallback_layouts` cannot be used with the `auto` layout_type",
+				"remove the layouts to detect the layout automatically, or specify the `layout_type` of the layouts",
+			)
+		}
+		t.auto = newAutoDetector(context.Logger)
 	case EpochKey:
-		switch t.Layout {
-		case "s", "ms", "us", "ns", "s.ms", "s.us", "s.ns": // ok
-		default:
+		if err := validateEpochLayout(t.Layout); err != nil {
+			return err
+		}
+		// Every integer is a valid value for every epoch layout, so the first one would always match
+		if len(t.FallbackLayouts) > 0 {
 			return errors.NewError(
-				"invalid `layout` for `epoch` type",
-				"specify 's', 'ms', 'us', 'ns', 's.ms', 's.us', or 's.ns'",
+				"`fallback_layouts` cannot be used with the `epoch` layout_type",
+				"epoch values are ambiguous between layouts, so specify the single `layout` of the values",
 			)
 		}
 	default:
 		return errors.NewError(
 			fmt.Sprintf("unsupported layout_type %s", t.LayoutType),
-			"valid values are 'strptime', 'gotime', and 'epoch'",
+			"valid values are 'strptime', 'gotime', 'epoch', and 'auto'",
 		)
 	}
 
+	if t.Location != "" {
+		if t.LayoutType == NativeKey || t.LayoutType == EpochKey {
+			return errors.NewError(
+				fmt.Sprintf("`location` cannot be used with the `%s` layout_type", t.LayoutType),
+				"remove the `location`, since these timestamps already refer to an exact point in time",
+			)
+		}
+
+		location, err := time.LoadLocation(t.Location)
+		if err != nil {
+			return errors.NewError(
+				fmt.Sprintf("invalid location '%s'", t.Location),
+				"specify a location from the IANA Time Zone database, such as 'America/Chicago' or 'UTC'",
+			)
+		}
+		t.location = location
+	}
+
+	return nil
+}
+
+// validateLayouts will validate the layout and fallback layouts of a strptime or
+// gotime parser, and convert strptime layouts to gotime
+func (t *TimeParser) validateLayouts() error {
+	layouts := append([]string{t.Layout}, t.FallbackLayouts...)
+	seen := make(map[string]bool, len(layouts))
+
+	for i, layout := range layouts {
+		if t.LayoutType == StrptimeKey {
+			var err error
+			layout, err = strptime.ToNative(layout)
+			if err != nil {
+				return errors.Wrap(err, "parse strptime layout")
+			}
+		}
+
+		// A layout without any elements only matches its own text
+		if sampleTime.Format(layout) == layout {
+			return errors.NewError(
+				fmt.Sprintf("layout '%s' does not contain any time elements", layouts[i]),
+				"ensure that the layout matches the `layout_type`",
+			)
+		}
+
+		if seen[layout] {
+			return errors.NewError(
+				fmt.Sprintf("layout '%s' is specified more than once", layouts[i]),
+				"remove the duplicate from `layout` and `fallback_layouts`",
+			)
+		}
+		seen[layout] = true
+		layouts[i] = layout
+	}
+
+	t.Layout = layouts[0]
+	if len(t.FallbackLayouts) > 0 {
+		t.FallbackLayouts = layouts[1:]
+	}
+	t.LayoutType = GotimeKey
 	return nil
 }
 
+// sampleTime is used to check that layouts contain time elements
+var sampleTime = time.Date(2017, 11, 23, 19, 34, 56, 0, time.UTC)
+
+func validateEpochLayout(layout string) error {
+	switch layout {
+	case "s", "ms", "us", "ns", "s.ms", "s.us", "s.ns": // ok
+		return nil
+	default:
+		return errors.NewError(
+			"invalid `layout` for `epoch` type",
+			"specify 's', 'ms', 'us', 'ns', 's.ms', 's.us', or 's.ns'",
+		)
+	}
+}
+
 // Parse will parse time from a field and attach it to the entry
 func (t *TimeParser) Parse(ctx context.Context, entry *entry.Entry) error {
 	value, ok := entry.Get(t.ParseFrom)
@@ -99,45 +191,83 @@ func (t *TimeParser) Parse(ctx context.Context, entry *entry.Entry) error {
 		)
 	}
 
+	timeValue, err := t.ParseValue(value)
+	if err != nil {
+		return err
+	}
+	entry.Timestamp = timeValue
+
+	if !t.Preserve {
+		entry.Delete(t.ParseFrom)
+	}
+
+	return nil
+}
+
+// ParseValue will parse a value as a time with the layout of the time parser
+func (t *TimeParser) ParseValue(value interface{}) (time.Time, error) {
 	switch t.LayoutType {
 	case NativeKey:
 		timeValue, ok := value.(time.Time)
 		if !ok {
-			return fmt.Errorf("native time.Time field required, but found %v of type %T", value, value)
+			return time.Time{}, fmt.Errorf("native time.Time field required, but found %v of type %T", value, value)
 		}
-		entry.Timestamp = setTimestampYear(timeValue)
+		return setTimestampYear(timeValue), nil
 	case GotimeKey:
 		timeValue, err := t.parseGotime(value)
 		if err != nil {
-			return err
+			return time.Time{}, err
 		}
-		entry.Timestamp = setTimestampYear(timeValue)
+		return setTimestampYear(timeValue), nil
 	case EpochKey:
-		timeValue, err := t.parseEpochTime(value)
+		return t.parseEpochTime(value)
+	case AutoKey:
+		detector := t.auto
+		if detector == nil {
+			detector = newAutoDetector(nil)
+		}
+		timeValue, err := detector.parse(value, t.getLocation())
 		if err != nil {
-			return err
+			return time.Time{}, err
 		}
-		entry.Timestamp = setTimestampYear(timeValue)
+		return setTimestampYear(timeValue), nil
 	default:
-		return fmt.Errorf("unsupported layout type: %s", t.LayoutType)
+		return time.Time{}, fmt.Errorf("unsupported layout type: %s", t.LayoutType)
 	}
-
-	if !t.Preserve {
-		entry.Delete(t.ParseFrom)
-	}
-
-	return nil
 }
 
 func (t *TimeParser) parseGotime(value interface{}) (time.Time, error) {
+	var str string
 	switch v := value.(type) {
 	case string:
-		return time.ParseInLocation(t.Layout, v, time.Local)
+		str = v
 	case []byte:
-		return time.ParseInLocation(t.Layout, string(v), time.Local)
+		str = string(v)
 	default:
 		return time.Time{}, fmt.Errorf("type %T cannot be parsed as a time", value)
 	}
+
+	location := t.getLocation()
+	timeValue, err := time.ParseInLocation(t.Layout, str, location)
+	if err == nil {
+		return timeValue, nil
+	}
+
+	// The fallback layouts are tried in order, and the error of the layout is returned if none match
+	for _, layout := range t.FallbackLayouts {
+		if timeValue, fallbackErr := time.ParseInLocation(layout, str, location); fallbackErr == nil {
+			return timeValue, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// getLocation returns the location of timestamps that do not include a time zone
+func (t *TimeParser) getLocation() *time.Location {
+	if t.location == nil {
+		return time.Local
+	}
+	return t.location
 }
 
 func (t *TimeParser) parseEpochTime(value interface{}) (time.Time, error) {
@@ -213,19 +343,26 @@ var toTime = map[string]toTimeFunc{
 var subsecToNs = map[string]int64{"s.ms": 1e6, "s.us": 1e3, "s.ns": 1}
 
 // setTimestampYear sets the year of a timestamp to the current year.
-// This is needed because year is missing from some time formats, such as rfc3164.
+// This is needed because year is missing from some time formats, such as rfc3164,
+// and timestamps parsed without a year have a year of 0.
 func setTimestampYear(t time.Time) time.Time {
-	if t.Year() > 1970 {
+	if t.Year() != 0 {
 		return t
 	}
 	n := now()
-	d := time.Date(n.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
-	// If the timestamp would be more than 7 days in the future using this year,
-	// assume it's from last year.
-	if d.After(n.AddDate(0, 0, 7)) {
-		d = d.AddDate(-1, 0, 0)
+	limit := n.AddDate(0, 0, 7)
+
+	// The timestamp is given the most recent year that does not put it more than 7 days
+	// in the future. Starting from next year in the location of the timestamp handles
+	// locations where the new year has already started, or has not started yet.
+	// Years that do not have the day of the timestamp, such as February 29 in a year
+	// that is not a leap year, are skipped rather than moving the timestamp to another day.
+	for year := n.In(t.Location()).Year() + 1; ; year-- {
+		d := time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		if d.Month() == t.Month() && d.Day() == t.Day() && !d.After(limit) {
+			return d
+		}
 	}
-	return d
 }
 
 // Allows tests to override with deterministic value
@@ -0,0 +1,155 @@
+package helper
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// autoLayout is a timestamp format that is recognized by the auto layout type
+type autoLayout struct {
+	name   string
+	layout string
+}
+
+// autoLayouts are the formats recognized by the auto layout type, in the order they are tried.
+// Fractional seconds are accepted after the seconds of every layout, and a comma before them is
+// replaced with a period before parsing.
+var autoLayouts = []autoLayout{
+	{name: "RFC3339", layout: "2006-01-02T15:04:05Z07:00"},
+	{name: "ISO8601", layout: "2006-01-02T15:04:05Z0700"},
+	{name: "ISO8601 without zone", layout: "2006-01-02T15:04:05"},
+	{name: "ISO8601 basic", layout: "20060102T150405Z0700"},
+	{name: "ISO8601 basic without zone", layout: "20060102T150405"},
+	{name: "ISO8601 with space", layout: "2006-01-02 15:04:05Z07:00"},
+	{name: "ISO8601 with space and offset", layout: "2006-01-02 15:04:05 -0700"},
+	{name: "ISO8601 with space and zone", layout: "2006-01-02 15:04:05 MST"},
+	{name: "ISO8601 with space without zone", layout: "2006-01-02 15:04:05"},
+	{name: "RFC1123", layout: time.RFC1123},
+	{name: "RFC1123 with offset", layout: time.RFC1123Z},
+	{name: "Apache CLF", layout: "02/Jan/2006:15:04:05 -0700"},
+	{name: "Java date", layout: "Mon Jan _2 15:04:05 MST 2006"},
+	{name: "log4j DATE", layout: "02 Jan 2006 15:04:05"},
+	{name: "syslog", layout: time.Stamp},
+}
+
+// commaFraction matches fractional seconds that are separated from the seconds by a comma
+var commaFraction = regexp.MustCompile(`(:\d{2}|T\d{6}),(\d+)`)
+
+// normalizeFraction will replace a comma before fractional seconds with a period, as time.Parse
+// only accepts fractional seconds that are not in the layout after a period before Go 1.17
+func normalizeFraction(value string) string {
+	if !strings.Contains(value, ",") {
+		return value
+	}
+	return commaFraction.ReplaceAllString(value, "$1.$2")
+}
+
+// epochValue matches strings that are parsed as epoch timestamps by the auto layout type.
+// Shorter numbers, such as the date 20201016, are not epoch timestamps of the current era.
+var epochValue = regexp.MustCompile(`^\d{10,19}(\.\d+)?$`)
+
+const (
+	minEpochDigits = 10
+	maxEpochDigits = 19
+)
+
+// autoDetector detects the layout of timestamps for the auto layout type. The last
+// detected layout is tried first, so that entries with the same format stay fast.
+type autoDetector struct {
+	last   atomic.Value // *autoLayout
+	logger *zap.SugaredLogger
+}
+
+func newAutoDetector(logger *zap.SugaredLogger) *autoDetector {
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+	return &autoDetector{logger: logger}
+}
+
+// parse will parse a value with the layout that it matches
+func (a *autoDetector) parse(value interface{}, location *time.Location) (time.Time, error) {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	case int, int32, int64, uint32, uint64:
+		return a.parseEpoch(fmt.Sprintf("%d", v))
+	case float64:
+		return a.parseEpoch(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return time.Time{}, fmt.Errorf("type %T cannot be parsed as a time", value)
+	}
+
+	str = strings.TrimSpace(str)
+	if epochValue.MatchString(str) {
+		return a.parseEpoch(str)
+	}
+
+	normalized := normalizeFraction(str)
+	last, _ := a.last.Load().(*autoLayout)
+	if last != nil {
+		if timeValue, err := time.ParseInLocation(last.layout, normalized, location); err == nil {
+			return timeValue, nil
+		}
+	}
+
+	for i := range autoLayouts {
+		candidate := &autoLayouts[i]
+		if candidate == last {
+			continue
+		}
+		timeValue, err := time.ParseInLocation(candidate.layout, normalized, location)
+		if err != nil {
+			continue
+		}
+		a.last.Store(candidate)
+		a.logger.Debugw("Detected timestamp layout", "name", candidate.name, "layout", candidate.layout)
+		return timeValue, nil
+	}
+
+	return time.Time{}, fmt.Errorf("value '%s' does not match any of the timestamp formats of the auto layout type", str)
+}
+
+// parseEpoch will parse a number as seconds, milliseconds, microseconds or nanoseconds
+// since the epoch, depending on the number of digits before the decimal point
+func (a *autoDetector) parseEpoch(stamp string) (time.Time, error) {
+	parts := strings.SplitN(stamp, ".", 2)
+	integer, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid epoch value '%s'", stamp)
+	}
+
+	digits := len(strings.TrimLeft(parts[0], "0"))
+	if digits < minEpochDigits || digits > maxEpochDigits {
+		return time.Time{}, fmt.Errorf("epoch value '%s' must have %d to %d digits before the decimal point", stamp, minEpochDigits, maxEpochDigits)
+	}
+
+	switch {
+	case digits <= 11:
+		var nanos int64
+		if len(parts) == 2 {
+			// The fraction is padded or truncated to nanoseconds
+			fraction := (parts[1] + "000000000")[:9]
+			nanos, err = strconv.ParseInt(fraction, 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid epoch value '%s'", stamp)
+			}
+		}
+		return time.Unix(integer, nanos), nil
+	case digits <= 14:
+		return toTime["ms"](integer), nil
+	case digits <= 17:
+		return toTime["us"](integer), nil
+	default:
+		return toTime["ns"](integer), nil
+	}
+}
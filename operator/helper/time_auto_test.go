@@ -0,0 +1,253 @@
+package helper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTimeAuto(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2020, 06, 16, 3, 31, 34, 525, time.UTC)
+	}
+
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		sample   interface{}
+		expected time.Time
+	}{
+		{
+			name:     "rfc3339",
+			sample:   "2020-06-09T15:39:58Z",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC),
+		},
+		{
+			name:     "rfc3339-fractional",
+			sample:   "2020-06-09T15:39:58.123456-04:00",
+			expected: time.Date(2020, 6, 9, 19, 39, 58, 123456000, time.UTC),
+		},
+		{
+			name:     "iso8601-offset-without-colon",
+			sample:   "2019-11-27T09:34:32.901-0500",
+			expected: time.Date(2019, 11, 27, 14, 34, 32, 901000000, time.UTC),
+		},
+		{
+			name:     "iso8601-without-zone",
+			sample:   "2020-06-09T15:39:58",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, chicago),
+		},
+		{
+			name:     "iso8601-basic",
+			sample:   "20200609T153958Z",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC),
+		},
+		{
+			name:     "iso8601-basic-offset",
+			sample:   "20200609T153958,118-0500",
+			expected: time.Date(2020, 6, 9, 20, 39, 58, 118000000, time.UTC),
+		},
+		{
+			name:     "iso8601-basic-without-zone",
+			sample:   "20200609T153958.118",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 118000000, chicago),
+		},
+		{
+			name:     "iso8601-space-offset",
+			sample:   "2020-06-09 15:39:58.118 +0000",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 118000000, time.UTC),
+		},
+		{
+			name:     "log4j-iso8601",
+			sample:   "2020-06-09 15:39:58,118",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 118000000, chicago),
+		},
+		{
+			name:     "log4j-date",
+			sample:   "09 Jun 2020 15:39:58,118",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 118000000, chicago),
+		},
+		{
+			name:     "rfc1123",
+			sample:   "Tue, 09 Jun 2020 15:39:58 UTC",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC),
+		},
+		{
+			name:     "rfc1123-offset",
+			sample:   "Tue, 09 Jun 2020 15:39:58 +0200",
+			expected: time.Date(2020, 6, 9, 13, 39, 58, 0, time.UTC),
+		},
+		{
+			name:     "apache-clf",
+			sample:   "09/Jun/2020:15:39:58 +0000",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC),
+		},
+		{
+			name:     "java-date",
+			sample:   "Tue Jun 09 15:39:58 UTC 2020",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC),
+		},
+		{
+			name:     "syslog",
+			sample:   "Jun  9 15:39:58",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, chicago),
+		},
+		{
+			name:     "syslog-bytes",
+			sample:   []byte("Jun 09 15:39:58"),
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, chicago),
+		},
+		{
+			name:     "epoch-s-string",
+			sample:   "1136214245",
+			expected: time.Unix(1136214245, 0),
+		},
+		{
+			name:     "epoch-s-fractional-string",
+			sample:   "1136214245.123",
+			expected: time.Unix(1136214245, 123000000),
+		},
+		{
+			name:     "epoch-ms-string",
+			sample:   "1136214245123",
+			expected: time.Unix(1136214245, 123000000),
+		},
+		{
+			name:     "epoch-us-string",
+			sample:   "1136214245123456",
+			expected: time.Unix(1136214245, 123456000),
+		},
+		{
+			name:     "epoch-ns-string",
+			sample:   "1136214245123456789",
+			expected: time.Unix(1136214245, 123456789),
+		},
+		{
+			name:     "epoch-s-int",
+			sample:   1136214245,
+			expected: time.Unix(1136214245, 0),
+		},
+		{
+			name:     "epoch-ms-int64",
+			sample:   int64(1136214245123),
+			expected: time.Unix(1136214245, 123000000),
+		},
+		{
+			name:     "epoch-s-float",
+			sample:   1136214245.5,
+			expected: time.Unix(1136214245, 500000000),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			field := entry.NewRecordField()
+			timeParser := &TimeParser{
+				ParseFrom:  &field,
+				LayoutType: AutoKey,
+				Location:   "America/Chicago",
+			}
+			require.NoError(t, timeParser.Validate(testutil.NewBuildContext(t)))
+
+			ent := makeTestEntry(field, tc.sample)
+			require.NoError(t, timeParser.Parse(context.Background(), ent))
+			require.True(t, tc.expected.Equal(ent.Timestamp), "expected %s, got %s", tc.expected, ent.Timestamp)
+		})
+	}
+}
+
+func TestTimeAutoFailure(t *testing.T) {
+	field := entry.NewRecordField()
+	timeParser := &TimeParser{
+		ParseFrom:  &field,
+		LayoutType: AutoKey,
+	}
+	require.NoError(t, timeParser.Validate(testutil.NewBuildContext(t)))
+
+	err := timeParser.Parse(context.Background(), makeTestEntry(field, "the ninth of June"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not match any of the timestamp formats")
+
+	// Numbers with fewer digits than an epoch timestamp of the current era are not parsed as epochs
+	err = timeParser.Parse(context.Background(), makeTestEntry(field, "20201016"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not match any of the timestamp formats")
+
+	err = timeParser.Parse(context.Background(), makeTestEntry(field, 20201016))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "epoch value '20201016' must have 10 to 19 digits")
+
+	err = timeParser.Parse(context.Background(), makeTestEntry(field, true))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "type bool cannot be parsed as a time")
+}
+
+func TestTimeAutoValidateFailure(t *testing.T) {
+	field := entry.NewRecordField()
+	timeParser := &TimeParser{
+		ParseFrom:  &field,
+		LayoutType: AutoKey,
+		Layout:     "%Y-%m-%d",
+	}
+	err := timeParser.Validate(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "`layout` and `fallback_layouts` cannot be used with the `auto` layout_type")
+}
+
+func TestTimeAutoCache(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	buildContext := testutil.NewBuildContext(t)
+	buildContext.Logger = zap.New(core).Sugar()
+
+	field := entry.NewRecordField()
+	timeParser := &TimeParser{
+		ParseFrom:  &field,
+		LayoutType: AutoKey,
+	}
+	require.NoError(t, timeParser.Validate(buildContext))
+
+	samples := []string{
+		"09/Jun/2020:15:39:58 +0000",
+		"09/Jun/2020:15:39:59 +0000",
+		"2020-06-09T15:40:00Z",
+		"09/Jun/2020:15:40:01 +0000",
+	}
+	for _, sample := range samples {
+		require.NoError(t, timeParser.Parse(context.Background(), makeTestEntry(field, sample)))
+	}
+
+	// A layout is only detected when a value does not match the cached layout
+	detected := logs.FilterMessage("Detected timestamp layout").All()
+	require.Len(t, detected, 3)
+	require.Equal(t, "Apache CLF", detected[0].ContextMap()["name"])
+	require.Equal(t, "RFC3339", detected[1].ContextMap()["name"])
+	require.Equal(t, "Apache CLF", detected[2].ContextMap()["name"])
+}
+
+func TestNormalizeFraction(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected string
+	}{
+		{"2020-06-09 15:39:58,118", "2020-06-09 15:39:58.118"},
+		{"09 Jun 2020 15:39:58,118 +0200", "09 Jun 2020 15:39:58.118 +0200"},
+		{"2020-06-09 15:39:58.118", "2020-06-09 15:39:58.118"},
+		{"20200609T153958,118Z", "20200609T153958.118Z"},
+		{"Tue, 09 Jun 2020 15:39:58 UTC", "Tue, 09 Jun 2020 15:39:58 UTC"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			require.Equal(t, tc.expected, normalizeFraction(tc.value))
+		})
+	}
+}
@@ -55,6 +55,71 @@ func Test_setTimestampYear(t *testing.T) {
 		expected := time.Date(2019, 12, 31, 3, 31, 34, 525, time.UTC)
 		require.Equal(t, expected, yearAdded)
 	})
+
+	t.Run("RolloverYearAheadOfUTC", func(t *testing.T) {
+		// It is already 2020 in Tokyo
+		now = func() time.Time {
+			return time.Date(2019, 12, 31, 20, 0, 0, 0, time.UTC)
+		}
+
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		require.NoError(t, err)
+		noYear := time.Date(0, 01, 01, 4, 0, 0, 0, tokyo)
+		yearAdded := setTimestampYear(noYear)
+		expected := time.Date(2020, 01, 01, 4, 0, 0, 0, tokyo)
+		require.Equal(t, expected, yearAdded)
+	})
+
+	t.Run("RolloverYearBehindUTC", func(t *testing.T) {
+		// It is still 2019 in Chicago
+		now = func() time.Time {
+			return time.Date(2020, 01, 01, 2, 0, 0, 0, time.UTC)
+		}
+
+		chicago, err := time.LoadLocation("America/Chicago")
+		require.NoError(t, err)
+		noYear := time.Date(0, 12, 31, 19, 0, 0, 0, chicago)
+		yearAdded := setTimestampYear(noYear)
+		expected := time.Date(2019, 12, 31, 19, 0, 0, 0, chicago)
+		require.Equal(t, expected, yearAdded)
+
+		noYear = time.Date(0, 01, 02, 8, 0, 0, 0, chicago)
+		yearAdded = setTimestampYear(noYear)
+		expected = time.Date(2020, 01, 02, 8, 0, 0, 0, chicago)
+		require.Equal(t, expected, yearAdded)
+	})
+
+	t.Run("LeapDay", func(t *testing.T) {
+		now = func() time.Time {
+			return time.Date(2021, 03, 01, 3, 31, 34, 525, time.UTC)
+		}
+
+		// 2021 has no February 29, so the most recent leap year is used
+		noYear := time.Date(0, 02, 29, 3, 31, 34, 525, time.UTC)
+		yearAdded := setTimestampYear(noYear)
+		expected := time.Date(2020, 02, 29, 3, 31, 34, 525, time.UTC)
+		require.Equal(t, expected, yearAdded)
+	})
+
+	t.Run("LeapDayInLeapYear", func(t *testing.T) {
+		now = func() time.Time {
+			return time.Date(2024, 03, 01, 3, 31, 34, 525, time.UTC)
+		}
+
+		noYear := time.Date(0, 02, 29, 3, 31, 34, 525, time.UTC)
+		yearAdded := setTimestampYear(noYear)
+		expected := time.Date(2024, 02, 29, 3, 31, 34, 525, time.UTC)
+		require.Equal(t, expected, yearAdded)
+	})
+
+	t.Run("HasYear", func(t *testing.T) {
+		now = func() time.Time {
+			return time.Date(2020, 06, 16, 3, 31, 34, 525, time.UTC)
+		}
+
+		epoch := time.Unix(0, 0)
+		require.Equal(t, epoch, setTimestampYear(epoch))
+	})
 }
 
 func TestIsZero(t *testing.T) {
@@ -179,6 +244,7 @@ func TestTimeParser(t *testing.T) {
 
 			expected, err := time.ParseInLocation(tc.gotimeLayout, sampleStr, time.Local)
 			require.NoError(t, err, "Test configuration includes invalid timestamp or layout")
+			expected = setTimestampYear(expected)
 
 			gotimeRootCfg := parseTimeTestConfig(GotimeKey, tc.gotimeLayout, rootField)
 			t.Run("gotime-root", runTimeParseTest(t, gotimeRootCfg, makeTestEntry(rootField, tc.sample), false, false, expected))
@@ -357,6 +423,181 @@ func TestTimeEpochs(t *testing.T) {
 	}
 }
 
+func TestTimeLocation(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		sample   string
+		layout   string
+		expected time.Time
+	}{
+		{
+			name:     "no-zone",
+			sample:   "2020-06-09 15:39:58",
+			layout:   "%Y-%m-%d %H:%M:%S",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, chicago),
+		},
+		{
+			name:     "zone-offset",
+			sample:   "2020-06-09 15:39:58 +0000",
+			layout:   "%Y-%m-%d %H:%M:%S %z",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			field := entry.NewRecordField()
+			timeParser := parseTimeTestConfig(StrptimeKey, tc.layout, field)
+			timeParser.Location = "America/Chicago"
+			require.NoError(t, timeParser.Validate(testutil.NewBuildContext(t)))
+
+			ent := makeTestEntry(field, tc.sample)
+			require.NoError(t, timeParser.Parse(context.Background(), ent))
+			require.True(t, tc.expected.Equal(ent.Timestamp), "expected %s, got %s", tc.expected, ent.Timestamp)
+		})
+	}
+}
+
+func TestTimeFallbackLayouts(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2020, 06, 16, 3, 31, 34, 525, time.UTC)
+	}
+
+	testCases := []struct {
+		name     string
+		sample   string
+		expected time.Time
+		parseErr bool
+	}{
+		{
+			name:     "layout",
+			sample:   "2020-06-09T15:39:58Z",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC),
+		},
+		{
+			name:     "first-fallback",
+			sample:   "09/Jun/2020:15:39:58 +0000",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC),
+		},
+		{
+			name:     "second-fallback-without-year",
+			sample:   "Jun  9 15:39:58",
+			expected: time.Date(2020, 6, 9, 15, 39, 58, 0, time.UTC),
+		},
+		{
+			name:     "no-match",
+			sample:   "9 June 2020",
+			parseErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			field := entry.NewRecordField()
+			timeParser := parseTimeTestConfig(StrptimeKey, "%Y-%m-%dT%H:%M:%SZ", field)
+			timeParser.FallbackLayouts = []string{"%d/%b/%Y:%H:%M:%S %z", "%b %e %H:%M:%S"}
+			timeParser.Location = "UTC"
+			t.Run("parse", runTimeParseTest(t, timeParser, makeTestEntry(field, tc.sample), false, tc.parseErr, tc.expected))
+		})
+	}
+}
+
+func TestTimeValidateFailure(t *testing.T) {
+	testCases := []struct {
+		name     string
+		modify   func(*TimeParser)
+		expected string
+	}{
+		{
+			name: "fallback-layouts-epoch",
+			modify: func(t *TimeParser) {
+				t.LayoutType = EpochKey
+				t.Layout = "s"
+				t.FallbackLayouts = []string{"ms"}
+			},
+			expected: "`fallback_layouts` cannot be used with the `epoch` layout_type",
+		},
+		{
+			name: "fallback-layouts-native",
+			modify: func(t *TimeParser) {
+				t.LayoutType = NativeKey
+				t.FallbackLayouts = []string{"%Y"}
+			},
+			expected: "`fallback_layouts` cannot be used with the `native` layout_type",
+		},
+		{
+			name: "location-epoch",
+			modify: func(t *TimeParser) {
+				t.LayoutType = EpochKey
+				t.Layout = "s"
+				t.Location = "UTC"
+			},
+			expected: "`location` cannot be used with the `epoch` layout_type",
+		},
+		{
+			name: "location-native",
+			modify: func(t *TimeParser) {
+				t.LayoutType = NativeKey
+				t.Location = "UTC"
+			},
+			expected: "`location` cannot be used with the `native` layout_type",
+		},
+		{
+			name: "invalid-location",
+			modify: func(t *TimeParser) {
+				t.Layout = "%Y-%m-%d"
+				t.Location = "Not/A_Location"
+			},
+			expected: "invalid location 'Not/A_Location'",
+		},
+		{
+			name: "duplicate-layout",
+			modify: func(t *TimeParser) {
+				t.Layout = "%Y-%m-%d"
+				t.FallbackLayouts = []string{"%d/%m/%Y", "%Y-%m-%d"}
+			},
+			expected: "layout '%Y-%m-%d' is specified more than once",
+		},
+		{
+			name: "fallback-layout-without-elements",
+			modify: func(t *TimeParser) {
+				t.Layout = "%d/%m/%Y"
+				t.FallbackLayouts = []string{"YYYY-MM-DD"}
+			},
+			expected: "layout 'YYYY-MM-DD' does not contain any time elements",
+		},
+		{
+			name: "strptime-layout-as-gotime",
+			modify: func(t *TimeParser) {
+				t.LayoutType = GotimeKey
+				t.Layout = "%Y-%m-%d"
+			},
+			expected: "layout '%Y-%m-%d' does not contain any time elements",
+		},
+		{
+			name: "invalid-fallback-layout",
+			modify: func(t *TimeParser) {
+				t.Layout = "%Y-%m-%d"
+				t.FallbackLayouts = []string{"%1"}
+			},
+			expected: "parse strptime layout",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			timeParser := parseTimeTestConfig(StrptimeKey, "", entry.NewRecordField())
+			tc.modify(timeParser)
+			err := timeParser.Validate(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
 func TestTimeErrors(t *testing.T) {
 
 	testCases := []struct {
@@ -0,0 +1,91 @@
+package helper
+
+import (
+	"context"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+)
+
+// NewTraceParser creates a new trace parser with default values
+func NewTraceParser() TraceParser {
+	traceID := entry.NewRecordField("trace_id")
+	spanID := entry.NewRecordField("span_id")
+	traceFlags := entry.NewRecordField("trace_flags")
+	return TraceParser{
+		TraceID:    &TraceIDConfig{ParseFrom: &traceID},
+		SpanID:     &SpanIDConfig{ParseFrom: &spanID},
+		TraceFlags: &TraceFlagsConfig{ParseFrom: &traceFlags},
+	}
+}
+
+// TraceParser is a helper that parses trace context onto an entry.
+type TraceParser struct {
+	TraceID    *TraceIDConfig    `json:"trace_id,omitempty"    yaml:"trace_id,omitempty"`
+	SpanID     *SpanIDConfig     `json:"span_id,omitempty"     yaml:"span_id,omitempty"`
+	TraceFlags *TraceFlagsConfig `json:"trace_flags,omitempty" yaml:"trace_flags,omitempty"`
+	Preserve   bool              `json:"preserve"              yaml:"preserve"`
+}
+
+// TraceIDConfig is the configuration of the field that a trace ID is parsed from.
+type TraceIDConfig struct {
+	ParseFrom *entry.Field `json:"parse_from,omitempty" yaml:"parse_from,omitempty"`
+}
+
+// SpanIDConfig is the configuration of the field that a span ID is parsed from.
+type SpanIDConfig struct {
+	ParseFrom *entry.Field `json:"parse_from,omitempty" yaml:"parse_from,omitempty"`
+}
+
+// TraceFlagsConfig is the configuration of the field that trace flags are parsed from.
+type TraceFlagsConfig struct {
+	ParseFrom *entry.Field `json:"parse_from,omitempty" yaml:"parse_from,omitempty"`
+}
+
+// Validate validates a TraceParser, and reconfigures it if necessary
+func (t *TraceParser) Validate(context operator.BuildContext) error {
+	defaults := NewTraceParser()
+	if t.TraceID == nil || t.TraceID.ParseFrom == nil {
+		t.TraceID = defaults.TraceID
+	}
+	if t.SpanID == nil || t.SpanID.ParseFrom == nil {
+		t.SpanID = defaults.SpanID
+	}
+	if t.TraceFlags == nil || t.TraceFlags.ParseFrom == nil {
+		t.TraceFlags = defaults.TraceFlags
+	}
+	return nil
+}
+
+// Parse will parse the trace context from the configured fields and attach it to the entry.
+// Fields that are missing from the entry are ignored.
+func (t *TraceParser) Parse(ctx context.Context, ent *entry.Entry) error {
+	if err := t.parseField(ent, *t.TraceID.ParseFrom, entry.NewTraceIDField()); err != nil {
+		return errors.Wrap(err, "trace_id")
+	}
+	if err := t.parseField(ent, *t.SpanID.ParseFrom, entry.NewSpanIDField()); err != nil {
+		return errors.Wrap(err, "span_id")
+	}
+	if err := t.parseField(ent, *t.TraceFlags.ParseFrom, entry.NewTraceFlagsField()); err != nil {
+		return errors.Wrap(err, "trace_flags")
+	}
+	return nil
+}
+
+// parseField will copy the value of a field to a trace field on the entry.
+func (t *TraceParser) parseField(ent *entry.Entry, from entry.Field, to entry.Field) error {
+	value, ok := ent.Get(from)
+	if !ok {
+		return nil
+	}
+
+	if err := ent.Set(to, value); err != nil {
+		return errors.WithDetails(err, "parse_from", from.String())
+	}
+
+	if !t.Preserve {
+		ent.Delete(from)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package helper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceParserValidateDefaults(t *testing.T) {
+	parser := TraceParser{}
+	err := parser.Validate(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.Equal(t, "trace_id", parser.TraceID.ParseFrom.String())
+	require.Equal(t, "span_id", parser.SpanID.ParseFrom.String())
+	require.Equal(t, "trace_flags", parser.TraceFlags.ParseFrom.String())
+}
+
+func TestTraceParserParse(t *testing.T) {
+	cases := []struct {
+		name           string
+		preserve       bool
+		record         map[string]interface{}
+		expectedRecord map[string]interface{}
+		traceID        []byte
+		spanID         []byte
+		traceFlags     []byte
+		expectErr      bool
+	}{
+		{
+			"AllFields",
+			false,
+			map[string]interface{}{
+				"trace_id":    "480140f3d770a5ae32f0a22b6a812cff",
+				"span_id":     "92c3792d54ba94f3",
+				"trace_flags": "01",
+				"message":     "test",
+			},
+			map[string]interface{}{
+				"message": "test",
+			},
+			[]byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+			[]byte{0x92, 0xc3, 0x79, 0x2d, 0x54, 0xba, 0x94, 0xf3},
+			[]byte{0x01},
+			false,
+		},
+		{
+			"Preserve",
+			true,
+			map[string]interface{}{
+				"trace_id": "480140f3d770a5ae32f0a22b6a812cff",
+			},
+			map[string]interface{}{
+				"trace_id": "480140f3d770a5ae32f0a22b6a812cff",
+			},
+			[]byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+			nil,
+			nil,
+			false,
+		},
+		{
+			"MissingFields",
+			false,
+			map[string]interface{}{
+				"message": "test",
+			},
+			map[string]interface{}{
+				"message": "test",
+			},
+			nil,
+			nil,
+			nil,
+			false,
+		},
+		{
+			"InvalidSpanID",
+			false,
+			map[string]interface{}{
+				"span_id": "invalid",
+			},
+			map[string]interface{}{
+				"span_id": "invalid",
+			},
+			nil,
+			nil,
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewTraceParser()
+			parser.Preserve = tc.preserve
+			require.NoError(t, parser.Validate(testutil.NewBuildContext(t)))
+
+			e := entry.New()
+			e.Record = tc.record
+			err := parser.Parse(context.Background(), e)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRecord, e.Record)
+			require.Equal(t, tc.traceID, e.TraceID)
+			require.Equal(t, tc.spanID, e.SpanID)
+			require.Equal(t, tc.traceFlags, e.TraceFlags)
+		})
+	}
+}
@@ -2,7 +2,10 @@ package helper
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
 	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/errors"
 	"github.com/observiq/stanza/operator"
@@ -21,6 +24,16 @@ func NewTransformerConfig(operatorID, operatorType string) TransformerConfig {
 type TransformerConfig struct {
 	WriterConfig `yaml:",inline"`
 	OnError      string `json:"on_error" yaml:"on_error"`
+	ErrorOutput  string `json:"error_output,omitempty" yaml:"error_output,omitempty"`
+	IfExpr       string `json:"if,omitempty" yaml:"if,omitempty"`
+}
+
+// SetNamespace will namespace the output ids and error output id of the transformer.
+func (c *TransformerConfig) SetNamespace(namespace string, exclusions ...string) {
+	c.WriterConfig.SetNamespace(namespace, exclusions...)
+	if c.ErrorOutput != "" && CanNamespace(c.ErrorOutput, exclusions) {
+		c.ErrorOutput = AddNamespace(c.ErrorOutput, namespace)
+	}
 }
 
 // Build will build a transformer operator.
@@ -32,10 +45,25 @@ func (c TransformerConfig) Build(context operator.BuildContext) (TransformerOper
 
 	switch c.OnError {
 	case SendOnError, DropOnError:
+		if c.ErrorOutput != "" {
+			return TransformerOperator{}, errors.NewError(
+				"operator config has an `error_output` field, but does not route errors.",
+				"set the `on_error` field to `route` to send failed entries to the `error_output`.",
+				"on_error", c.OnError,
+				"error_output", c.ErrorOutput,
+			)
+		}
+	case RouteOnError:
+		if c.ErrorOutput == "" {
+			return TransformerOperator{}, errors.NewError(
+				"operator config is missing the `error_output` field.",
+				"ensure that the `error_output` field is set when the `on_error` field is `route`.",
+			)
+		}
 	default:
 		return TransformerOperator{}, errors.NewError(
 			"operator config has an invalid `on_error` field.",
-			"ensure that the `on_error` field is set to either `send` or `drop`.",
+			"ensure that the `on_error` field is set to `send`, `drop` or `route`.",
 			"on_error", c.OnError,
 		)
 	}
@@ -43,6 +71,20 @@ func (c TransformerConfig) Build(context operator.BuildContext) (TransformerOper
 	transformerOperator := TransformerOperator{
 		WriterOperator: writerOperator,
 		OnError:        c.OnError,
+		ErrorOutputID:  c.ErrorOutput,
+	}
+
+	if c.IfExpr != "" {
+		compiled, err := ExprCompile(c.IfExpr, expr.AsBool())
+		if err != nil {
+			return TransformerOperator{}, errors.NewError(
+				"operator config has an invalid `if` field.",
+				"ensure that the `if` field is an expression that returns a boolean.",
+				"if", c.IfExpr,
+				"error", err.Error(),
+			)
+		}
+		transformerOperator.IfExpr = compiled
 	}
 
 	return transformerOperator, nil
@@ -51,7 +93,41 @@ func (c TransformerConfig) Build(context operator.BuildContext) (TransformerOper
 // TransformerOperator provides a basic implementation of a transformer operator.
 type TransformerOperator struct {
 	WriterOperator
-	OnError string
+	OnError       string
+	ErrorOutputID string
+	ErrorOperator operator.Operator
+	IfExpr        *vm.Program
+}
+
+// SetOutputs will set the outputs and the error output of the operator.
+func (t *TransformerOperator) SetOutputs(operators []operator.Operator) error {
+	if err := t.WriterOperator.SetOutputs(operators); err != nil {
+		return err
+	}
+
+	if t.ErrorOutputID == "" {
+		return nil
+	}
+
+	errorOperator, ok := t.findOperator(operators, t.ErrorOutputID)
+	if !ok {
+		return fmt.Errorf("error output operator '%s' does not exist", t.ErrorOutputID)
+	}
+
+	if !errorOperator.CanProcess() {
+		return fmt.Errorf("error output operator '%s' can not process entries", t.ErrorOutputID)
+	}
+
+	t.ErrorOperator = errorOperator
+	return nil
+}
+
+// ErrorOutputs returns the operators that receive the entries this operator failed to process.
+func (t *TransformerOperator) ErrorOutputs() []operator.Operator {
+	if t.ErrorOperator == nil {
+		return nil
+	}
+	return []operator.Operator{t.ErrorOperator}
 }
 
 // CanProcess will always return true for a transformer operator.
@@ -60,30 +136,113 @@ func (t *TransformerOperator) CanProcess() bool {
 }
 
 // ProcessWith will process an entry with a transform function.
-func (t *TransformerOperator) ProcessWith(ctx context.Context, entry *entry.Entry, transform TransformFunction) error {
-	newEntry, err := transform(entry)
-	if err != nil {
+func (t *TransformerOperator) ProcessWith(ctx context.Context, e *entry.Entry, transform TransformFunction) error {
+	return t.ProcessIf(ctx, e, func(ctx context.Context, e *entry.Entry) error {
+		newEntry, err := transform(e)
+		if err != nil {
+			return t.HandleEntryError(ctx, e, err)
+		}
+		t.Write(ctx, newEntry)
+		return nil
+	})
+}
+
+// ProcessIf will process an entry with a process function if it matches the if expression.
+// Entries that do not match the if expression are passed through untouched.
+func (t *TransformerOperator) ProcessIf(ctx context.Context, entry *entry.Entry, process ProcessFunction) error {
+	if skip, err := t.skip(ctx, entry); err != nil {
 		return t.HandleEntryError(ctx, entry, err)
+	} else if skip {
+		t.Write(ctx, entry)
+		return nil
 	}
-	t.Write(ctx, newEntry)
-	return nil
+
+	return process(ctx, entry)
 }
 
 // HandleEntryError will handle an entry error using the on_error strategy.
 func (t *TransformerOperator) HandleEntryError(ctx context.Context, entry *entry.Entry, err error) error {
 	t.Errorw("Failed to process entry", zap.Any("error", err), zap.Any("action", t.OnError), zap.Any("entry", entry))
-	if t.OnError == SendOnError {
+	switch t.OnError {
+	case SendOnError:
 		t.Write(ctx, entry)
 		return nil
+	case RouteOnError:
+		if t.ErrorOperator == nil {
+			return err
+		}
+		t.addErrorLabels(entry, err)
+		_ = t.ErrorOperator.Process(ctx, entry)
+		return nil
+	default:
+		return err
+	}
+}
+
+// addErrorLabels will describe an error on the labels of the entry that failed to process.
+func (t *TransformerOperator) addErrorLabels(entry *entry.Entry, err error) {
+	entry.AddLabel(ErrorOperatorLabel, t.ID())
+
+	agentErr, ok := err.(errors.AgentError)
+	if !ok {
+		entry.AddLabel(ErrorDescriptionLabel, err.Error())
+		return
+	}
+
+	entry.AddLabel(ErrorDescriptionLabel, agentErr.Description)
+	if agentErr.Suggestion != "" {
+		entry.AddLabel(ErrorSuggestionLabel, agentErr.Suggestion)
+	}
+	for key, value := range agentErr.Details {
+		entry.AddLabel(ErrorDetailsLabelPrefix+key, value)
+	}
+}
+
+// skip will return true if the operator has an if expression that does not match the entry.
+func (t *TransformerOperator) skip(ctx context.Context, entry *entry.Entry) (bool, error) {
+	if t.IfExpr == nil {
+		return false, nil
 	}
-	return err
+
+	env := GetExprEnv(entry)
+	defer PutExprEnv(env)
+
+	matches, err := vm.Run(t.IfExpr, env)
+	if err != nil {
+		return false, errors.Wrap(err, "evaluate if expression")
+	}
+
+	matched, ok := matches.(bool)
+	if !ok {
+		return false, errors.NewError(
+			"if expression did not return a boolean.",
+			"ensure that the `if` field is an expression that returns a boolean.",
+			"result", fmt.Sprintf("%v", matches),
+		)
+	}
+
+	return !matched, nil
 }
 
 // TransformFunction is function that transforms an entry.
 type TransformFunction = func(*entry.Entry) (*entry.Entry, error)
 
+// ProcessFunction is function that processes an entry and sends it to the outputs of an operator.
+type ProcessFunction = func(context.Context, *entry.Entry) error
+
 // SendOnError specifies an on_error mode for sending entries after an error.
 const SendOnError = "send"
 
 // DropOnError specifies an on_error mode for dropping entries after an error.
 const DropOnError = "drop"
+
+// RouteOnError specifies an on_error mode for sending entries to the error output after an error.
+const RouteOnError = "route"
+
+// Labels added to entries that are sent to an error output
+const (
+	ErrorOperatorLabel      = "error.operator"
+	ErrorDescriptionLabel   = "error.description"
+	ErrorSuggestionLabel    = "error.suggestion"
+	ErrorDetailsLabelPrefix = "error.details."
+)
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
 	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/testutil"
 	"github.com/stretchr/testify/mock"
@@ -146,3 +148,210 @@ func TestTransformerProcessWithValid(t *testing.T) {
 	require.NoError(t, err)
 	output.AssertCalled(t, "Process", mock.Anything, mock.Anything)
 }
+
+func TestTransformerIf(t *testing.T) {
+	cases := []struct {
+		name        string
+		ifExpr      string
+		inputRecord string
+		expected    string
+	}{
+		{
+			"NoIf",
+			"",
+			"test",
+			"parsed",
+		},
+		{
+			"TrueIf",
+			"true",
+			"test",
+			"parsed",
+		},
+		{
+			"FalseIf",
+			"false",
+			"test",
+			"test",
+		},
+		{
+			"EvaluatedTrue",
+			"$record == 'test'",
+			"test",
+			"parsed",
+		},
+		{
+			"EvaluatedFalse",
+			"$record == 'notest'",
+			"test",
+			"test",
+		},
+		{
+			"FunctionTrue",
+			"regex_match($record, '^te')",
+			"test",
+			"parsed",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewTransformerConfig("test", "test")
+			cfg.IfExpr = tc.ifExpr
+
+			buildContext := testutil.NewBuildContext(t)
+			transformer, err := cfg.Build(buildContext)
+			require.NoError(t, err)
+
+			e := entry.New()
+			e.Record = tc.inputRecord
+			fake := testutil.NewFakeOutput(t)
+			transformer.OutputOperators = []operator.Operator{fake}
+
+			err = transformer.ProcessWith(context.Background(), e, func(e *entry.Entry) (*entry.Entry, error) {
+				e.Record = "parsed"
+				return e, nil
+			})
+			require.NoError(t, err)
+
+			select {
+			case e := <-fake.Received:
+				require.Equal(t, tc.expected, e.Record)
+			case <-time.After(time.Second):
+				require.FailNow(t, "Timed out waiting for entry")
+			}
+		})
+	}
+
+	t.Run("InvalidIfExpr", func(t *testing.T) {
+		cfg := NewTransformerConfig("test", "test")
+		cfg.IfExpr = "'nonbool'"
+
+		buildContext := testutil.NewBuildContext(t)
+		_, err := cfg.Build(buildContext)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid `if` field")
+	})
+}
+
+func TestTransformerConfigErrorOutput(t *testing.T) {
+	t.Run("RouteWithoutErrorOutput", func(t *testing.T) {
+		cfg := NewTransformerConfig("test", "test")
+		cfg.OnError = RouteOnError
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing the `error_output` field")
+	})
+
+	t.Run("ErrorOutputWithoutRoute", func(t *testing.T) {
+		cfg := NewTransformerConfig("test", "test")
+		cfg.ErrorOutput = "quarantine"
+		_, err := cfg.Build(testutil.NewBuildContext(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not route errors")
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := NewTransformerConfig("test", "test")
+		cfg.OnError = RouteOnError
+		cfg.ErrorOutput = "quarantine"
+		transformer, err := cfg.Build(testutil.NewBuildContext(t))
+		require.NoError(t, err)
+		require.Equal(t, "quarantine", transformer.ErrorOutputID)
+	})
+
+	t.Run("SetNamespace", func(t *testing.T) {
+		cfg := NewTransformerConfig("test", "test")
+		cfg.OnError = RouteOnError
+		cfg.ErrorOutput = "quarantine"
+		cfg.SetNamespace("namespace")
+		require.Equal(t, "namespace.quarantine", cfg.ErrorOutput)
+	})
+}
+
+func TestTransformerSetOutputsErrorOutput(t *testing.T) {
+	cfg := NewTransformerConfig("test", "test")
+	cfg.OutputIDs = []string{"output"}
+	cfg.OnError = RouteOnError
+	cfg.ErrorOutput = "quarantine"
+	transformer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	output := testutil.NewMockOperator("output")
+	quarantine := testutil.NewMockOperator("quarantine")
+
+	err = transformer.SetOutputs([]operator.Operator{output})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error output operator 'quarantine' does not exist")
+
+	err = transformer.SetOutputs([]operator.Operator{output, quarantine})
+	require.NoError(t, err)
+	require.Equal(t, []operator.Operator{output}, transformer.Outputs())
+	require.Equal(t, []operator.Operator{quarantine}, transformer.ErrorOutputs())
+}
+
+func TestTransformerRouteOnError(t *testing.T) {
+	output := testutil.NewMockOperator("output")
+	output.On("Process", mock.Anything, mock.Anything).Return(nil)
+
+	var routed *entry.Entry
+	quarantine := testutil.NewMockOperator("quarantine")
+	quarantine.On("Process", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		routed = args.Get(1).(*entry.Entry)
+	})
+
+	cfg := NewTransformerConfig("test", "test")
+	cfg.OutputIDs = []string{"output"}
+	cfg.OnError = RouteOnError
+	cfg.ErrorOutput = "quarantine"
+	transformer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	err = transformer.SetOutputs([]operator.Operator{output, quarantine})
+	require.NoError(t, err)
+
+	transform := func(e *entry.Entry) (*entry.Entry, error) {
+		return e, errors.NewError("Failure", "Try again", "key", "value")
+	}
+
+	err = transformer.ProcessWith(context.Background(), entry.New(), transform)
+	require.NoError(t, err)
+	output.AssertNotCalled(t, "Process", mock.Anything, mock.Anything)
+
+	require.NotNil(t, routed)
+	expected := map[string]string{
+		ErrorOperatorLabel:              "test",
+		ErrorDescriptionLabel:           "Failure",
+		ErrorSuggestionLabel:            "Try again",
+		ErrorDetailsLabelPrefix + "key": "value",
+	}
+	require.Equal(t, expected, routed.Labels)
+}
+
+func TestTransformerRouteOnErrorPlainError(t *testing.T) {
+	var routed *entry.Entry
+	quarantine := testutil.NewMockOperator("quarantine")
+	quarantine.On("Process", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		routed = args.Get(1).(*entry.Entry)
+	})
+
+	cfg := NewTransformerConfig("test", "test")
+	cfg.OutputIDs = []string{"quarantine"}
+	cfg.OnError = RouteOnError
+	cfg.ErrorOutput = "quarantine"
+	transformer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	err = transformer.SetOutputs([]operator.Operator{quarantine})
+	require.NoError(t, err)
+
+	transform := func(e *entry.Entry) (*entry.Entry, error) {
+		return e, fmt.Errorf("Failure")
+	}
+
+	err = transformer.ProcessWith(context.Background(), entry.New(), transform)
+	require.NoError(t, err)
+	quarantine.AssertNumberOfCalls(t, "Process", 1)
+	require.Equal(t, map[string]string{
+		ErrorOperatorLabel:    "test",
+		ErrorDescriptionLabel: "Failure",
+	}, routed.Labels)
+}
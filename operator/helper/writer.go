@@ -54,13 +54,14 @@ type WriterOperator struct {
 }
 
 // Write will write an entry to the outputs of the operator.
+// Each output receives its own entry, which shares its values with the others until it modifies them.
 func (w *WriterOperator) Write(ctx context.Context, e *entry.Entry) {
 	for i, operator := range w.OutputOperators {
 		if i == len(w.OutputOperators)-1 {
 			_ = operator.Process(ctx, e)
 			return
 		}
-		_ = operator.Process(ctx, e.Copy())
+		_ = operator.Process(ctx, e.Share())
 	}
 }
 
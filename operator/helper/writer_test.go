@@ -3,6 +3,8 @@ package helper
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/observiq/stanza/entry"
@@ -203,3 +205,112 @@ func TestUnmarshalYAMLInvalidArray(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "value in array is not of type string")
 }
+
+// fanOutOutput is an output that records the entries it processes, and can modify them
+type fanOutOutput struct {
+	testutil.Operator
+	modify  bool
+	entries []*entry.Entry
+}
+
+func (o *fanOutOutput) Process(ctx context.Context, e *entry.Entry) error {
+	o.entries = append(o.entries, e)
+	if !o.modify {
+		_, _ = e.Get(entry.NewRecordField("message"))
+		return nil
+	}
+
+	// Modify a nested map in place, which is not visible to the entry's methods
+	nested, _ := e.Get(entry.NewRecordField("nested"))
+	nested.(map[string]interface{})["modified"] = true
+	e.AddLabel("output", "modified")
+	return e.Set(entry.NewRecordField("message"), "modified")
+}
+
+func fanOutEntry() *entry.Entry {
+	e := entry.New()
+	e.Labels = map[string]string{"label1": "value1", "label2": "value2"}
+	e.Resource = map[string]string{"host.name": "host", "service.name": "service"}
+	record := map[string]interface{}{
+		"message": "this is a test message",
+		"count":   10,
+		"nested": map[string]interface{}{
+			"list": []interface{}{"a", "b", "c", "d"},
+		},
+	}
+	for i := 0; i < 20; i++ {
+		record[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value%d", i)
+	}
+	e.Record = record
+	return e
+}
+
+func TestWriterOperatorWriteCopies(t *testing.T) {
+	modifying := &fanOutOutput{modify: true}
+	reading := &fanOutOutput{}
+	writer := WriterOperator{
+		OutputOperators: []operator.Operator{modifying, reading},
+	}
+
+	writer.Write(context.Background(), fanOutEntry())
+	require.Len(t, modifying.entries, 1)
+	require.Len(t, reading.entries, 1)
+	require.NotSame(t, modifying.entries[0], reading.entries[0])
+
+	// Modifications of one output, including nested maps modified in place, are not seen by the others
+	require.Equal(t, fanOutEntry().Record, reading.entries[0].Record)
+	require.Equal(t, fanOutEntry().Labels, reading.entries[0].Labels)
+	nested, ok := modifying.entries[0].Get(entry.NewRecordField("nested", "modified"))
+	require.True(t, ok)
+	require.Equal(t, true, nested)
+}
+
+func TestWriterOperatorWriteShares(t *testing.T) {
+	output1 := &fanOutOutput{}
+	output2 := &fanOutOutput{}
+	output3 := &fanOutOutput{}
+	writer := WriterOperator{
+		OutputOperators: []operator.Operator{output1, output2, output3},
+	}
+
+	testEntry := fanOutEntry()
+	writer.Write(context.Background(), testEntry)
+	require.Same(t, testEntry, output3.entries[0])
+
+	// Outputs that only read the entry share its record instead of copying it
+	record := reflect.ValueOf(testEntry.Record).Pointer()
+	for _, output := range []*fanOutOutput{output1, output2} {
+		require.Len(t, output.entries, 1)
+		require.NotSame(t, testEntry, output.entries[0])
+		require.Equal(t, record, reflect.ValueOf(output.entries[0].Record).Pointer())
+	}
+}
+
+func BenchmarkWriterOperatorWrite(b *testing.B) {
+	cases := []struct {
+		name   string
+		modify int
+	}{
+		{"ModifyAll", 4},
+		{"ModifyOne", 1},
+		{"Read", 0},
+	}
+
+	for _, tc := range cases {
+		outputs := make([]operator.Operator, 0, 4)
+		for i := 0; i < 4; i++ {
+			outputs = append(outputs, &fanOutOutput{modify: i < tc.modify})
+		}
+		writer := WriterOperator{OutputOperators: outputs}
+
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, output := range outputs {
+					output.(*fanOutOutput).entries = nil
+				}
+				writer.Write(context.Background(), fanOutEntry())
+			}
+		})
+	}
+}
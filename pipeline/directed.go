@@ -6,6 +6,8 @@ import (
 
 	"github.com/observiq/stanza/errors"
 	"github.com/observiq/stanza/operator"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
 	"gonum.org/v1/gonum/graph/encoding/dot"
 	"gonum.org/v1/gonum/graph/simple"
 	"gonum.org/v1/gonum/graph/topo"
@@ -102,9 +104,25 @@ func connectNodes(graph *simple.DirectedGraph) error {
 	return nil
 }
 
-// connectNode will connect a node to its outputs in the supplied graph.
+// connectNode will connect a node to its outputs and error outputs in the supplied graph.
 func connectNode(graph *simple.DirectedGraph, inputNode OperatorNode) error {
-	for outputOperatorID, outputNodeID := range inputNode.OutputIDs() {
+	if err := connectOutputs(graph, inputNode, inputNode.OutputIDs(), false); err != nil {
+		return err
+	}
+
+	// An error output that is also a regular output is already connected
+	errorOutputIDs := make(map[string]int64)
+	for outputOperatorID, outputNodeID := range inputNode.ErrorOutputIDs() {
+		if _, ok := inputNode.OutputIDs()[outputOperatorID]; !ok {
+			errorOutputIDs[outputOperatorID] = outputNodeID
+		}
+	}
+	return connectOutputs(graph, inputNode, errorOutputIDs, true)
+}
+
+// connectOutputs will connect a node to the supplied outputs in the supplied graph.
+func connectOutputs(graph *simple.DirectedGraph, inputNode OperatorNode, outputIDs map[string]int64, errorOutputs bool) error {
+	for outputOperatorID, outputNodeID := range outputIDs {
 		if graph.Node(outputNodeID) == nil {
 			return errors.NewError(
 				"operators cannot be connected, because the output does not exist in the pipeline",
@@ -134,12 +152,33 @@ func connectNode(graph *simple.DirectedGraph, inputNode OperatorNode) error {
 		}
 
 		edge := graph.NewEdge(inputNode, outputNode)
+		if errorOutputs {
+			edge = errorEdge{edge}
+		}
 		graph.SetEdge(edge)
 	}
 
 	return nil
 }
 
+// errorEdge is an edge to an operator that receives the entries another operator failed to process.
+type errorEdge struct {
+	graph.Edge
+}
+
+// ReversedEdge returns the reversed edge, which is still an error edge.
+func (e errorEdge) ReversedEdge() graph.Edge {
+	return errorEdge{e.Edge.ReversedEdge()}
+}
+
+// Attributes returns the attributes used to render the edge in a dot graph.
+func (e errorEdge) Attributes() []encoding.Attribute {
+	return []encoding.Attribute{
+		{Key: "label", Value: "error"},
+		{Key: "style", Value: "dashed"},
+	}
+}
+
 // setOperatorOutputs will set the outputs on operators that can output.
 func setOperatorOutputs(operators []operator.Operator) error {
 	for _, operator := range operators {
@@ -280,3 +280,67 @@ func TestPipelineRender(t *testing.T) {
 }`
 	require.Equal(t, expected, string(dotGraph))
 }
+
+// errorOutputOperator is a mock operator that sends failed entries to error outputs
+type errorOutputOperator struct {
+	*testutil.Operator
+	errorOutputs []operator.Operator
+}
+
+func (o *errorOutputOperator) ErrorOutputs() []operator.Operator {
+	return o.errorOutputs
+}
+
+func TestPipelineRenderErrorOutput(t *testing.T) {
+	mockOperator1 := testutil.NewMockOperator("operator1")
+	mockOperator2 := testutil.NewMockOperator("operator2")
+	mockOperator3 := testutil.NewMockOperator("operator3")
+
+	mockOperator1.On("Outputs").Return([]operator.Operator{mockOperator2})
+	mockOperator2.On("Outputs").Return(nil)
+	mockOperator3.On("Outputs").Return(nil)
+
+	mockOperator1.On("SetOutputs", mock.Anything).Return(nil)
+	mockOperator2.On("SetOutputs", mock.Anything).Return(nil)
+	mockOperator3.On("SetOutputs", mock.Anything).Return(nil)
+
+	operator1 := &errorOutputOperator{mockOperator1, []operator.Operator{mockOperator3}}
+
+	pipeline, err := NewDirectedPipeline([]operator.Operator{operator1, mockOperator2, mockOperator3})
+	require.NoError(t, err)
+
+	dotGraph, err := pipeline.Render()
+	require.NoError(t, err)
+	expected := `strict digraph G {
+ // Node definitions.
+ operator1;
+ operator3;
+ operator2;
+
+ // Edge definitions.
+ operator1 -> operator3 [
+  label=error
+  style=dashed
+ ];
+ operator1 -> operator2;
+}`
+	require.Equal(t, expected, string(dotGraph))
+}
+
+func TestPipelineErrorOutputMissing(t *testing.T) {
+	mockOperator1 := testutil.NewMockOperator("operator1")
+	mockOperator2 := testutil.NewMockOperator("operator2")
+	mockOperator3 := testutil.NewMockOperator("operator3")
+
+	mockOperator1.On("Outputs").Return([]operator.Operator{mockOperator2})
+	mockOperator2.On("Outputs").Return(nil)
+
+	mockOperator1.On("SetOutputs", mock.Anything).Return(nil)
+	mockOperator2.On("SetOutputs", mock.Anything).Return(nil)
+
+	operator1 := &errorOutputOperator{mockOperator1, []operator.Operator{mockOperator3}}
+
+	_, err := NewDirectedPipeline([]operator.Operator{operator1, mockOperator2})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the output does not exist")
+}
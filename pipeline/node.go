@@ -8,9 +8,10 @@ import (
 
 // OperatorNode is a basic node that represents an operator in a pipeline.
 type OperatorNode struct {
-	operator  operator.Operator
-	id        int64
-	outputIDs map[string]int64
+	operator       operator.Operator
+	id             int64
+	outputIDs      map[string]int64
+	errorOutputIDs map[string]int64
 }
 
 // Operator returns the operator of the node.
@@ -33,16 +34,32 @@ func (b OperatorNode) OutputIDs() map[string]int64 {
 	return b.outputIDs
 }
 
+// ErrorOutputIDs returns a map of error output operator ids to node ids.
+func (b OperatorNode) ErrorOutputIDs() map[string]int64 {
+	return b.errorOutputIDs
+}
+
+// errorOutputter is an operator that sends the entries it failed to process to error outputs.
+type errorOutputter interface {
+	ErrorOutputs() []operator.Operator
+}
+
 // createOperatorNode will create an operator node.
 func createOperatorNode(operator operator.Operator) OperatorNode {
 	id := createNodeID(operator.ID())
 	outputIDs := make(map[string]int64)
+	errorOutputIDs := make(map[string]int64)
 	if operator.CanOutput() {
 		for _, output := range operator.Outputs() {
 			outputIDs[output.ID()] = createNodeID(output.ID())
 		}
+		if outputter, ok := operator.(errorOutputter); ok {
+			for _, output := range outputter.ErrorOutputs() {
+				errorOutputIDs[output.ID()] = createNodeID(output.ID())
+			}
+		}
 	}
-	return OperatorNode{operator, id, outputIDs}
+	return OperatorNode{operator, id, outputIDs, errorOutputIDs}
 }
 
 // createNodeID generates a node id from an operator id.